@@ -0,0 +1,1161 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AstarteSpec defines the desired state of Astarte
+type AstarteSpec struct {
+	// The Astarte Version for this Resource
+	Version string `json:"version"`
+	// +kubebuilder:validation:Optional
+	Features AstarteFeatures `json:"features,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="IfNotPresent"
+	ImagePullPolicy *v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// +kubebuilder:validation:Optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// The distribution channel for astarte images. This setting can be overridden by explicitly
+	// setting the 'image' value for each service. Defaults to "astarte".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="astarte"
+	DistributionChannel string `json:"distributionChannel,omitempty"`
+	// +kubebuilder:validation:Optional
+	DeploymentStrategy *appsv1.DeploymentStrategy `json:"deploymentStrategy,omitempty"`
+	// +kubebuilder:validation:Optional
+	StorageClassName string         `json:"storageClassName,omitempty"`
+	API              AstarteAPISpec `json:"api"`
+	// +kubebuilder:validation:Optional
+	RabbitMQ AstarteRabbitMQSpec `json:"rabbitmq"`
+	// +kubebuilder:validation:Optional
+	Cassandra AstarteCassandraSpec `json:"cassandra"`
+	VerneMQ   AstarteVerneMQSpec   `json:"vernemq"`
+	// +kubebuilder:validation:Optional
+	CFSSL AstarteCFSSLSpec `json:"cfssl"`
+	// +kubebuilder:validation:Optional
+	Components AstarteComponentsSpec `json:"components"`
+	// AstarteInstanceID is the unique ID that is associated with an Astarte instance. This parameter
+	// is used to let different Astarte instances employ a shared database infrastructure.
+	// Once set, the AstarteInstanceID cannot be changed. Defaults to "".
+	// +kubebuilder:validation:Pattern:=`^[a-z]?[a-z0-9]{0,47}$`
+	// +kubebuilder:default:=""
+	// +kubebuilder:validation:Optional
+	AstarteInstanceID string `json:"astarteInstanceID,omitempty"`
+	// ManualMaintenanceMode pauses all reconciliation activities but still computes the resource
+	// status. It should be used only when the managed Astarte resources requires manual intervention
+	// and the Operator cannot break out of the problem by itself. Do not set this field unless you
+	// know exactly what you are doing.
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	ManualMaintenanceMode bool `json:"manualMaintenanceMode,omitempty"`
+	// DeletionPolicy defines what happens to the volumes and keys of this instance when it is deleted.
+	// Retained resources are adopted again by an Astarte with the same name, created in the same namespace.
+	// +kubebuilder:validation:Optional
+	DeletionPolicy *AstarteDeletionPolicySpec `json:"deletionPolicy,omitempty"`
+	// When true, the validating webhook rejects the deletion of this instance, and of the
+	// AstarteDefaultIngresses referring to it, until the flag is cleared. The same can be achieved
+	// by setting the api.astarte-platform.org/deletion-protection annotation to "true".
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+	// When enabled, the Operator checks that RabbitMQ and Cassandra are reachable before deploying the
+	// Astarte components.
+	// +kubebuilder:validation:Optional
+	Preflight *AstartePreflightSpec `json:"preflight,omitempty"`
+}
+
+// AstarteStatus defines the observed state of Astarte
+type AstarteStatus struct {
+	ReconciliationPhase ReconciliationPhase  `json:"phase"`
+	AstarteVersion      string               `json:"astarteVersion"`
+	OperatorVersion     string               `json:"operatorVersion"`
+	Health              AstarteClusterHealth `json:"health"`
+	BaseAPIURL          string               `json:"baseAPIURL"`
+	BrokerURL           string               `json:"brokerURL"`
+	// SHA-256 fingerprint of the devices CA certificate.
+	// +optional
+	DeviceCAFingerprint string `json:"deviceCAFingerprint,omitempty"`
+	// Expiry of the devices CA certificate.
+	// +optional
+	DeviceCAExpiry *metav1.Time `json:"deviceCAExpiry,omitempty"`
+	// The state of the Data Updater Plant queue autoscaler, if enabled.
+	// +optional
+	DataUpdaterPlant *AstarteDataUpdaterPlantStatus `json:"dataUpdaterPlant,omitempty"`
+	// The Data Updater Plant shards, and the data queues each of them is consuming from.
+	// +optional
+	DataUpdaterPlantShards []AstarteDataUpdaterPlantShardStatus `json:"dataUpdaterPlantShards,omitempty"`
+	// The progress of the coordinated VerneMQ update, if enabled.
+	// +optional
+	VerneMQUpdate *AstarteVerneMQUpdateStatus `json:"verneMQUpdate,omitempty"`
+	// The Cassandra topology, as last observed by the topology check, if enabled.
+	// +optional
+	CassandraTopology *AstarteCassandraTopologyStatus `json:"cassandraTopology,omitempty"`
+	// The latest observations of the Astarte instance, e.g. the outcome of the connectivity preflight.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// AstarteCassandraTopologyStatus reports the datacenters of the Cassandra cluster
+type AstarteCassandraTopologyStatus struct {
+	// The total number of nodes.
+	Nodes int32 `json:"nodes"`
+	// The datacenters of the cluster, sorted by name.
+	// +optional
+	DataCenters []AstarteCassandraDataCenterStatus `json:"dataCenters,omitempty"`
+}
+
+// AstarteCassandraDataCenterStatus reports a datacenter of the Cassandra cluster
+type AstarteCassandraDataCenterStatus struct {
+	Name  string `json:"name"`
+	Nodes int32  `json:"nodes"`
+}
+
+// AstarteDataUpdaterPlantStatus reports what the Data Updater Plant queue autoscaler observed and decided
+type AstarteDataUpdaterPlantStatus struct {
+	// The number of shards decided by the autoscaler.
+	Shards int32 `json:"shards"`
+	// The total number of messages in the data queues, ready or unacknowledged.
+	Backlog int64 `json:"backlog"`
+	// The total number of consumers of the data queues.
+	Consumers int32 `json:"consumers"`
+	// The number of data queues nobody is consuming from.
+	QueuesWithoutConsumers int32 `json:"queuesWithoutConsumers"`
+	// When the data queues were last observed.
+	// +optional
+	LastObservationTime *metav1.Time `json:"lastObservationTime,omitempty"`
+	// When the number of shards last changed.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// AstarteVerneMQUpdateStatus reports the progress of a coordinated VerneMQ update
+type AstarteVerneMQUpdateStatus struct {
+	// The phase of the update.
+	Phase AstarteVerneMQUpdatePhase `json:"phase"`
+	// The StatefulSet partition: nodes with an ordinal greater or equal than this are updated.
+	Partition int32 `json:"partition"`
+	// The number of VerneMQ nodes.
+	Replicas int32 `json:"replicas"`
+	// The number of VerneMQ nodes running the latest revision.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// The number of sessions currently open across all nodes, as last observed.
+	// +optional
+	Sessions *int64 `json:"sessions,omitempty"`
+	// The number of sessions open right before the last node was replaced.
+	// +optional
+	SessionsBeforeLastStep *int64 `json:"sessionsBeforeLastStep,omitempty"`
+	// When the last node was replaced.
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
+}
+
+// AstarteVerneMQUpdatePhase is the phase of a coordinated VerneMQ update
+type AstarteVerneMQUpdatePhase string
+
+const (
+	// VerneMQUpdatePhaseIdle means all nodes run the latest revision
+	VerneMQUpdatePhaseIdle AstarteVerneMQUpdatePhase = "Idle"
+	// VerneMQUpdatePhaseWaitingForNode means a node is being drained, replaced or is starting up
+	VerneMQUpdatePhaseWaitingForNode AstarteVerneMQUpdatePhase = "WaitingForNode"
+	// VerneMQUpdatePhaseWaitingForSessions means the Operator waits for sessions to be redistributed
+	VerneMQUpdatePhaseWaitingForSessions AstarteVerneMQUpdatePhase = "WaitingForSessions"
+)
+
+// AstarteDataUpdaterPlantShardStatus reports the data queues a Data Updater Plant shard is consuming from
+type AstarteDataUpdaterPlantShardStatus struct {
+	// The name of the shard Deployment.
+	Name string `json:"name"`
+	// The first data queue consumed by the shard. Unset when the shard is drained.
+	// +optional
+	QueueRangeStart *int32 `json:"queueRangeStart,omitempty"`
+	// The last data queue consumed by the shard, inclusive. Unset when the shard is drained.
+	// +optional
+	QueueRangeEnd *int32 `json:"queueRangeEnd,omitempty"`
+	// Whether the shard is rolled out and consuming.
+	Ready bool `json:"ready"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+
+// Astarte is the Schema for the astartes API
+type Astarte struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AstarteSpec   `json:"spec,omitempty"`
+	Status AstarteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AstarteList contains a list of Astarte
+type AstarteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Astarte `json:"items"`
+}
+
+// AstarteClusterHealth represents the overall health of the cluster
+type AstarteClusterHealth string
+
+const (
+	// AstarteClusterHealthRed means the cluster is experiencing serious malfunctions or is down
+	AstarteClusterHealthRed AstarteClusterHealth = "red"
+	// AstarteClusterHealthYellow means the cluster is experiencing downtimes related to a single service
+	AstarteClusterHealthYellow AstarteClusterHealth = "yellow"
+	// AstarteClusterHealthGreen means the cluster is healthy, up and running
+	AstarteClusterHealthGreen AstarteClusterHealth = "green"
+)
+
+// AstarteResourceEvent represents a v1.Event reason for various events. They are all stated
+// in this enum to ease understanding and as a reference to users.
+type AstarteResourceEvent string
+
+const (
+	// AstarteResourceEventInconsistentVersion means the requested Astarte version is inconsistent or unexpected
+	AstarteResourceEventInconsistentVersion AstarteResourceEvent = "ErrInconsistentVersion"
+	// AstarteResourceEventUnsupportedVersion means the requested Astarte version is not supported by the Operator
+	AstarteResourceEventUnsupportedVersion AstarteResourceEvent = "ErrUnsupportedVersion"
+	// AstarteResourceEventMigration means the current Astarte Resource will be migrated from a previous one
+	AstarteResourceEventMigration AstarteResourceEvent = "Migration"
+	// AstarteResourceEventReconciliationFailed means there was a temporary failure in resource Reconciliation
+	AstarteResourceEventReconciliationFailed AstarteResourceEvent = "ErrReconcile"
+	// AstarteResourceEventCriticalError represents an unrecoverable error which requires manual intervention on the cluster
+	AstarteResourceEventCriticalError AstarteResourceEvent = "ErrCritical"
+	// AstarteResourceEventStatus represents a generic Status event - in common situations, this is the most common event type
+	AstarteResourceEventStatus AstarteResourceEvent = "Status"
+	// AstarteResourceEventUpgrade represents an event happening during a Cluster Upgrade
+	AstarteResourceEventUpgrade AstarteResourceEvent = "Upgrade"
+	// AstarteResourceEventUpgradeError represents an error happening during a Cluster Upgrade
+	AstarteResourceEventUpgradeError AstarteResourceEvent = "ErrUpgrade"
+	// AstarteResourceEventPreflight means a backing service passed the connectivity preflight again
+	AstarteResourceEventPreflight AstarteResourceEvent = "Preflight"
+	// AstarteResourceEventPreflightFailed means a backing service failed the connectivity preflight
+	AstarteResourceEventPreflightFailed AstarteResourceEvent = "ErrPreflight"
+	// AstarteResourceEventRabbitMQDrift means the RabbitMQ provisioning found and corrected a drift
+	AstarteResourceEventRabbitMQDrift AstarteResourceEvent = "RabbitMQDrift"
+	// AstarteResourceEventRabbitMQProvisioningFailed means RabbitMQ could not be provisioned
+	AstarteResourceEventRabbitMQProvisioningFailed AstarteResourceEvent = "ErrRabbitMQProvisioning"
+	// AstarteResourceEventCassandraReplication means the Cassandra topology satisfies the keyspace replication again
+	AstarteResourceEventCassandraReplication AstarteResourceEvent = "CassandraReplication"
+	// AstarteResourceEventCassandraReplicationUnsatisfiable means the Cassandra topology cannot satisfy the keyspace replication
+	AstarteResourceEventCassandraReplicationUnsatisfiable AstarteResourceEvent = "ErrCassandraReplication"
+)
+
+const (
+	// AstarteConditionRabbitMQReachable reports the outcome of the RabbitMQ connectivity preflight
+	AstarteConditionRabbitMQReachable = "RabbitMQReachable"
+	// AstarteConditionCassandraReachable reports the outcome of the Cassandra connectivity preflight
+	AstarteConditionCassandraReachable = "CassandraReachable"
+	// AstarteConditionRabbitMQProvisioned reports the outcome of the RabbitMQ provisioning
+	AstarteConditionRabbitMQProvisioned = "RabbitMQProvisioned"
+	// AstarteConditionCassandraReplicationSatisfiable reports whether the Cassandra topology can satisfy the
+	// replication of the Astarte system keyspace
+	AstarteConditionCassandraReplicationSatisfiable = "CassandraReplicationSatisfiable"
+)
+
+// ReconciliationPhase describes the reconciliation phase the Resource is in
+type ReconciliationPhase string
+
+const (
+	// ReconciliationPhaseUnknown represents an Unknown Phase of the Resource. When in this state, it might
+	// have never been reconciled
+	ReconciliationPhaseUnknown ReconciliationPhase = ""
+	// ReconciliationPhaseReconciling means the Resource is currently in the process of being reconciled
+	ReconciliationPhaseReconciling ReconciliationPhase = "Reconciling"
+	// ReconciliationPhaseUpgrading means the Resource is currently in the process of being upgraded to a new Astarte version.
+	// When successful, the Resource will transition to ReconciliationPhaseReconciling
+	ReconciliationPhaseUpgrading ReconciliationPhase = "Upgrading"
+	// ReconciliationPhaseReconciled means the Resource is currently reconciled and stable. The resource should stay in this
+	// state for most of the time.
+	ReconciliationPhaseReconciled ReconciliationPhase = "Reconciled"
+	// ReconciliationPhaseManualMaintenanceMode means the Resource is currently not being reconciled as the resource is in
+	// Manual Maintenance Mode. This happens only when the user explicitly requires that.
+	ReconciliationPhaseManualMaintenanceMode ReconciliationPhase = "Disabled, in Manual Maintenance Mode"
+	// ReconciliationPhaseFailed means the Resource failed to reconcile. If this state persists, a manual intervention
+	// might be necessary.
+	ReconciliationPhaseFailed ReconciliationPhase = "Failed"
+)
+
+// AstarteComponent describes an internal Astarte Component
+type AstarteComponent string
+
+const (
+	// AppEngineAPI represents Astarte AppEngine API
+	AppEngineAPI AstarteComponent = "appengine_api"
+	// DataUpdaterPlant represents Astarte Data Updater Plant
+	DataUpdaterPlant AstarteComponent = "data_updater_plant"
+	// FlowComponent represents Astarte Flow
+	FlowComponent AstarteComponent = "flow"
+	// Housekeeping represents Astarte Housekeeping
+	Housekeeping AstarteComponent = "housekeeping"
+	// Pairing represents Astarte Pairing
+	Pairing AstarteComponent = "pairing"
+	// RealmManagement represents Astarte Realm Management
+	RealmManagement AstarteComponent = "realm_management"
+	// TriggerEngine represents Astarte Trigger Engine
+	TriggerEngine AstarteComponent = "trigger_engine"
+	// Dashboard represents Astarte Dashboard
+	Dashboard AstarteComponent = "dashboard"
+)
+
+type AstarteGenericClusteredResource struct {
+	// +kubebuilder:validation:Optional
+	Deploy *bool `json:"deploy,omitempty"`
+	// +kubebuilder:validation:Optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// +kubebuilder:validation:Optional
+	AntiAffinity *bool `json:"antiAffinity,omitempty"`
+	// +kubebuilder:validation:Optional
+	CustomAffinity *v1.Affinity `json:"customAffinity,omitempty"`
+	// +kubebuilder:validation:Optional
+	DeploymentStrategy *appsv1.DeploymentStrategy `json:"deploymentStrategy,omitempty"`
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// +kubebuilder:validation:Optional
+	ImagePullPolicy *v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// +kubebuilder:validation:Optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Compute Resources for this Component.
+	// +kubebuilder:validation:Optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Additional environment variables for this Component
+	// +kubebuilder:validation:Optional
+	AdditionalEnv []v1.EnvVar `json:"additionalEnv,omitempty"`
+	// Additional labels for this Component's pod(s).
+	// Label keys can't be of the form "app", "component", "astarte-*", "flow-*"
+	// +kubebuilder:validation:Optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+	// Autoscaling resources for this deployment/statefulset.
+	// If autoscaling is enabled, this will take precedence over the "Replicas" field.
+	// +kubebuilder:validation:Optional
+	Autoscale *AstarteGenericClusteredResourceAutoscalerSpec `json:"autoscaler,omitempty"`
+	// The PriorityClass for this component.
+	// Must be one of "high", "mid", "low" or unspecified.
+	// Ignored if astartePodPriorities is not enabled.
+	// +kubebuilder:validation:Enum:=high;mid;low;""
+	// +kubebuilder:validation:Optional
+	PriorityClass string `json:"priorityClass,omitempty"`
+	// Override the default Liveness probe for this component.
+	// If not set, a default HTTP GET probe is configured to check the /health endpoint on the http port.
+	// Default settings: InitialDelaySeconds=10, TimeoutSeconds=5, PeriodSeconds=30, FailureThreshold=5 (15 for Housekeeping).
+	// Note: VerneMQ uses different defaults: /metrics endpoint on port 8888, InitialDelaySeconds=60, PeriodSeconds=20, FailureThreshold=3.
+	// +kubebuilder:validation:Optional
+	LivenessProbe *v1.Probe `json:"livenessProbe,omitempty"`
+	// Override the default Readiness probe for this component.
+	// If not set, a default HTTP GET probe is configured to check the /health endpoint on the http port.
+	// Default settings: InitialDelaySeconds=10, TimeoutSeconds=5, PeriodSeconds=30, FailureThreshold=5 (15 for Housekeeping).
+	// Note: VerneMQ uses different defaults: /metrics endpoint on port 8888, InitialDelaySeconds=60, PeriodSeconds=20, FailureThreshold=3.
+	// +kubebuilder:validation:Optional
+	ReadinessProbe *v1.Probe `json:"readinessProbe,omitempty"`
+	// Override the default Startup probe for this component.
+	// If not set, no startup probe is configured by default.
+	// +kubebuilder:validation:Optional
+	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
+}
+
+type AstarteGenericClusteredResourceAutoscalerSpec struct {
+	// Name of an externally managed HorizontalPodAutoscaler for this deployment/statefulset.
+	// While the HorizontalPodAutoscaler exists, the Operator won't touch the replica count.
+	// Deprecated: describe the autoscaler through maxReplicas and friends to have the Operator manage it instead.
+	// +kubebuilder:validation:Optional
+	Horizontal string `json:"horizontal,omitempty"`
+	// The lower limit for the number of replicas of the Operator managed HorizontalPodAutoscaler.
+	// Defaults to the "Replicas" field of the parent Astarte component, or 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// The upper limit for the number of replicas of the Operator managed HorizontalPodAutoscaler.
+	// When set, the Operator creates and manages a HorizontalPodAutoscaler for this deployment/statefulset.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// The target average CPU utilization, as a percentage of the requested CPU.
+	// If neither a CPU nor a memory target are set, this defaults to 80.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// The target average memory utilization, as a percentage of the requested memory.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// The scaling behavior of the Operator managed HorizontalPodAutoscaler, in both directions.
+	// +kubebuilder:validation:Optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	// TODO: Vertical string `json:"vertical,omitempty"`
+}
+
+type AstartePersistentStorageSpec struct {
+	// +kubebuilder:validation:Optional
+	Size *resource.Quantity `json:"size"`
+	// +kubebuilder:validation:Optional
+	ClassName string `json:"className,omitempty"`
+	// +kubebuilder:validation:Optional
+	VolumeDefinition *v1.Volume `json:"volumeDefinition,omitempty"`
+}
+
+// AnnotationDeletionProtection protects an Astarte instance from deletion, as spec.deletionProtection does
+const AnnotationDeletionProtection = "api.astarte-platform.org/deletion-protection"
+
+// AstartePreflightSpec configures the connectivity checks of RabbitMQ and Cassandra
+type AstartePreflightSpec struct {
+	// When true, the Operator connects to RabbitMQ and to every Cassandra node, reporting whether they
+	// are reachable in the conditions of the Astarte status. Default: false.
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// When true, the checks log in with the configured credentials, besides connecting. Default: false.
+	// +kubebuilder:validation:Optional
+	Authenticate bool `json:"authenticate,omitempty"`
+	// When true, the Astarte components are not deployed until the checks pass. Default: false.
+	// +kubebuilder:validation:Optional
+	HoldDeployment bool `json:"holdDeployment,omitempty"`
+	// The timeout of each check, in seconds. Default: 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// AstarteDeletionPolicySpec defines what happens to the resources holding the state of Astarte on deletion
+type AstarteDeletionPolicySpec struct {
+	// The policy for all the resources, unless overridden by the kind-specific ones. Snapshot retains
+	// the Secrets, as they can't be snapshotted. Default: Delete.
+	// +kubebuilder:validation:Enum:=Delete;Retain;Snapshot
+	// +kubebuilder:validation:Optional
+	Policy AstarteDeletionPolicyType `json:"policy,omitempty"`
+	// The policy for the PersistentVolumeClaims, e.g. the VerneMQ data.
+	// +kubebuilder:validation:Enum:=Delete;Retain;Snapshot
+	// +kubebuilder:validation:Optional
+	PersistentVolumeClaims AstarteDeletionPolicyType `json:"persistentVolumeClaims,omitempty"`
+	// The policy for the Secrets holding generated keys: the devices CA, the Housekeeping key pair
+	// and the secret key base.
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:validation:Optional
+	Secrets AstarteDeletionPolicyType `json:"secrets,omitempty"`
+	// The VolumeSnapshotClass used when snapshotting PersistentVolumeClaims. When not set, the
+	// default VolumeSnapshotClass of the cluster is used.
+	// +kubebuilder:validation:Optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// AstarteDeletionPolicyType identifies what happens to a resource when Astarte is deleted
+type AstarteDeletionPolicyType string
+
+const (
+	// DeletionPolicyDelete deletes the resource together with Astarte
+	DeletionPolicyDelete AstarteDeletionPolicyType = "Delete"
+	// DeletionPolicyRetain keeps the resource around, detached from Astarte
+	DeletionPolicyRetain AstarteDeletionPolicyType = "Retain"
+	// DeletionPolicySnapshot takes a VolumeSnapshot of the resource before deleting it
+	DeletionPolicySnapshot AstarteDeletionPolicyType = "Snapshot"
+)
+
+type AstarteAPISpec struct {
+	// +kubebuilder:validation:Optional
+	SSL  *bool  `json:"ssl,omitempty"`
+	Host string `json:"host"`
+}
+
+// HostAndPort is the address of a service. Which of the fields are required depends on where it is used, and is
+// checked by the validating webhook: e.g. a connection string can provide the RabbitMQ address in their place.
+type HostAndPort struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Optional
+	Host string `json:"host,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	Port *int32 `json:"port,omitempty"`
+}
+
+type LoginCredentialsSecret struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	UsernameKey string `json:"usernameKey"`
+	// +kubebuilder:validation:MinLength=1
+	PasswordKey string `json:"passwordKey"`
+}
+
+type ConnectionStringSecret struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+type GenericConnectionSpec struct {
+	// +kubebuilder:validation:Optional
+	SSLConfiguration GenericSSLConfigurationSpec `json:"sslConfiguration,omitempty"`
+	// The secret containing Username and Password to login.
+	// Either this field or `connectionStringSecret` must be set.
+	// +kubebuilder:validation:Optional
+	CredentialsSecret *LoginCredentialsSecret `json:"credentialsSecret,omitempty"`
+	// The secret containing a connection string to the service, which also provides its address.
+	// Either this field or `credentialsSecret` must be set.
+	// RabbitMQ expects an AMQP URI, i.e. amqp[s]://[username[:password]@]host[:port][/virtual-host].
+	// Cassandra expects a comma-separated list of contact points, optionally preceded by the credentials,
+	// i.e. [username[:password]@]host[:port][,host[:port]...].
+	// +kubebuilder:validation:Optional
+	ConnectionStringSecret *ConnectionStringSecret `json:"connectionStringSecret,omitempty"`
+}
+
+type GenericSSLConfigurationSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// +kubebuilder:validation:Optional
+	CustomCASecret v1.LocalObjectReference `json:"customCASecret,omitempty"`
+	// +kubebuilder:validation:Optional
+	SNI *bool `json:"sni,omitempty"`
+	// +kubebuilder:validation:Optional
+	CustomSNI string `json:"customSNI,omitempty"`
+	// The TLS Secret holding the client certificate and key (tls.crt and tls.key) to authenticate
+	// through mutual TLS. Requires `enable` to be true. When set, credentials may be omitted to rely on
+	// the certificate only.
+	// +kubebuilder:validation:Optional
+	ClientCertSecret v1.LocalObjectReference `json:"clientCertSecret,omitempty"`
+}
+
+type AstarteRabbitMQConnectionSpec struct {
+	// The RabbitMQ host and AMQP port. The host is required and the port defaults to 5672, unless
+	// `connectionStringSecret` is set.
+	HostAndPort `json:",inline"`
+	// +kubebuilder:validation:Optional
+	GenericConnectionSpec `json:",inline"`
+	// The RabbitMQ virtual host. Defaults to "/", unless `connectionStringSecret` is set.
+	// +kubebuilder:validation:Optional
+	VirtualHost string `json:"virtualHost,omitempty"`
+}
+
+type AstarteRabbitMQSpec struct {
+	// +kubebuilder:validation:Required
+	Connection *AstarteRabbitMQConnectionSpec `json:"connection,omitempty"`
+	// Configures the data queues prefix on RabbitMQ. You should change this setting only
+	// in custom RabbitMQ installations.
+	// +kubebuilder:validation:Optional
+	DataQueuesPrefix string `json:"dataQueuesPrefix,omitempty"`
+	// Configures the events exchange name on RabbitMQ. You should change this setting only
+	// in custom RabbitMQ installations.
+	// +kubebuilder:validation:Optional
+	EventsExchangeName string `json:"eventsExchangeName,omitempty"`
+	// The URL of the RabbitMQ management API, used by the Operator to inspect RabbitMQ.
+	// Defaults to port 15672 of the RabbitMQ host, over HTTPS when SSL is enabled.
+	// +kubebuilder:validation:Optional
+	ManagementURL string `json:"managementURL,omitempty"`
+	// When set, the Operator provisions the virtual host, the Astarte user and its permissions, and
+	// optionally the data queues policy through the management API, correcting any drift.
+	// +kubebuilder:validation:Optional
+	Provisioning *AstarteRabbitMQProvisioningSpec `json:"provisioning,omitempty"`
+}
+
+// AstarteRabbitMQProvisioningSpec configures how the Operator provisions RabbitMQ for Astarte
+type AstarteRabbitMQProvisioningSpec struct {
+	// The Secret holding the credentials of a RabbitMQ administrator, used for provisioning only.
+	// +kubebuilder:validation:Required
+	AdminCredentialsSecret *LoginCredentialsSecret `json:"adminCredentialsSecret"`
+	// The URL of the RabbitMQ management API used for provisioning. Defaults to `managementURL`.
+	// +kubebuilder:validation:Optional
+	ManagementURL string `json:"managementURL,omitempty"`
+	// The policy applied to the data queues, and to the VerneMQ mirror queue if any. When not set,
+	// no policy is provisioned.
+	// +kubebuilder:validation:Optional
+	DataQueuesPolicy *AstarteRabbitMQQueuePolicySpec `json:"dataQueuesPolicy,omitempty"`
+}
+
+// AstarteRabbitMQQueuePolicySpec describes a RabbitMQ queue mirroring policy
+type AstarteRabbitMQQueuePolicySpec struct {
+	// The name of the policy. Default: astarte-data-queues.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// The priority of the policy. Default: 0.
+	// +kubebuilder:validation:Optional
+	Priority int32 `json:"priority,omitempty"`
+	// How queues are mirrored across the RabbitMQ cluster nodes: to all of them, or to exactly `haParams` nodes.
+	// +kubebuilder:validation:Enum=all;exactly
+	// +kubebuilder:validation:Required
+	HAMode string `json:"haMode"`
+	// The number of nodes queues are mirrored to. Required when `haMode` is exactly.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	HAParams *int32 `json:"haParams,omitempty"`
+	// Whether new mirrors are synchronized automatically or manually. Defaults to RabbitMQ's own default.
+	// +kubebuilder:validation:Enum=automatic;manual
+	// +kubebuilder:validation:Optional
+	HASyncMode string `json:"haSyncMode,omitempty"`
+}
+
+type AstarteCassandraConnectionSpec struct {
+	GenericConnectionSpec `json:",inline"`
+	// The Cassandra nodes. Required, unless `connectionStringSecret` is set.
+	Nodes []HostAndPort `json:"nodes,omitempty"`
+	// +kubebuilder:validation:Optional
+	PoolSize *int `json:"poolSize,omitempty"`
+	// Enable or disable the keepalive option for the xandra connection.
+	// Default: true
+	// +kubebuilder:validation:Optional
+	EnableKeepalive *bool `json:"enableKeepalive,omitempty"`
+}
+
+type AstarteCassandraSpec struct {
+	// +kubebuilder:validation:Required
+	Connection *AstarteCassandraConnectionSpec `json:"connection,omitempty"`
+	// +kubebuilder:validation:Optional
+	AstarteSystemKeyspace AstarteSystemKeyspaceSpec `json:"astarteSystemKeyspace,omitempty"`
+	// When enabled, the Operator reads the Cassandra topology and checks that the replication of
+	// the Astarte system keyspace can be satisfied.
+	// +kubebuilder:validation:Optional
+	TopologyCheck *AstarteCassandraTopologyCheckSpec `json:"topologyCheck,omitempty"`
+}
+
+// AstarteCassandraTopologyCheckSpec configures the Cassandra topology check
+type AstarteCassandraTopologyCheckSpec struct {
+	// When true, the Operator connects to Cassandra to read its datacenters and nodes, reporting them
+	// in the status and flagging replication settings which cannot be satisfied. Default: false.
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The timeout of the connection to each node, in seconds. Default: 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+type AstarteVerneMQSpec struct {
+	AstarteGenericClusteredResource `json:",inline"`
+	// The host devices connect to the broker through.
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+	// The port devices connect to the broker through. Defaults to 8883.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	Port *int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Optional
+	CaSecret string `json:"caSecret,omitempty"`
+	// +kubebuilder:validation:Optional
+	Storage *AstartePersistentStorageSpec `json:"storage,omitempty"`
+	// Controls the device heartbeat from the broker to Astarte. The heartbeat is sent periodically
+	// to prevent Astarte from keeping up stale connections from Devices in case the broker misbehaves
+	// and does not send disconnection events. You should usually not tweak this value. Moreover, keep
+	// in mind that when a lot of devices are connected simultaneously, having a short heartbeat time
+	// might cause performance issues. Defaults to an hour.
+	// +kubebuilder:validation:Optional
+	DeviceHeartbeatSeconds int `json:"deviceHeartbeatSeconds,omitempty"`
+	// The maximum number of QoS 1 or 2 messages to hold in the offline queue.
+	// Defaults to 1000000. Set to -1 for no maximum (not recommended). Set to 0
+	// if no messages should be stored offline.
+	// +kubebuilder:validation:Optional
+	MaxOfflineMessages *int `json:"maxOfflineMessages,omitempty"`
+	// This option allows persistent clients ( = clean session set to
+	// false) to be removed if they do not reconnect within 'persistent_client_expiration'.
+	// This is a non-standard option. As far as the MQTT specification is concerned,
+	// persistent clients persist forever.
+	// The expiration period should be an integer followed by one of 'd', 'w', 'm', 'y' for
+	// day, week, month, and year.
+	// Default: 1 year
+	// +kubebuilder:validation:Optional
+	PersistentClientExpiration string `json:"persistentClientExpiration,omitempty"`
+	// +kubebuilder:validation:Optional
+	MirrorQueue string `json:"mirrorQueue,omitempty"`
+	// This option allows, when true, to handle SSL termination at VerneMQ level.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	SSLListener *bool `json:"sslListener,omitempty"`
+	// Reference the name of the secret containing the TLS certificate for VerneMQ.
+	// The secret must be present in the same namespace in which Astarte resides.
+	// The field will be used only if SSLListener is set to true.
+	// +kubebuilder:validation:Optional
+	SSLListenerCertSecretName string `json:"sslListenerCertSecretName,omitempty"`
+	// When true, VerneMQ exposes a tcp listener on port 1886 which expects the PROXY protocol header, so that
+	// it sees the real IP of devices connecting through a load balancer, and the broker load balancer targets
+	// it instead of the SSL listener. The load balancer must then terminate TLS and forward the client
+	// certificate in the PROXY protocol v2 header, as VerneMQ takes the device identity from its common name.
+	// Default: false.
+	// +optional
+	ProxyProtocol *bool `json:"proxyProtocol,omitempty"`
+	// Controls how VerneMQ pods are replaced when the StatefulSet changes.
+	// +kubebuilder:validation:Optional
+	UpdateStrategy *AstarteVerneMQUpdateStrategySpec `json:"updateStrategy,omitempty"`
+	// Additional listeners, besides the ones the Operator always configures.
+	// TLS listeners (ssl, wss) use the certificate referenced by SSLListenerCertSecretName.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:Optional
+	Listeners []AstarteVerneMQListenerSpec `json:"listeners,omitempty"`
+	// Arbitrary vernemq.conf settings, such as "max_inflight_messages" or "max_message_size".
+	// Settings managed by the Operator, listeners included, cannot be overridden here.
+	// +kubebuilder:validation:Optional
+	ConfigOverrides map[string]string `json:"configOverrides,omitempty"`
+}
+
+// AstarteVerneMQListenerSpec defines an additional VerneMQ listener
+type AstarteVerneMQListenerSpec struct {
+	// The name of the listener. It is used as the name of the container and Service ports, too.
+	// +kubebuilder:validation:Pattern:=`^[a-z]([a-z0-9-]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength:=15
+	Name string `json:"name"`
+	// The kind of listener: plain MQTT ("tcp"), MQTT over TLS ("ssl"), MQTT over WebSocket ("ws")
+	// or MQTT over secure WebSocket ("wss").
+	// +kubebuilder:validation:Enum:=tcp;ssl;ws;wss
+	Type AstarteVerneMQListenerType `json:"type"`
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	Port int32 `json:"port"`
+	// Expect the PROXY protocol header on incoming connections. Only supported by tcp and ws listeners.
+	// +kubebuilder:validation:Optional
+	ProxyProtocol bool `json:"proxyProtocol,omitempty"`
+}
+
+// AstarteVerneMQListenerType identifies the kind of a VerneMQ listener
+type AstarteVerneMQListenerType string
+
+const (
+	// VerneMQListenerTCP is a plain MQTT listener
+	VerneMQListenerTCP AstarteVerneMQListenerType = "tcp"
+	// VerneMQListenerSSL is an MQTT over TLS listener
+	VerneMQListenerSSL AstarteVerneMQListenerType = "ssl"
+	// VerneMQListenerWS is an MQTT over WebSocket listener
+	VerneMQListenerWS AstarteVerneMQListenerType = "ws"
+	// VerneMQListenerWSS is an MQTT over secure WebSocket listener
+	VerneMQListenerWSS AstarteVerneMQListenerType = "wss"
+)
+
+// AstarteVerneMQUpdateStrategySpec defines how VerneMQ nodes are updated
+type AstarteVerneMQUpdateStrategySpec struct {
+	// "RollingUpdate" (the default) lets Kubernetes replace VerneMQ pods on its own.
+	// "Coordinated" makes the Operator replace one node at a time, moving on only once sessions are
+	// redistributed. Nodes removed by a scale down leave the cluster first, migrating their queues.
+	// +kubebuilder:validation:Enum:=RollingUpdate;Coordinated;""
+	// +kubebuilder:validation:Optional
+	Type AstarteVerneMQUpdateStrategyType `json:"type,omitempty"`
+	// How long a node removed by a scale down has to leave the cluster and migrate its queues before
+	// being stopped.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	DrainTimeoutSeconds *int32 `json:"drainTimeoutSeconds,omitempty"`
+	// The percentage of the sessions open before a node was replaced which must be open again
+	// before the next node is replaced. Defaults to 90.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=100
+	// +kubebuilder:validation:Optional
+	SessionRecoveryPercentage *int32 `json:"sessionRecoveryPercentage,omitempty"`
+	// How long to wait for sessions to be redistributed before replacing the next node anyway.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	SessionRedistributionTimeoutSeconds *int32 `json:"sessionRedistributionTimeoutSeconds,omitempty"`
+}
+
+// AstarteVerneMQUpdateStrategyType identifies how VerneMQ nodes are updated
+type AstarteVerneMQUpdateStrategyType string
+
+const (
+	// VerneMQRollingUpdateStrategy relies on the standard StatefulSet rolling update
+	VerneMQRollingUpdateStrategy AstarteVerneMQUpdateStrategyType = "RollingUpdate"
+	// VerneMQCoordinatedUpdateStrategy replaces VerneMQ nodes one at a time, draining them first
+	VerneMQCoordinatedUpdateStrategy AstarteVerneMQUpdateStrategyType = "Coordinated"
+)
+
+type AstarteDataUpdaterPlantSpec struct {
+	AstarteGenericClusteredResource `json:",inline"`
+	// +kubebuilder:validation:Optional
+	DataQueueCount *int `json:"dataQueueCount,omitempty"`
+	// Controls the prefetch count for Data Updater Plant. When fine-tuning Astarte, this parameter
+	// can make a difference for what concerns Data Updater Plant ingestion performance. However,
+	// it can also degrade performance significantly and/or increase risk of data loss when misconfigured.
+	// Configure this value only if you know what you're doing and you have experience with RabbitMQ.
+	// Defaults to 300
+	// +kubebuilder:validation:Optional
+	PrefetchCount *int `json:"prefetchCount,omitempty"`
+	// Scales the number of Data Updater Plant shards according to the backlog of the data queues.
+	// Requires the autoscaling feature to be enabled. When active, it takes precedence over the "Replicas" field.
+	// +kubebuilder:validation:Optional
+	QueueAutoscaler *AstarteDataUpdaterPlantQueueAutoscalerSpec `json:"queueAutoscaler,omitempty"`
+}
+
+// AstarteDataUpdaterPlantQueueAutoscalerSpec configures the queue-depth driven autoscaling of Data Updater Plant.
+// The backlog is read from the RabbitMQ management API.
+type AstarteDataUpdaterPlantQueueAutoscalerSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The lower limit for the number of shards. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MinShards *int32 `json:"minShards,omitempty"`
+	// The upper limit for the number of shards. Cannot exceed the data queue count.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MaxShards int32 `json:"maxShards,omitempty"`
+	// The number of messages waiting in the data queues each shard is expected to handle. Defaults to 1000.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetBacklogPerShard *int64 `json:"targetBacklogPerShard,omitempty"`
+	// Scale down hysteresis: a shard is removed only when the backlog falls below this percentage of what the
+	// remaining shards are expected to handle. Defaults to 50.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +kubebuilder:validation:Optional
+	ScaleDownThresholdPercentage *int32 `json:"scaleDownThresholdPercentage,omitempty"`
+	// Minimum time between a scaling event and a subsequent scale up. Defaults to 60.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	ScaleUpCooldownSeconds *int32 `json:"scaleUpCooldownSeconds,omitempty"`
+	// Minimum time between a scaling event and a subsequent scale down. Defaults to 300.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	ScaleDownCooldownSeconds *int32 `json:"scaleDownCooldownSeconds,omitempty"`
+	// How often the data queues are polled. Defaults to 30.
+	// +kubebuilder:validation:Minimum:=5
+	// +kubebuilder:validation:Optional
+	PollIntervalSeconds *int32 `json:"pollIntervalSeconds,omitempty"`
+}
+
+type AstarteTriggerEngineSpec struct {
+	AstarteGenericClusteredResource `json:",inline"`
+	// Configures the name of the Events queue. Should be configured only in installations with a highly
+	// customized RabbitMQ. It is advised to leave empty unless you know exactly what you're doing.
+	// +kubebuilder:validation:Optional
+	EventsQueueName string `json:"eventsQueueName,omitempty"`
+	// Configures the routing key for Trigger Events. Should be configured only in installations
+	// with a highly customized RabbitMQ and a custom Trigger Engine setup. It is advised to leave
+	// empty unless you know exactly what you're doing, misconfiguring this value can cause heavy
+	// breakage within Trigger Engine.
+	// +kubebuilder:validation:Optional
+	EventsRoutingKey string `json:"eventsRoutingKey,omitempty"`
+}
+
+type AstarteAppengineAPISpec struct {
+	AstarteGenericAPIComponentSpec `json:",inline"`
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Optional
+	MaxResultsLimit *int `json:"maxResultsLimit,omitempty"`
+	// Configures the name of the Room Events queue. Should be configured only in installations with a highly
+	// customized RabbitMQ. It is advised to leave empty unless you know exactly what you're doing.
+	// +kubebuilder:validation:Optional
+	RoomEventsQueueName string `json:"roomEventsQueueName,omitempty"`
+	// Configures the name of the Room Events exchange. Should be configured only in installations with a highly
+	// customized RabbitMQ. It is advised to leave empty unless you know exactly what you're doing.
+	// +kubebuilder:validation:Optional
+	RoomEventsExchangeName string `json:"roomEventsExchangeName,omitempty"`
+}
+
+type AstarteDashboardConfigAuthSpec struct {
+	Type string `json:"type"`
+	// +kubebuilder:validation:Optional
+	OAuthAPIURL string `json:"oauthAPIURL,omitempty"`
+}
+
+type AstarteDashboardConfigSpec struct {
+	// +kubebuilder:validation:Optional
+	RealmManagementAPIURL string `json:"realmManagementApiUrl,omitempty"`
+	// +kubebuilder:validation:Optional
+	AppEngineAPIURL string `json:"appEngineApiUrl,omitempty"`
+	// +kubebuilder:validation:Optional
+	PairingAPIURL string `json:"pairingApiUrl,omitempty"`
+	// +kubebuilder:validation:Optional
+	FlowAPIURL string `json:"flowApiUrl,omitempty"`
+	// +kubebuilder:validation:Optional
+	DefaultRealm string `json:"defaultRealm,omitempty"`
+	// +kubebuilder:validation:Optional
+	DefaultAuth string `json:"defaultAuth,omitempty"`
+	// +kubebuilder:validation:Optional
+	Auth []AstarteDashboardConfigAuthSpec `json:"auth,omitempty"`
+}
+
+type AstarteDashboardSpec struct {
+	AstarteGenericClusteredResource `json:",inline"`
+	// +kubebuilder:validation:Optional
+	AstarteDashboardConfigSpec `json:",inline"`
+}
+
+type AstarteGenericAPIComponentSpec struct {
+	AstarteGenericClusteredResource `json:",inline"`
+	// +kubebuilder:validation:Optional
+	DisableAuthentication *bool `json:"disableAuthentication,omitempty"`
+}
+
+type AstarteComponentsSpec struct {
+	// Compute Resources for this Component.
+	// +kubebuilder:validation:Optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// +kubebuilder:validation:Optional
+	Flow AstarteGenericAPIComponentSpec `json:"flow,omitempty"`
+	// +kubebuilder:validation:Optional
+	Housekeeping AstarteGenericAPIComponentSpec `json:"housekeeping,omitempty"`
+	// +kubebuilder:validation:Optional
+	RealmManagement AstarteGenericAPIComponentSpec `json:"realmManagement,omitempty"`
+	// +kubebuilder:validation:Optional
+	Pairing AstarteGenericAPIComponentSpec `json:"pairing,omitempty"`
+	// +kubebuilder:validation:Optional
+	DataUpdaterPlant AstarteDataUpdaterPlantSpec `json:"dataUpdaterPlant,omitempty"`
+	// +kubebuilder:validation:Optional
+	AppengineAPI AstarteAppengineAPISpec `json:"appengineApi,omitempty"`
+	// +kubebuilder:validation:Optional
+	TriggerEngine AstarteTriggerEngineSpec `json:"triggerEngine,omitempty"`
+	// +kubebuilder:validation:Optional
+	Dashboard AstarteDashboardSpec `json:"dashboard,omitempty"`
+}
+
+type AstarteCFSSLDBConfigSpec struct {
+	Driver     string `json:"driver,omitempty"`
+	DataSource string `json:"dataSource,omitempty"`
+}
+
+type AstarteCFSSLCSRRootCAKeySpec struct {
+	Algo string `json:"algo"`
+	Size int    `json:"size"`
+}
+
+type AstarteCFSSLCSRRootCANamesSpec struct {
+	C  string `json:"C"`
+	L  string `json:"L"`
+	O  string `json:"O"`
+	OU string `json:"OU"`
+	ST string `json:"ST"`
+}
+
+type AstarteCFSSLCSRRootCASpec struct {
+	CN     string                           `json:"CN"`
+	Key    *AstarteCFSSLCSRRootCAKeySpec    `json:"key"`
+	Names  []AstarteCFSSLCSRRootCANamesSpec `json:"names"`
+	Expiry string                           `json:"expiry"`
+}
+
+type AstarteCFSSLCARootConfigSigningCAConstraintSpec struct {
+	MaxPathLen     int  `json:"maxPathLen"`
+	IsCA           bool `json:"isCA"`
+	MaxPathLenZero bool `json:"maxPathLenZero"`
+}
+
+type AstarteCFSSLCARootConfigSigningDefaultSpec struct {
+	Usages       []string                                         `json:"usages"`
+	Expiry       string                                           `json:"expiry"`
+	CAConstraint *AstarteCFSSLCARootConfigSigningCAConstraintSpec `json:"caConstraint"`
+}
+
+type AstarteCFSSLCARootConfigSpec struct {
+	SigningDefault *AstarteCFSSLCARootConfigSigningDefaultSpec `json:"signingDefault"`
+}
+
+type AstarteCFSSLSpec struct {
+	// +kubebuilder:validation:Optional
+	Deploy *bool `json:"deploy,omitempty"`
+	// The backend in charge of signing device certificates.
+	// "cfssl" (the default) deploys and manages a CFSSL instance. "operator" makes the Operator itself
+	// sign device certificates, exposing the same CFSSL-compatible API without running CFSSL.
+	// When a custom URL is set, the backend is ignored and the given URL is used as is.
+	// +kubebuilder:validation:Enum:=cfssl;operator;""
+	// +kubebuilder:validation:Optional
+	Backend AstarteDeviceCABackend `json:"backend,omitempty"`
+	// +kubebuilder:validation:Optional
+	URL string `json:"url,omitempty"`
+	// +kubebuilder:validation:Optional
+	CaExpiry string `json:"caExpiry,omitempty"`
+	// +kubebuilder:validation:Optional
+	CASecret v1.LocalObjectReference `json:"caSecret,omitempty"`
+	// +kubebuilder:validation:Optional
+	CertificateExpiry string `json:"certificateExpiry,omitempty"`
+	// +kubebuilder:validation:Optional
+	DBConfig *AstarteCFSSLDBConfigSpec `json:"dbConfig,omitempty"`
+	// Compute Resources for this Component.
+	// +kubebuilder:validation:Optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// Deprecated: CFSSL is deployed without persistent storage, hence this field has no effect. It is still
+	// accepted, so that existing resources keep validating.
+	// +kubebuilder:validation:Optional
+	Storage *AstartePersistentStorageSpec `json:"storage,omitempty"`
+	// +kubebuilder:validation:Optional
+	CSRRootCa *AstarteCFSSLCSRRootCASpec `json:"csrRootCa,omitempty"`
+	// +kubebuilder:validation:Optional
+	CARootConfig *AstarteCFSSLCARootConfigSpec `json:"caRootConfig,omitempty"`
+	// Publish the devices CA certificate chain and the broker URL for external consumers.
+	// +kubebuilder:validation:Optional
+	PublishCABundle *AstarteDeviceCABundleSpec `json:"publishCABundle,omitempty"`
+	// Additional labels for this Component's pod(s).
+	// Label keys can't be of the form "app", "component", "astarte-*", "flow-*"
+	// +kubebuilder:validation:Optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+	// The PriorityClass for this component.
+	// Must be one of "high", "mid", "low" or unspecified.
+	// Ignored if astartePodPriorities is not enabled.
+	// +kubebuilder:validation:Enum:=high;mid;low;""
+	// +kubebuilder:validation:Optional
+	PriorityClass string `json:"priorityClass,omitempty"`
+	// Override the default Liveness probe for CFSSL.
+	// If not set, a default HTTP GET probe is configured to check the /api/v1/cfssl/health endpoint on the http port.
+	// Default settings: InitialDelaySeconds=10, TimeoutSeconds=5, PeriodSeconds=20, FailureThreshold=3.
+	// +kubebuilder:validation:Optional
+	LivenessProbe *v1.Probe `json:"livenessProbe,omitempty"`
+	// Override the default Readiness probe for CFSSL.
+	// If not set, a default HTTP GET probe is configured to check the /api/v1/cfssl/health endpoint on the http port.
+	// Default settings: InitialDelaySeconds=10, TimeoutSeconds=5, PeriodSeconds=20, FailureThreshold=3.
+	// +kubebuilder:validation:Optional
+	ReadinessProbe *v1.Probe `json:"readinessProbe,omitempty"`
+	// Override the default Startup probe for CFSSL.
+	// If not set, no startup probe is configured by default.
+	// +kubebuilder:validation:Optional
+	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
+}
+
+// AstarteDeviceCABackend identifies the backend in charge of signing device certificates
+type AstarteDeviceCABackend string
+
+const (
+	// DeviceCABackendCFSSL signs device certificates through an Operator-managed CFSSL Deployment
+	DeviceCABackendCFSSL AstarteDeviceCABackend = "cfssl"
+	// DeviceCABackendOperator signs device certificates through the CFSSL-compatible API served by the Operator
+	DeviceCABackendOperator AstarteDeviceCABackend = "operator"
+)
+
+// AstarteDeviceCABundleSpec defines how the devices CA bundle is published. The bundle is a ConfigMap
+// holding the devices CA certificate chain (never the key) and the VerneMQ broker URL.
+type AstarteDeviceCABundleSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The name of the ConfigMap holding the bundle. Defaults to <astarte-name>-devices-ca-bundle.
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// When set, the bundle is mirrored into all namespaces matching the selector, in addition
+	// to the namespace of the Astarte instance.
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// This interface is implemented by all Astarte components which have a podLabels field.
+// +k8s:deepcopy-gen=false
+type PodLabelsGetter interface {
+	GetPodLabels() map[string]string
+}
+
+// AstarteSystemKeyspaceSpec configures the ScyllaDB/Cassandra keyspace for Astarte.
+//
+// By configuring these fields, you control the replication strategy, replication factor, and (for multi-datacenter
+// deployments) the replica distribution per datacenter. These settings take effect only upon keyspace creation.
+//
+// Fields:
+//   - ReplicationStrategy chooses the replication strategy for the keyspace.
+//   - ReplicationFactor (for SimpleStrategy or for default replication factor with NetworkTopologyStrategy).
+//   - DataCenterReplication (for flexible NetworkTopologyStrategy configurations).
+type AstarteSystemKeyspaceSpec struct {
+	// ReplicationStrategy specifies the Cassandra/ScyllaDB replication strategy for the keyspace.
+	// Must be either "SimpleStrategy" or "NetworkTopologyStrategy" (for production deployments and/or
+	// multi-datacenter deployments).
+	// Defaults to "SimpleStrategy".
+	// +kubebuilder:default:=SimpleStrategy
+	// +kubebuilder:validation:Enum=SimpleStrategy;NetworkTopologyStrategy
+	ReplicationStrategy string `json:"replicationStrategy,omitempty"`
+	// ReplicationFactor sets the total number of replicas for the keyspace when using SimpleStrategy.
+	// This field is ignored if ReplicationStrategy is set to NetworkTopologyStrategy.
+	// Must be at least 1. Must be odd. Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=1
+	ReplicationFactor int `json:"replicationFactor,omitempty"`
+	// DataCenterReplication specifies custom replication factors per datacenter when using NetworkTopologyStrategy.
+	// If set, this string must be a comma-separated list of <DataCenter>:<ReplicationFactor> entries
+	// (e.g., "dc1:3,dc2:5"). <ReplicationFactor> must be odd.
+	// This field is ignored if ReplicationStrategy is set to SimpleStrategy.
+	// +kubebuilder:validation:Optional
+	DataCenterReplication string `json:"dataCenterReplication,omitempty"`
+}
+
+// AstartePodPriorities allows to set different priorityClasses for Astarte pods.
+// Note that enabling this feature might generate some counter-intuitive
+// scheduling beahaviour if not done properly.
+type AstartePodPrioritiesSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The value of the highest PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-high-priority-non-preemptive-2000: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=1000
+	// +kubebuilder:validation:Minimum:=0
+	AstarteHighPriority *int `json:"astarteHighPriority,omitempty"`
+	// The value of the medium PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-mid-priority-non-preemptive-200: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=100
+	// +kubebuilder:validation:Minimum:=0
+	AstarteMidPriority *int `json:"astarteMidPriority,omitempty"`
+	// The value of the least PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-low-priority-non-preemptive-20: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum:=0
+	AstarteLowPriority *int `json:"astarteLowPriority,omitempty"`
+	// The name of the highest PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteHighPriorityClassName string `json:"astarteHighPriorityClassName,omitempty"`
+	// The name of the medium PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteMidPriorityClassName string `json:"astarteMidPriorityClassName,omitempty"`
+	// The name of the least PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteLowPriorityClassName string `json:"astarteLowPriorityClassName,omitempty"`
+}
+
+// AstarteFDOSpec configures FDO support in Astarte.
+// This feature is EXPERIMENTAL, expect breaking changes in future releases.
+type AstarteFDOSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// +kubebuilder:validation:Optional
+	RendezvousServer HostAndPort `json:"rendezvousServer,omitempty"`
+}
+
+// AstarteFeatures enables/disables selectively a set of global features in Astarte
+type AstarteFeatures struct {
+	// +kubebuilder:validation:Optional
+	RealmDeletion bool `json:"realmDeletion,omitempty"`
+	// +kubebuilder:validation:Optional
+	Autoscaling bool `json:"autoscaling,omitempty"`
+	// +kubebuilder:validation:Optional
+	AstartePodPriorities *AstartePodPrioritiesSpec `json:"astartePodPriorities,omitempty"`
+	// +kubebuilder:validation:Optional
+	FDO *AstarteFDOSpec `json:"fdo,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Astarte{}, &AstarteList{})
+}
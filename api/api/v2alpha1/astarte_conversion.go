@@ -0,0 +1,85 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/api/api/v1beta1"
+)
+
+var _ conversion.Convertible = &Astarte{}
+
+// ConvertTo converts this Astarte to the Hub version (v1beta1)
+func (src *Astarte) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Astarte)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Besides a few renamed fields, handled below, the two versions share the same JSON representation
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	for i, auth := range src.Spec.Components.Dashboard.Auth {
+		dst.Spec.Components.Dashboard.Auth[i] = v1beta1.AstarteDashboardConfigAuthSpec(auth)
+	}
+	if c := src.Spec.CFSSL.CARootConfig; c != nil && c.SigningDefault != nil && c.SigningDefault.CAConstraint != nil {
+		caConstraint := v1beta1.AstarteCFSSLCARootConfigSigningCAConstraintSpec(*c.SigningDefault.CAConstraint)
+		dst.Spec.CFSSL.CARootConfig.SigningDefault.CAConstraint = &caConstraint
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version
+func (dst *Astarte) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Astarte)
+	dst.ObjectMeta = src.ObjectMeta
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	for i, auth := range src.Spec.Components.Dashboard.Auth {
+		dst.Spec.Components.Dashboard.Auth[i] = AstarteDashboardConfigAuthSpec(auth)
+	}
+	if c := src.Spec.CFSSL.CARootConfig; c != nil && c.SigningDefault != nil && c.SigningDefault.CAConstraint != nil {
+		caConstraint := AstarteCFSSLCARootConfigSigningCAConstraintSpec(*c.SigningDefault.CAConstraint)
+		dst.Spec.CFSSL.CARootConfig.SigningDefault.CAConstraint = &caConstraint
+	}
+
+	return nil
+}
+
+// convertJSON copies src into dst through their JSON representation. Fields unknown to dst are dropped.
+func convertJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
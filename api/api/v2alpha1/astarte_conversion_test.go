@@ -0,0 +1,91 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/api/api/v1beta1"
+)
+
+var _ = Describe("Astarte conversion", func() {
+	var cr *Astarte
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.ObjectMeta = metav1.ObjectMeta{Name: "example-astarte", Namespace: "default", Labels: map[string]string{"team": "iot"}}
+		cr.Spec.VerneMQ.Host = "broker.astarte.example.com"
+		cr.Spec.VerneMQ.Port = pointy.Int32(443)
+		cr.Spec.Components.Dashboard.Auth = []AstarteDashboardConfigAuthSpec{{Type: "oauth", OAuthAPIURL: "https://auth.example.com"}}
+		cr.Spec.CFSSL.CARootConfig = &AstarteCFSSLCARootConfigSpec{
+			SigningDefault: &AstarteCFSSLCARootConfigSigningDefaultSpec{
+				Usages:       []string{"cert sign"},
+				Expiry:       "8760h",
+				CAConstraint: &AstarteCFSSLCARootConfigSigningCAConstraintSpec{MaxPathLen: 1, IsCA: true},
+			},
+		}
+		// Timestamps are serialized with a precision of a second
+		expiry := metav1.NewTime(time.Now().Truncate(time.Second))
+		cr.Status = AstarteStatus{
+			ReconciliationPhase: ReconciliationPhaseReconciled,
+			AstarteVersion:      "1.3.0",
+			Health:              AstarteClusterHealthGreen,
+			DeviceCAExpiry:      &expiry,
+		}
+	})
+
+	It("should convert to v1beta1 and back without losing data", func() {
+		hub := &v1beta1.Astarte{}
+		Expect(cr.ConvertTo(hub)).To(Succeed())
+		Expect(hub.ObjectMeta).To(Equal(cr.ObjectMeta))
+
+		converted := &Astarte{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.ObjectMeta).To(Equal(cr.ObjectMeta))
+		Expect(converted.Spec).To(Equal(cr.Spec))
+		Expect(converted.Status).To(Equal(cr.Status))
+	})
+
+	It("should convert the fields renamed in v1beta1", func() {
+		hub := &v1beta1.Astarte{}
+		Expect(cr.ConvertTo(hub)).To(Succeed())
+
+		Expect(hub.Spec.VerneMQ.Host).To(Equal("broker.astarte.example.com"))
+		Expect(hub.Spec.VerneMQ.Port).To(Equal(pointy.Int32(443)))
+		Expect(hub.Spec.Components.Dashboard.Auth).To(ConsistOf(v1beta1.AstarteDashboardConfigAuthSpec{Type: "oauth", OAuthAPIURL: "https://auth.example.com"}))
+		Expect(hub.Spec.CFSSL.CARootConfig.SigningDefault.CAConstraint).To(Equal(&v1beta1.AstarteCFSSLCARootConfigSigningCAConstraintSpec{MaxPathLen: 1, IsCA: true}))
+		Expect(hub.Status.AstarteVersion).To(Equal("1.3.0"))
+	})
+
+	It("should convert from v1beta1 and back without losing data", func() {
+		hub := &v1beta1.Astarte{}
+		Expect(cr.ConvertTo(hub)).To(Succeed())
+
+		spoke := &Astarte{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		converted := &v1beta1.Astarte{}
+		Expect(spoke.ConvertTo(converted)).To(Succeed())
+		Expect(converted).To(Equal(hub))
+	})
+})
@@ -0,0 +1,234 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DefaultReplicas is the number of replicas of a clustered resource, when not specified
+	DefaultReplicas int32 = 1
+	// DefaultVerneMQPort is the port devices connect to VerneMQ on
+	DefaultVerneMQPort int32 = 8883
+	// VerneMQProxyProtocolPort is the port of the tcp listener expecting the PROXY protocol, see vernemq.proxyProtocol
+	VerneMQProxyProtocolPort int32 = 1886
+	// DefaultVerneMQMaxOfflineMessages is the maximum number of QoS 1 or 2 messages held for offline clients
+	DefaultVerneMQMaxOfflineMessages = 1000000
+	// DefaultRabbitMQPort is the AMQP port of RabbitMQ
+	DefaultRabbitMQPort int32 = 5672
+	// DefaultDataQueueCount is the number of data queues consumed by Data Updater Plant
+	DefaultDataQueueCount = 128
+	// DefaultDataUpdaterPlantPrefetchCount is the prefetch count of Data Updater Plant
+	DefaultDataUpdaterPlantPrefetchCount = 300
+	// DefaultAppengineAPIMaxResultsLimit is the maximum number of results returned by AppEngine API
+	DefaultAppengineAPIMaxResultsLimit = 10000
+	// DefaultFDORendezvousServerPort is the port of the FDO Rendezvous Server
+	DefaultFDORendezvousServerPort int32 = 8041
+	// DefaultAstarteHighPriority is the value of the high PriorityClass
+	DefaultAstarteHighPriority = 1000
+	// DefaultAstarteMidPriority is the value of the mid PriorityClass
+	DefaultAstarteMidPriority = 100
+	// DefaultAstarteLowPriority is the value of the low PriorityClass
+	DefaultAstarteLowPriority = 10
+)
+
+// SetDefaults fills in every optional field of the Astarte spec which has a default, so that the effective
+// configuration is explicit. Fields which are already set are never changed. Defaults which depend on the Astarte
+// version or on the Operator release, i.e. the CFSSL version and the probes, are left out: they are resolved at
+// reconcile time, so that upgrading either moves them.
+func (r *Astarte) SetDefaults() {
+	if r.Spec.API.SSL == nil {
+		r.Spec.API.SSL = pointy.Bool(true)
+	}
+
+	r.setRabbitMQDefaults()
+	r.setCassandraDefaults()
+	r.setVerneMQDefaults()
+	r.setCFSSLDefaults()
+	r.setComponentsDefaults()
+	r.setFeaturesDefaults()
+}
+
+func (r *Astarte) setRabbitMQDefaults() {
+	connection := r.Spec.RabbitMQ.Connection
+	if connection == nil {
+		return
+	}
+
+	// The connection string, when set, provides the port
+	if connection.Port == nil && connection.ConnectionStringSecret == nil {
+		connection.Port = pointy.Int32(DefaultRabbitMQPort)
+	}
+	setSSLConfigurationDefaults(&connection.SSLConfiguration)
+}
+
+func (r *Astarte) setCassandraDefaults() {
+	if connection := r.Spec.Cassandra.Connection; connection != nil {
+		if connection.EnableKeepalive == nil {
+			connection.EnableKeepalive = pointy.Bool(true)
+		}
+		setSSLConfigurationDefaults(&connection.SSLConfiguration)
+	}
+
+	keyspace := &r.Spec.Cassandra.AstarteSystemKeyspace
+	if keyspace.ReplicationStrategy == "" {
+		keyspace.ReplicationStrategy = "SimpleStrategy"
+	}
+	if keyspace.ReplicationStrategy == "SimpleStrategy" && keyspace.ReplicationFactor == 0 {
+		keyspace.ReplicationFactor = 1
+	}
+}
+
+func setSSLConfigurationDefaults(spec *GenericSSLConfigurationSpec) {
+	// SNI matters only when SSL is enabled, and a custom SNI takes precedence anyway
+	if spec.Enable && spec.CustomSNI == "" && spec.SNI == nil {
+		spec.SNI = pointy.Bool(true)
+	}
+}
+
+func (r *Astarte) setVerneMQDefaults() {
+	vernemq := &r.Spec.VerneMQ
+	setClusteredResourceDefaults(&vernemq.AstarteGenericClusteredResource, true)
+
+	if vernemq.Port == nil {
+		vernemq.Port = pointy.Int32(DefaultVerneMQPort)
+	}
+	if vernemq.MaxOfflineMessages == nil {
+		vernemq.MaxOfflineMessages = pointy.Int(DefaultVerneMQMaxOfflineMessages)
+	}
+	if vernemq.SSLListener == nil {
+		vernemq.SSLListener = pointy.Bool(false)
+	}
+	if vernemq.ProxyProtocol == nil {
+		vernemq.ProxyProtocol = pointy.Bool(false)
+	}
+}
+
+func (r *Astarte) setCFSSLDefaults() {
+	cfssl := &r.Spec.CFSSL
+	if cfssl.Deploy == nil {
+		cfssl.Deploy = pointy.Bool(true)
+	}
+	if cfssl.Backend == "" {
+		cfssl.Backend = DeviceCABackendCFSSL
+	}
+}
+
+func (r *Astarte) setComponentsDefaults() {
+	components := &r.Spec.Components
+
+	// Flow is the only component which is opt-in
+	setClusteredResourceDefaults(&components.Flow.AstarteGenericClusteredResource, false)
+	setClusteredResourceDefaults(&components.Housekeeping.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.RealmManagement.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.Pairing.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.DataUpdaterPlant.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.AppengineAPI.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.TriggerEngine.AstarteGenericClusteredResource, true)
+	setClusteredResourceDefaults(&components.Dashboard.AstarteGenericClusteredResource, true)
+
+	if components.DataUpdaterPlant.DataQueueCount == nil {
+		components.DataUpdaterPlant.DataQueueCount = pointy.Int(DefaultDataQueueCount)
+	}
+	if components.DataUpdaterPlant.PrefetchCount == nil {
+		components.DataUpdaterPlant.PrefetchCount = pointy.Int(DefaultDataUpdaterPlantPrefetchCount)
+	}
+	if components.AppengineAPI.MaxResultsLimit == nil {
+		components.AppengineAPI.MaxResultsLimit = pointy.Int(DefaultAppengineAPIMaxResultsLimit)
+	}
+}
+
+func setClusteredResourceDefaults(resource *AstarteGenericClusteredResource, deploy bool) {
+	if resource.Deploy == nil {
+		resource.Deploy = pointy.Bool(deploy)
+	}
+	if resource.Replicas == nil {
+		resource.Replicas = pointy.Int32(DefaultReplicas)
+	}
+	if resource.AntiAffinity == nil {
+		resource.AntiAffinity = pointy.Bool(true)
+	}
+}
+
+func (r *Astarte) setFeaturesDefaults() {
+	if fdo := r.Spec.Features.FDO; fdo != nil && fdo.Enable && fdo.RendezvousServer.Port == nil {
+		fdo.RendezvousServer.Port = pointy.Int32(DefaultFDORendezvousServerPort)
+	}
+
+	if priorities := r.Spec.Features.AstartePodPriorities; priorities.IsEnabled() {
+		if priorities.AstarteHighPriority == nil {
+			priorities.AstarteHighPriority = pointy.Int(DefaultAstarteHighPriority)
+		}
+		if priorities.AstarteMidPriority == nil {
+			priorities.AstarteMidPriority = pointy.Int(DefaultAstarteMidPriority)
+		}
+		if priorities.AstarteLowPriority == nil {
+			priorities.AstarteLowPriority = pointy.Int(DefaultAstarteLowPriority)
+		}
+	}
+}
+
+// DefaultAstarteComponentProbe returns the default readiness and liveness probe for an Astarte component
+func DefaultAstarteComponentProbe(component AstarteComponent) *v1.Probe {
+	// Housekeeping needs a much longer timeout, as we have an initialization which happens 3 times
+	threshold := int32(5)
+	if component == Housekeeping {
+		threshold = 15
+	}
+
+	return &v1.Probe{
+		ProbeHandler: v1.ProbeHandler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path: "/health",
+				Port: intstr.FromString("http"),
+			},
+		},
+		InitialDelaySeconds: 10,
+		TimeoutSeconds:      5,
+		PeriodSeconds:       30,
+		FailureThreshold:    threshold,
+	}
+}
+
+// DefaultCFSSLProbe returns the default readiness and liveness probe for CFSSL
+func DefaultCFSSLProbe() *v1.Probe {
+	// Start checking after 10 seconds, every 20 seconds, fail after the 3rd attempt
+	return &v1.Probe{
+		ProbeHandler:        v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/api/v1/cfssl/health", Port: intstr.FromString("http")}},
+		InitialDelaySeconds: 10,
+		TimeoutSeconds:      5,
+		PeriodSeconds:       20,
+		FailureThreshold:    3,
+	}
+}
+
+// DefaultVerneMQProbe returns the default readiness and liveness probe for VerneMQ
+func DefaultVerneMQProbe() *v1.Probe {
+	// Start checking after 1 minute, every 20 seconds, fail after the 3rd attempt
+	return &v1.Probe{
+		ProbeHandler:        v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/metrics", Port: intstr.FromInt(8888)}},
+		InitialDelaySeconds: 60,
+		TimeoutSeconds:      10,
+		PeriodSeconds:       20,
+		FailureThreshold:    3,
+	}
+}
@@ -0,0 +1,115 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+)
+
+var _ = Describe("Astarte defaults testing", func() {
+	var cr *Astarte
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+	})
+
+	It("should make the effective configuration explicit", func() {
+		cr.Spec.RabbitMQ.Connection.Port = nil
+		cr.Spec.VerneMQ.Port = nil
+		cr.Spec.CFSSL.Image = ""
+		cr.Spec.CFSSL.Version = ""
+
+		cr.Default()
+
+		Expect(cr.Spec.API.SSL).To(Equal(pointy.Bool(true)))
+		Expect(cr.Spec.RabbitMQ.Connection.Port).To(Equal(pointy.Int32(DefaultRabbitMQPort)))
+		Expect(cr.Spec.VerneMQ.Port).To(Equal(pointy.Int32(DefaultVerneMQPort)))
+		Expect(cr.Spec.VerneMQ.SSLListener).To(Equal(pointy.Bool(false)))
+		Expect(cr.Spec.CFSSL.Deploy).To(Equal(pointy.Bool(true)))
+		Expect(cr.Spec.CFSSL.Backend).To(Equal(DeviceCABackendCFSSL))
+		Expect(cr.Spec.Cassandra.AstarteSystemKeyspace.ReplicationStrategy).To(Equal("SimpleStrategy"))
+		Expect(cr.Spec.Cassandra.AstarteSystemKeyspace.ReplicationFactor).To(Equal(1))
+		Expect(cr.Spec.Components.Housekeeping.Deploy).To(Equal(pointy.Bool(true)))
+		Expect(cr.Spec.Components.Housekeeping.Replicas).To(Equal(pointy.Int32(DefaultReplicas)))
+		Expect(cr.Spec.Components.Flow.Deploy).To(Equal(pointy.Bool(false)))
+		Expect(cr.Spec.Components.DataUpdaterPlant.DataQueueCount).To(Equal(pointy.Int(DefaultDataQueueCount)))
+		Expect(cr.Spec.Components.AppengineAPI.MaxResultsLimit).To(Equal(pointy.Int(DefaultAppengineAPIMaxResultsLimit)))
+	})
+
+	It("should not default the RabbitMQ port when a connection string provides it", func() {
+		cr.Spec.RabbitMQ.Connection.Host = ""
+		cr.Spec.RabbitMQ.Connection.Port = nil
+		cr.Spec.RabbitMQ.Connection.CredentialsSecret = nil
+		cr.Spec.RabbitMQ.Connection.ConnectionStringSecret = &ConnectionStringSecret{Name: "rabbitmq", Key: "uri"}
+
+		cr.Default()
+
+		Expect(cr.Spec.RabbitMQ.Connection.Port).To(BeNil())
+	})
+
+	It("should leave the defaults depending on the Astarte version or the Operator release out", func() {
+		cr.Spec.CFSSL.Image = ""
+		cr.Spec.CFSSL.Version = ""
+		cr.Spec.CFSSL.LivenessProbe = nil
+		cr.Spec.VerneMQ.ReadinessProbe = nil
+		cr.Spec.Components.Housekeeping.ReadinessProbe = nil
+
+		cr.Default()
+
+		Expect(cr.Spec.CFSSL.Version).To(BeEmpty())
+		Expect(cr.Spec.CFSSL.LivenessProbe).To(BeNil())
+		Expect(cr.Spec.VerneMQ.ReadinessProbe).To(BeNil())
+		Expect(cr.Spec.Components.Housekeeping.ReadinessProbe).To(BeNil())
+	})
+
+	It("should never override fields which are already set", func() {
+		cr.Spec.API.SSL = pointy.Bool(false)
+		cr.Spec.Components.Flow.Deploy = pointy.Bool(true)
+		cr.Spec.Components.Pairing.Replicas = pointy.Int32(3)
+		cr.Spec.CFSSL.Image = "example.com/cfssl:custom"
+		cr.Spec.CFSSL.Version = ""
+
+		cr.Default()
+
+		Expect(cr.Spec.API.SSL).To(Equal(pointy.Bool(false)))
+		Expect(cr.Spec.Components.Flow.Deploy).To(Equal(pointy.Bool(true)))
+		Expect(cr.Spec.Components.Pairing.Replicas).To(Equal(pointy.Int32(3)))
+		Expect(cr.Spec.CFSSL.Version).To(BeEmpty())
+	})
+
+	It("should be idempotent", func() {
+		cr.Default()
+		defaulted := cr.DeepCopy()
+		cr.Default()
+		Expect(cr).To(Equal(defaulted))
+	})
+
+	It("should default the PriorityClass values only when pod priorities are enabled", func() {
+		cr.Spec.Features.AstartePodPriorities = &AstartePodPrioritiesSpec{}
+		cr.Default()
+		Expect(cr.Spec.Features.AstartePodPriorities.AstarteHighPriority).To(BeNil())
+
+		cr.Spec.Features.AstartePodPriorities.Enable = true
+		cr.Default()
+		Expect(cr.Spec.Features.AstartePodPriorities.AstarteHighPriority).To(Equal(pointy.Int(DefaultAstarteHighPriority)))
+		Expect(cr.Spec.Features.AstartePodPriorities.AstarteLowPriority).To(Equal(pointy.Int(DefaultAstarteLowPriority)))
+	})
+})
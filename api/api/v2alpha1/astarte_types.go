@@ -19,9 +19,12 @@ limitations under the License.
 package v2alpha1
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -71,6 +74,19 @@ type AstarteSpec struct {
 	// +kubebuilder:default:=false
 	// +kubebuilder:validation:Optional
 	ManualMaintenanceMode bool `json:"manualMaintenanceMode,omitempty"`
+	// DeletionPolicy defines what happens to the volumes and keys of this instance when it is deleted.
+	// Retained resources are adopted again by an Astarte with the same name, created in the same namespace.
+	// +kubebuilder:validation:Optional
+	DeletionPolicy *AstarteDeletionPolicySpec `json:"deletionPolicy,omitempty"`
+	// When true, the validating webhook rejects the deletion of this instance, and of the
+	// AstarteDefaultIngresses referring to it, until the flag is cleared. The same can be achieved
+	// by setting the api.astarte-platform.org/deletion-protection annotation to "true".
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+	// When enabled, the Operator checks that RabbitMQ and Cassandra are reachable before deploying the
+	// Astarte components.
+	// +kubebuilder:validation:Optional
+	Preflight *AstartePreflightSpec `json:"preflight,omitempty"`
 }
 
 // AstarteStatus defines the observed state of Astarte
@@ -81,6 +97,111 @@ type AstarteStatus struct {
 	Health              AstarteClusterHealth `json:"health"`
 	BaseAPIURL          string               `json:"baseAPIURL"`
 	BrokerURL           string               `json:"brokerURL"`
+	// SHA-256 fingerprint of the devices CA certificate.
+	// +optional
+	DeviceCAFingerprint string `json:"deviceCAFingerprint,omitempty"`
+	// Expiry of the devices CA certificate.
+	// +optional
+	DeviceCAExpiry *metav1.Time `json:"deviceCAExpiry,omitempty"`
+	// The state of the Data Updater Plant queue autoscaler, if enabled.
+	// +optional
+	DataUpdaterPlant *AstarteDataUpdaterPlantStatus `json:"dataUpdaterPlant,omitempty"`
+	// The Data Updater Plant shards, and the data queues each of them is consuming from.
+	// +optional
+	DataUpdaterPlantShards []AstarteDataUpdaterPlantShardStatus `json:"dataUpdaterPlantShards,omitempty"`
+	// The progress of the coordinated VerneMQ update, if enabled.
+	// +optional
+	VerneMQUpdate *AstarteVerneMQUpdateStatus `json:"verneMQUpdate,omitempty"`
+	// The Cassandra topology, as last observed by the topology check, if enabled.
+	// +optional
+	CassandraTopology *AstarteCassandraTopologyStatus `json:"cassandraTopology,omitempty"`
+	// The latest observations of the Astarte instance, e.g. the outcome of the connectivity preflight.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// AstarteCassandraTopologyStatus reports the datacenters of the Cassandra cluster
+type AstarteCassandraTopologyStatus struct {
+	// The total number of nodes.
+	Nodes int32 `json:"nodes"`
+	// The datacenters of the cluster, sorted by name.
+	// +optional
+	DataCenters []AstarteCassandraDataCenterStatus `json:"dataCenters,omitempty"`
+}
+
+// AstarteCassandraDataCenterStatus reports a datacenter of the Cassandra cluster
+type AstarteCassandraDataCenterStatus struct {
+	Name  string `json:"name"`
+	Nodes int32  `json:"nodes"`
+}
+
+// AstarteDataUpdaterPlantStatus reports what the Data Updater Plant queue autoscaler observed and decided
+type AstarteDataUpdaterPlantStatus struct {
+	// The number of shards decided by the autoscaler.
+	Shards int32 `json:"shards"`
+	// The total number of messages in the data queues, ready or unacknowledged.
+	Backlog int64 `json:"backlog"`
+	// The total number of consumers of the data queues.
+	Consumers int32 `json:"consumers"`
+	// The number of data queues nobody is consuming from.
+	QueuesWithoutConsumers int32 `json:"queuesWithoutConsumers"`
+	// When the data queues were last observed.
+	// +optional
+	LastObservationTime *metav1.Time `json:"lastObservationTime,omitempty"`
+	// When the number of shards last changed.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// AstarteVerneMQUpdateStatus reports the progress of a coordinated VerneMQ update
+type AstarteVerneMQUpdateStatus struct {
+	// The phase of the update.
+	Phase AstarteVerneMQUpdatePhase `json:"phase"`
+	// The StatefulSet partition: nodes with an ordinal greater or equal than this are updated.
+	Partition int32 `json:"partition"`
+	// The number of VerneMQ nodes.
+	Replicas int32 `json:"replicas"`
+	// The number of VerneMQ nodes running the latest revision.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// The number of sessions currently open across all nodes, as last observed.
+	// +optional
+	Sessions *int64 `json:"sessions,omitempty"`
+	// The number of sessions open right before the last node was replaced.
+	// +optional
+	SessionsBeforeLastStep *int64 `json:"sessionsBeforeLastStep,omitempty"`
+	// When the last node was replaced.
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
+}
+
+// AstarteVerneMQUpdatePhase is the phase of a coordinated VerneMQ update
+type AstarteVerneMQUpdatePhase string
+
+const (
+	// VerneMQUpdatePhaseIdle means all nodes run the latest revision
+	VerneMQUpdatePhaseIdle AstarteVerneMQUpdatePhase = "Idle"
+	// VerneMQUpdatePhaseWaitingForNode means a node is being drained, replaced or is starting up
+	VerneMQUpdatePhaseWaitingForNode AstarteVerneMQUpdatePhase = "WaitingForNode"
+	// VerneMQUpdatePhaseWaitingForSessions means the Operator waits for sessions to be redistributed
+	VerneMQUpdatePhaseWaitingForSessions AstarteVerneMQUpdatePhase = "WaitingForSessions"
+)
+
+// AstarteDataUpdaterPlantShardStatus reports the data queues a Data Updater Plant shard is consuming from
+type AstarteDataUpdaterPlantShardStatus struct {
+	// The name of the shard Deployment.
+	Name string `json:"name"`
+	// The first data queue consumed by the shard. Unset when the shard is drained.
+	// +optional
+	QueueRangeStart *int32 `json:"queueRangeStart,omitempty"`
+	// The last data queue consumed by the shard, inclusive. Unset when the shard is drained.
+	// +optional
+	QueueRangeEnd *int32 `json:"queueRangeEnd,omitempty"`
+	// Whether the shard is rolled out and consuming.
+	Ready bool `json:"ready"`
 }
 
 // +kubebuilder:object:root=true
@@ -137,6 +258,30 @@ const (
 	AstarteResourceEventUpgrade AstarteResourceEvent = "Upgrade"
 	// AstarteResourceEventUpgradeError represents an error happening during a Cluster Upgrade
 	AstarteResourceEventUpgradeError AstarteResourceEvent = "ErrUpgrade"
+	// AstarteResourceEventPreflight means a backing service passed the connectivity preflight again
+	AstarteResourceEventPreflight AstarteResourceEvent = "Preflight"
+	// AstarteResourceEventPreflightFailed means a backing service failed the connectivity preflight
+	AstarteResourceEventPreflightFailed AstarteResourceEvent = "ErrPreflight"
+	// AstarteResourceEventRabbitMQDrift means the RabbitMQ provisioning found and corrected a drift
+	AstarteResourceEventRabbitMQDrift AstarteResourceEvent = "RabbitMQDrift"
+	// AstarteResourceEventRabbitMQProvisioningFailed means RabbitMQ could not be provisioned
+	AstarteResourceEventRabbitMQProvisioningFailed AstarteResourceEvent = "ErrRabbitMQProvisioning"
+	// AstarteResourceEventCassandraReplication means the Cassandra topology satisfies the keyspace replication again
+	AstarteResourceEventCassandraReplication AstarteResourceEvent = "CassandraReplication"
+	// AstarteResourceEventCassandraReplicationUnsatisfiable means the Cassandra topology cannot satisfy the keyspace replication
+	AstarteResourceEventCassandraReplicationUnsatisfiable AstarteResourceEvent = "ErrCassandraReplication"
+)
+
+const (
+	// AstarteConditionRabbitMQReachable reports the outcome of the RabbitMQ connectivity preflight
+	AstarteConditionRabbitMQReachable = "RabbitMQReachable"
+	// AstarteConditionCassandraReachable reports the outcome of the Cassandra connectivity preflight
+	AstarteConditionCassandraReachable = "CassandraReachable"
+	// AstarteConditionRabbitMQProvisioned reports the outcome of the RabbitMQ provisioning
+	AstarteConditionRabbitMQProvisioned = "RabbitMQProvisioned"
+	// AstarteConditionCassandraReplicationSatisfiable reports whether the Cassandra topology can satisfy the
+	// replication of the Astarte system keyspace
+	AstarteConditionCassandraReplicationSatisfiable = "CassandraReplicationSatisfiable"
 )
 
 func (e AstarteResourceEvent) String() string {
@@ -280,10 +425,33 @@ type AstarteGenericClusteredResource struct {
 }
 
 type AstarteGenericClusteredResourceAutoscalerSpec struct {
-	// Name of the HorizontalPodAutoscaler for this deployment/statefulset.
-	// This will take precedence over the "Replicas" field of the parent Astarte component.
+	// Name of an externally managed HorizontalPodAutoscaler for this deployment/statefulset.
+	// While the HorizontalPodAutoscaler exists, the Operator won't touch the replica count.
+	// Deprecated: describe the autoscaler through maxReplicas and friends to have the Operator manage it instead.
 	// +kubebuilder:validation:Optional
 	Horizontal string `json:"horizontal,omitempty"`
+	// The lower limit for the number of replicas of the Operator managed HorizontalPodAutoscaler.
+	// Defaults to the "Replicas" field of the parent Astarte component, or 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// The upper limit for the number of replicas of the Operator managed HorizontalPodAutoscaler.
+	// When set, the Operator creates and manages a HorizontalPodAutoscaler for this deployment/statefulset.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// The target average CPU utilization, as a percentage of the requested CPU.
+	// If neither a CPU nor a memory target are set, this defaults to 80.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// The target average memory utilization, as a percentage of the requested memory.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// The scaling behavior of the Operator managed HorizontalPodAutoscaler, in both directions.
+	// +kubebuilder:validation:Optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
 	// TODO: Vertical string `json:"vertical,omitempty"`
 }
 
@@ -296,20 +464,130 @@ type AstartePersistentStorageSpec struct {
 	VolumeDefinition *v1.Volume `json:"volumeDefinition,omitempty"`
 }
 
+// AnnotationDeletionProtection protects an Astarte instance from deletion, as spec.deletionProtection does
+const AnnotationDeletionProtection = "api.astarte-platform.org/deletion-protection"
+
+// IsDeletionProtected returns whether the deletion of the Astarte instance must be rejected
+func (r *Astarte) IsDeletionProtected() bool {
+	return r.Spec.DeletionProtection || r.GetAnnotations()[AnnotationDeletionProtection] == "true"
+}
+
+// GetReferencedSecretNames returns the names of the user-provided Secrets the Astarte instance references.
+// Secrets generated by the Operator are not included, as they are owned by the instance.
+func (r *Astarte) GetReferencedSecretNames() []string {
+	names := []string{}
+	if r.Spec.RabbitMQ.Connection != nil {
+		names = append(names, r.Spec.RabbitMQ.Connection.GetReferencedSecretNames()...)
+	}
+	if p := r.Spec.RabbitMQ.Provisioning; p != nil && p.AdminCredentialsSecret != nil {
+		names = append(names, p.AdminCredentialsSecret.Name)
+	}
+	if r.Spec.Cassandra.Connection != nil {
+		names = append(names, r.Spec.Cassandra.Connection.GetReferencedSecretNames()...)
+	}
+	names = append(names, r.Spec.VerneMQ.CaSecret, r.Spec.VerneMQ.SSLListenerCertSecretName, r.Spec.CFSSL.CASecret.Name)
+
+	names = slices.DeleteFunc(names, func(name string) bool { return name == "" })
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// AstartePreflightSpec configures the connectivity checks of RabbitMQ and Cassandra
+type AstartePreflightSpec struct {
+	// When true, the Operator connects to RabbitMQ and to every Cassandra node, reporting whether they
+	// are reachable in the conditions of the Astarte status. Default: false.
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// When true, the checks log in with the configured credentials, besides connecting. Default: false.
+	// +kubebuilder:validation:Optional
+	Authenticate bool `json:"authenticate,omitempty"`
+	// When true, the Astarte components are not deployed until the checks pass. Default: false.
+	// +kubebuilder:validation:Optional
+	HoldDeployment bool `json:"holdDeployment,omitempty"`
+	// The timeout of each check, in seconds. Default: 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// AstarteDeletionPolicySpec defines what happens to the resources holding the state of Astarte on deletion
+type AstarteDeletionPolicySpec struct {
+	// The policy for all the resources, unless overridden by the kind-specific ones. Snapshot retains
+	// the Secrets, as they can't be snapshotted. Default: Delete.
+	// +kubebuilder:validation:Enum:=Delete;Retain;Snapshot
+	// +kubebuilder:validation:Optional
+	Policy AstarteDeletionPolicyType `json:"policy,omitempty"`
+	// The policy for the PersistentVolumeClaims, e.g. the VerneMQ data.
+	// +kubebuilder:validation:Enum:=Delete;Retain;Snapshot
+	// +kubebuilder:validation:Optional
+	PersistentVolumeClaims AstarteDeletionPolicyType `json:"persistentVolumeClaims,omitempty"`
+	// The policy for the Secrets holding generated keys: the devices CA, the Housekeeping key pair
+	// and the secret key base.
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:validation:Optional
+	Secrets AstarteDeletionPolicyType `json:"secrets,omitempty"`
+	// The VolumeSnapshotClass used when snapshotting PersistentVolumeClaims. When not set, the
+	// default VolumeSnapshotClass of the cluster is used.
+	// +kubebuilder:validation:Optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// AstarteDeletionPolicyType identifies what happens to a resource when Astarte is deleted
+type AstarteDeletionPolicyType string
+
+const (
+	// DeletionPolicyDelete deletes the resource together with Astarte
+	DeletionPolicyDelete AstarteDeletionPolicyType = "Delete"
+	// DeletionPolicyRetain keeps the resource around, detached from Astarte
+	DeletionPolicyRetain AstarteDeletionPolicyType = "Retain"
+	// DeletionPolicySnapshot takes a VolumeSnapshot of the resource before deleting it
+	DeletionPolicySnapshot AstarteDeletionPolicyType = "Snapshot"
+)
+
+// GetPersistentVolumeClaimsPolicy returns the deletion policy for PersistentVolumeClaims
+func (p *AstarteDeletionPolicySpec) GetPersistentVolumeClaimsPolicy() AstarteDeletionPolicyType {
+	if p == nil {
+		return DeletionPolicyDelete
+	}
+	if p.PersistentVolumeClaims != "" {
+		return p.PersistentVolumeClaims
+	}
+	if p.Policy != "" {
+		return p.Policy
+	}
+	return DeletionPolicyDelete
+}
+
+// GetSecretsPolicy returns the deletion policy for Secrets
+func (p *AstarteDeletionPolicySpec) GetSecretsPolicy() AstarteDeletionPolicyType {
+	if p == nil {
+		return DeletionPolicyDelete
+	}
+	if p.Secrets != "" {
+		return p.Secrets
+	}
+	if p.Policy == DeletionPolicyRetain || p.Policy == DeletionPolicySnapshot {
+		return DeletionPolicyRetain
+	}
+	return DeletionPolicyDelete
+}
+
 type AstarteAPISpec struct {
 	// +kubebuilder:validation:Optional
 	SSL  *bool  `json:"ssl,omitempty"`
 	Host string `json:"host"`
 }
 
+// HostAndPort is the address of a service. Which of the fields are required depends on where it is used, and is
+// checked by the validating webhook: e.g. a connection string can provide the RabbitMQ address in their place.
 type HostAndPort struct {
 	// +kubebuilder:validation:MinLength=1
-	// +kubebuilder:validation:Required
-	Host string `json:"host"`
+	// +kubebuilder:validation:Optional
+	Host string `json:"host,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=65535
-	// +kubebuilder:validation:Required
-	Port *int32 `json:"port"`
+	// +kubebuilder:validation:Optional
+	Port *int32 `json:"port,omitempty"`
 }
 
 type LoginCredentialsSecret struct {
@@ -335,15 +613,36 @@ type GenericConnectionSpec struct {
 	// Either this field or `connectionStringSecret` must be set.
 	// +kubebuilder:validation:Optional
 	CredentialsSecret *LoginCredentialsSecret `json:"credentialsSecret,omitempty"`
-	// The secret containing a connection string to the service.
+	// The secret containing a connection string to the service, which also provides its address.
 	// Either this field or `credentialsSecret` must be set.
-	// TODO: currently, Astarte services do not allow the connection string to be
-	// put as-is in the env. Therefore, setting this field is a no-op.
-	// Not using `credentialsSecret` WILL break your Astarte instance.
+	// RabbitMQ expects an AMQP URI, i.e. amqp[s]://[username[:password]@]host[:port][/virtual-host].
+	// Cassandra expects a comma-separated list of contact points, optionally preceded by the credentials,
+	// i.e. [username[:password]@]host[:port][,host[:port]...].
 	// +kubebuilder:validation:Optional
 	ConnectionStringSecret *ConnectionStringSecret `json:"connectionStringSecret,omitempty"`
 }
 
+// GetReferencedSecretNames returns the names of the Secrets referenced by the connection, possibly empty
+func (s *GenericConnectionSpec) GetReferencedSecretNames() []string {
+	names := []string{s.SSLConfiguration.CustomCASecret.Name}
+	if s.CredentialsSecret != nil {
+		names = append(names, s.CredentialsSecret.Name)
+	}
+	if s.ConnectionStringSecret != nil {
+		names = append(names, s.ConnectionStringSecret.Name)
+	}
+	if s.SSLConfiguration.ClientCertSecret.Name != "" {
+		names = append(names, s.SSLConfiguration.ClientCertSecret.Name)
+	}
+	return names
+}
+
+// UsesClientCertificateOnly returns whether the connection authenticates through its client certificate alone,
+// as it has neither credentials nor a connection string
+func (s *GenericConnectionSpec) UsesClientCertificateOnly() bool {
+	return s.CredentialsSecret == nil && s.ConnectionStringSecret == nil && s.SSLConfiguration.ClientCertSecret.Name != ""
+}
+
 type GenericSSLConfigurationSpec struct {
 	// +kubebuilder:validation:Optional
 	Enable bool `json:"enable,omitempty"`
@@ -353,12 +652,20 @@ type GenericSSLConfigurationSpec struct {
 	SNI *bool `json:"sni,omitempty"`
 	// +kubebuilder:validation:Optional
 	CustomSNI string `json:"customSNI,omitempty"`
+	// The TLS Secret holding the client certificate and key (tls.crt and tls.key) to authenticate
+	// through mutual TLS. Requires `enable` to be true. When set, credentials may be omitted to rely on
+	// the certificate only.
+	// +kubebuilder:validation:Optional
+	ClientCertSecret v1.LocalObjectReference `json:"clientCertSecret,omitempty"`
 }
 
 type AstarteRabbitMQConnectionSpec struct {
+	// The RabbitMQ host and AMQP port. The host is required and the port defaults to 5672, unless
+	// `connectionStringSecret` is set.
 	HostAndPort `json:",inline"`
 	// +kubebuilder:validation:Optional
 	GenericConnectionSpec `json:",inline"`
+	// The RabbitMQ virtual host. Defaults to "/", unless `connectionStringSecret` is set.
 	// +kubebuilder:validation:Optional
 	VirtualHost string `json:"virtualHost,omitempty"`
 }
@@ -374,11 +681,56 @@ type AstarteRabbitMQSpec struct {
 	// in custom RabbitMQ installations.
 	// +kubebuilder:validation:Optional
 	EventsExchangeName string `json:"eventsExchangeName,omitempty"`
+	// The URL of the RabbitMQ management API, used by the Operator to inspect RabbitMQ.
+	// Defaults to port 15672 of the RabbitMQ host, over HTTPS when SSL is enabled.
+	// +kubebuilder:validation:Optional
+	ManagementURL string `json:"managementURL,omitempty"`
+	// When set, the Operator provisions the virtual host, the Astarte user and its permissions, and
+	// optionally the data queues policy through the management API, correcting any drift.
+	// +kubebuilder:validation:Optional
+	Provisioning *AstarteRabbitMQProvisioningSpec `json:"provisioning,omitempty"`
+}
+
+// AstarteRabbitMQProvisioningSpec configures how the Operator provisions RabbitMQ for Astarte
+type AstarteRabbitMQProvisioningSpec struct {
+	// The Secret holding the credentials of a RabbitMQ administrator, used for provisioning only.
+	// +kubebuilder:validation:Required
+	AdminCredentialsSecret *LoginCredentialsSecret `json:"adminCredentialsSecret"`
+	// The URL of the RabbitMQ management API used for provisioning. Defaults to `managementURL`.
+	// +kubebuilder:validation:Optional
+	ManagementURL string `json:"managementURL,omitempty"`
+	// The policy applied to the data queues, and to the VerneMQ mirror queue if any. When not set,
+	// no policy is provisioned.
+	// +kubebuilder:validation:Optional
+	DataQueuesPolicy *AstarteRabbitMQQueuePolicySpec `json:"dataQueuesPolicy,omitempty"`
+}
+
+// AstarteRabbitMQQueuePolicySpec describes a RabbitMQ queue mirroring policy
+type AstarteRabbitMQQueuePolicySpec struct {
+	// The name of the policy. Default: astarte-data-queues.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// The priority of the policy. Default: 0.
+	// +kubebuilder:validation:Optional
+	Priority int32 `json:"priority,omitempty"`
+	// How queues are mirrored across the RabbitMQ cluster nodes: to all of them, or to exactly `haParams` nodes.
+	// +kubebuilder:validation:Enum=all;exactly
+	// +kubebuilder:validation:Required
+	HAMode string `json:"haMode"`
+	// The number of nodes queues are mirrored to. Required when `haMode` is exactly.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	HAParams *int32 `json:"haParams,omitempty"`
+	// Whether new mirrors are synchronized automatically or manually. Defaults to RabbitMQ's own default.
+	// +kubebuilder:validation:Enum=automatic;manual
+	// +kubebuilder:validation:Optional
+	HASyncMode string `json:"haSyncMode,omitempty"`
 }
 
 type AstarteCassandraConnectionSpec struct {
 	GenericConnectionSpec `json:",inline"`
-	Nodes                 []HostAndPort `json:"nodes,omitempty"`
+	// The Cassandra nodes. Required, unless `connectionStringSecret` is set.
+	Nodes []HostAndPort `json:"nodes,omitempty"`
 	// +kubebuilder:validation:Optional
 	PoolSize *int `json:"poolSize,omitempty"`
 	// Enable or disable the keepalive option for the xandra connection.
@@ -392,6 +744,22 @@ type AstarteCassandraSpec struct {
 	Connection *AstarteCassandraConnectionSpec `json:"connection,omitempty"`
 	// +kubebuilder:validation:Optional
 	AstarteSystemKeyspace AstarteSystemKeyspaceSpec `json:"astarteSystemKeyspace,omitempty"`
+	// When enabled, the Operator reads the Cassandra topology and checks that the replication of
+	// the Astarte system keyspace can be satisfied.
+	// +kubebuilder:validation:Optional
+	TopologyCheck *AstarteCassandraTopologyCheckSpec `json:"topologyCheck,omitempty"`
+}
+
+// AstarteCassandraTopologyCheckSpec configures the Cassandra topology check
+type AstarteCassandraTopologyCheckSpec struct {
+	// When true, the Operator connects to Cassandra to read its datacenters and nodes, reporting them
+	// in the status and flagging replication settings which cannot be satisfied. Default: false.
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The timeout of the connection to each node, in seconds. Default: 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 type AstarteVerneMQSpec struct {
@@ -433,6 +801,105 @@ type AstarteVerneMQSpec struct {
 	// The field will be used only if SSLListener is set to true.
 	// +kubebuilder:validation:Optional
 	SSLListenerCertSecretName string `json:"sslListenerCertSecretName,omitempty"`
+	// When true, VerneMQ exposes a tcp listener on port 1886 which expects the PROXY protocol header, so that
+	// it sees the real IP of devices connecting through a load balancer, and the broker load balancer targets
+	// it instead of the SSL listener. The load balancer must then terminate TLS and forward the client
+	// certificate in the PROXY protocol v2 header, as VerneMQ takes the device identity from its common name.
+	// Default: false.
+	// +optional
+	ProxyProtocol *bool `json:"proxyProtocol,omitempty"`
+	// Controls how VerneMQ pods are replaced when the StatefulSet changes.
+	// +kubebuilder:validation:Optional
+	UpdateStrategy *AstarteVerneMQUpdateStrategySpec `json:"updateStrategy,omitempty"`
+	// Additional listeners, besides the ones the Operator always configures.
+	// TLS listeners (ssl, wss) use the certificate referenced by SSLListenerCertSecretName.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:Optional
+	Listeners []AstarteVerneMQListenerSpec `json:"listeners,omitempty"`
+	// Arbitrary vernemq.conf settings, such as "max_inflight_messages" or "max_message_size".
+	// Settings managed by the Operator, listeners included, cannot be overridden here.
+	// +kubebuilder:validation:Optional
+	ConfigOverrides map[string]string `json:"configOverrides,omitempty"`
+}
+
+// AstarteVerneMQListenerSpec defines an additional VerneMQ listener
+type AstarteVerneMQListenerSpec struct {
+	// The name of the listener. It is used as the name of the container and Service ports, too.
+	// +kubebuilder:validation:Pattern:=`^[a-z]([a-z0-9-]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength:=15
+	Name string `json:"name"`
+	// The kind of listener: plain MQTT ("tcp"), MQTT over TLS ("ssl"), MQTT over WebSocket ("ws")
+	// or MQTT over secure WebSocket ("wss").
+	// +kubebuilder:validation:Enum:=tcp;ssl;ws;wss
+	Type AstarteVerneMQListenerType `json:"type"`
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	Port int32 `json:"port"`
+	// Expect the PROXY protocol header on incoming connections. Only supported by tcp and ws listeners.
+	// +kubebuilder:validation:Optional
+	ProxyProtocol bool `json:"proxyProtocol,omitempty"`
+}
+
+// AstarteVerneMQListenerType identifies the kind of a VerneMQ listener
+type AstarteVerneMQListenerType string
+
+const (
+	// VerneMQListenerTCP is a plain MQTT listener
+	VerneMQListenerTCP AstarteVerneMQListenerType = "tcp"
+	// VerneMQListenerSSL is an MQTT over TLS listener
+	VerneMQListenerSSL AstarteVerneMQListenerType = "ssl"
+	// VerneMQListenerWS is an MQTT over WebSocket listener
+	VerneMQListenerWS AstarteVerneMQListenerType = "ws"
+	// VerneMQListenerWSS is an MQTT over secure WebSocket listener
+	VerneMQListenerWSS AstarteVerneMQListenerType = "wss"
+)
+
+// IsTLS returns whether the listener terminates TLS
+func (t AstarteVerneMQListenerType) IsTLS() bool {
+	return t == VerneMQListenerSSL || t == VerneMQListenerWSS
+}
+
+// AstarteVerneMQUpdateStrategySpec defines how VerneMQ nodes are updated
+type AstarteVerneMQUpdateStrategySpec struct {
+	// "RollingUpdate" (the default) lets Kubernetes replace VerneMQ pods on its own.
+	// "Coordinated" makes the Operator replace one node at a time, moving on only once sessions are
+	// redistributed. Nodes removed by a scale down leave the cluster first, migrating their queues.
+	// +kubebuilder:validation:Enum:=RollingUpdate;Coordinated;""
+	// +kubebuilder:validation:Optional
+	Type AstarteVerneMQUpdateStrategyType `json:"type,omitempty"`
+	// How long a node removed by a scale down has to leave the cluster and migrate its queues before
+	// being stopped.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	DrainTimeoutSeconds *int32 `json:"drainTimeoutSeconds,omitempty"`
+	// The percentage of the sessions open before a node was replaced which must be open again
+	// before the next node is replaced. Defaults to 90.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=100
+	// +kubebuilder:validation:Optional
+	SessionRecoveryPercentage *int32 `json:"sessionRecoveryPercentage,omitempty"`
+	// How long to wait for sessions to be redistributed before replacing the next node anyway.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	SessionRedistributionTimeoutSeconds *int32 `json:"sessionRedistributionTimeoutSeconds,omitempty"`
+}
+
+// AstarteVerneMQUpdateStrategyType identifies how VerneMQ nodes are updated
+type AstarteVerneMQUpdateStrategyType string
+
+const (
+	// VerneMQRollingUpdateStrategy relies on the standard StatefulSet rolling update
+	VerneMQRollingUpdateStrategy AstarteVerneMQUpdateStrategyType = "RollingUpdate"
+	// VerneMQCoordinatedUpdateStrategy replaces VerneMQ nodes one at a time, draining them first
+	VerneMQCoordinatedUpdateStrategy AstarteVerneMQUpdateStrategyType = "Coordinated"
+)
+
+// IsCoordinated returns whether VerneMQ nodes are updated one at a time by the Operator
+func (s *AstarteVerneMQUpdateStrategySpec) IsCoordinated() bool {
+	return s != nil && s.Type == VerneMQCoordinatedUpdateStrategy
 }
 
 type AstarteDataUpdaterPlantSpec struct {
@@ -446,6 +913,47 @@ type AstarteDataUpdaterPlantSpec struct {
 	// Defaults to 300
 	// +kubebuilder:validation:Optional
 	PrefetchCount *int `json:"prefetchCount,omitempty"`
+	// Scales the number of Data Updater Plant shards according to the backlog of the data queues.
+	// Requires the autoscaling feature to be enabled. When active, it takes precedence over the "Replicas" field.
+	// +kubebuilder:validation:Optional
+	QueueAutoscaler *AstarteDataUpdaterPlantQueueAutoscalerSpec `json:"queueAutoscaler,omitempty"`
+}
+
+// AstarteDataUpdaterPlantQueueAutoscalerSpec configures the queue-depth driven autoscaling of Data Updater Plant.
+// The backlog is read from the RabbitMQ management API.
+type AstarteDataUpdaterPlantQueueAutoscalerSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The lower limit for the number of shards. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MinShards *int32 `json:"minShards,omitempty"`
+	// The upper limit for the number of shards. Cannot exceed the data queue count.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	MaxShards int32 `json:"maxShards,omitempty"`
+	// The number of messages waiting in the data queues each shard is expected to handle. Defaults to 1000.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	TargetBacklogPerShard *int64 `json:"targetBacklogPerShard,omitempty"`
+	// Scale down hysteresis: a shard is removed only when the backlog falls below this percentage of what the
+	// remaining shards are expected to handle. Defaults to 50.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +kubebuilder:validation:Optional
+	ScaleDownThresholdPercentage *int32 `json:"scaleDownThresholdPercentage,omitempty"`
+	// Minimum time between a scaling event and a subsequent scale up. Defaults to 60.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	ScaleUpCooldownSeconds *int32 `json:"scaleUpCooldownSeconds,omitempty"`
+	// Minimum time between a scaling event and a subsequent scale down. Defaults to 300.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Optional
+	ScaleDownCooldownSeconds *int32 `json:"scaleDownCooldownSeconds,omitempty"`
+	// How often the data queues are polled. Defaults to 30.
+	// +kubebuilder:validation:Minimum:=5
+	// +kubebuilder:validation:Optional
+	PollIntervalSeconds *int32 `json:"pollIntervalSeconds,omitempty"`
 }
 
 type AstarteTriggerEngineSpec struct {
@@ -578,6 +1086,13 @@ type AstarteCFSSLCARootConfigSpec struct {
 type AstarteCFSSLSpec struct {
 	// +kubebuilder:validation:Optional
 	Deploy *bool `json:"deploy,omitempty"`
+	// The backend in charge of signing device certificates.
+	// "cfssl" (the default) deploys and manages a CFSSL instance. "operator" makes the Operator itself
+	// sign device certificates, exposing the same CFSSL-compatible API without running CFSSL.
+	// When a custom URL is set, the backend is ignored and the given URL is used as is.
+	// +kubebuilder:validation:Enum:=cfssl;operator;""
+	// +kubebuilder:validation:Optional
+	Backend AstarteDeviceCABackend `json:"backend,omitempty"`
 	// +kubebuilder:validation:Optional
 	URL string `json:"url,omitempty"`
 	// +kubebuilder:validation:Optional
@@ -595,12 +1110,17 @@ type AstarteCFSSLSpec struct {
 	Version string `json:"version,omitempty"`
 	// +kubebuilder:validation:Optional
 	Image string `json:"image,omitempty"`
+	// Deprecated: CFSSL is deployed without persistent storage, hence this field has no effect. It is still
+	// accepted, so that existing resources keep validating.
 	// +kubebuilder:validation:Optional
 	Storage *AstartePersistentStorageSpec `json:"storage,omitempty"`
 	// +kubebuilder:validation:Optional
 	CSRRootCa *AstarteCFSSLCSRRootCASpec `json:"csrRootCa,omitempty"`
 	// +kubebuilder:validation:Optional
 	CARootConfig *AstarteCFSSLCARootConfigSpec `json:"caRootConfig,omitempty"`
+	// Publish the devices CA certificate chain and the broker URL for external consumers.
+	// +kubebuilder:validation:Optional
+	PublishCABundle *AstarteDeviceCABundleSpec `json:"publishCABundle,omitempty"`
 	// Additional labels for this Component's pod(s).
 	// Label keys can't be of the form "app", "component", "astarte-*", "flow-*"
 	// +kubebuilder:validation:Optional
@@ -627,6 +1147,30 @@ type AstarteCFSSLSpec struct {
 	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
 }
 
+// AstarteDeviceCABackend identifies the backend in charge of signing device certificates
+type AstarteDeviceCABackend string
+
+const (
+	// DeviceCABackendCFSSL signs device certificates through an Operator-managed CFSSL Deployment
+	DeviceCABackendCFSSL AstarteDeviceCABackend = "cfssl"
+	// DeviceCABackendOperator signs device certificates through the CFSSL-compatible API served by the Operator
+	DeviceCABackendOperator AstarteDeviceCABackend = "operator"
+)
+
+// AstarteDeviceCABundleSpec defines how the devices CA bundle is published. The bundle is a ConfigMap
+// holding the devices CA certificate chain (never the key) and the VerneMQ broker URL.
+type AstarteDeviceCABundleSpec struct {
+	// +kubebuilder:validation:Optional
+	Enable bool `json:"enable,omitempty"`
+	// The name of the ConfigMap holding the bundle. Defaults to <astarte-name>-devices-ca-bundle.
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// When set, the bundle is mirrored into all namespaces matching the selector, in addition
+	// to the namespace of the Astarte instance.
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
 // This interface is implemented by all Astarte components which have a podLabels field.
 // +k8s:deepcopy-gen=false
 type PodLabelsGetter interface {
@@ -679,24 +1223,42 @@ type AstarteSystemKeyspaceSpec struct {
 type AstartePodPrioritiesSpec struct {
 	// +kubebuilder:validation:Optional
 	Enable bool `json:"enable,omitempty"`
-	// The value of the highest PriorityClass for Astarte pods.
-	// Once the value is set, updating it will not have effect.
+	// The value of the highest PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-high-priority-non-preemptive-2000: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=1000
 	// +kubebuilder:validation:Minimum:=0
 	AstarteHighPriority *int `json:"astarteHighPriority,omitempty"`
-	// The value of the medium PriorityClass for Astarte pods.
-	// Once the value is set, updating it will not have effect.
+	// The value of the medium PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-mid-priority-non-preemptive-200: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=100
 	// +kubebuilder:validation:Minimum:=0
 	AstarteMidPriority *int `json:"astarteMidPriority,omitempty"`
-	// The value of the least PriorityClass for Astarte pods.
-	// Once the value is set, updating it will not have effect.
+	// The value of the least PriorityClass for Astarte pods. Values other than the default one are
+	// appended to the name of the PriorityClass, e.g. astarte-low-priority-non-preemptive-20: changing
+	// it moves pods to another PriorityClass, and they get the new value once they are restarted.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=10
 	// +kubebuilder:validation:Minimum:=0
 	AstarteLowPriority *int `json:"astarteLowPriority,omitempty"`
+	// The name of the highest PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteHighPriorityClassName string `json:"astarteHighPriorityClassName,omitempty"`
+	// The name of the medium PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteMidPriorityClassName string `json:"astarteMidPriorityClassName,omitempty"`
+	// The name of the least PriorityClass for Astarte pods, in place of the one shared by all instances.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=242
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	AstarteLowPriorityClassName string `json:"astarteLowPriorityClassName,omitempty"`
 }
 
 // AstarteFDOSpec configures FDO support in Astarte.
@@ -712,6 +1274,57 @@ func (a *AstartePodPrioritiesSpec) IsEnabled() bool {
 	return a != nil && a.Enable
 }
 
+// IsEnabled returns whether the connectivity preflight should run
+func (p *AstartePreflightSpec) IsEnabled() bool {
+	return p != nil && p.Enable
+}
+
+// ShouldAuthenticate returns whether the connectivity preflight should log in with the configured credentials
+func (p *AstartePreflightSpec) ShouldAuthenticate() bool {
+	return p != nil && p.Authenticate
+}
+
+// HoldsDeployment returns whether the Astarte components should wait for the connectivity preflight to pass
+func (p *AstartePreflightSpec) HoldsDeployment() bool {
+	return p != nil && p.HoldDeployment
+}
+
+// GetTimeout returns the timeout of each connectivity check
+func (p *AstartePreflightSpec) GetTimeout() time.Duration {
+	if p == nil || p.TimeoutSeconds == nil {
+		return 5 * time.Second
+	}
+	return time.Duration(*p.TimeoutSeconds) * time.Second
+}
+
+// IsEnabled returns whether the Cassandra topology should be checked
+func (t *AstarteCassandraTopologyCheckSpec) IsEnabled() bool {
+	return t != nil && t.Enable
+}
+
+// GetTimeout returns the timeout of the connection to each Cassandra node
+func (t *AstarteCassandraTopologyCheckSpec) GetTimeout() time.Duration {
+	if t == nil || t.TimeoutSeconds == nil {
+		return 5 * time.Second
+	}
+	return time.Duration(*t.TimeoutSeconds) * time.Second
+}
+
+// IsOperatorManaged returns whether the Operator should manage the HorizontalPodAutoscaler itself
+func (a *AstarteGenericClusteredResourceAutoscalerSpec) IsOperatorManaged() bool {
+	return a != nil && a.MaxReplicas != nil
+}
+
+// IsEnabled returns whether the Data Updater Plant queue autoscaler is enabled
+func (a *AstarteDataUpdaterPlantQueueAutoscalerSpec) IsEnabled() bool {
+	return a != nil && a.Enable
+}
+
+// IsHorizontal returns whether any kind of horizontal autoscaling was requested
+func (a *AstarteGenericClusteredResourceAutoscalerSpec) IsHorizontal() bool {
+	return a != nil && (a.Horizontal != "" || a.IsOperatorManaged())
+}
+
 // AstarteFeatures enables/disables selectively a set of global features in Astarte
 type AstarteFeatures struct {
 	// +kubebuilder:validation:Optional
@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
 )
 
 var _ = Describe("Astarte types testing", Ordered, Serial, func() {
@@ -82,6 +83,21 @@ var _ = Describe("Astarte types testing", Ordered, Serial, func() {
 		})
 	})
 
+	Describe("Test AstartePreflightSpec.IsEnabled()", func() {
+		It("should return true if AstartePreflightSpec is enabled", func() {
+			cr.Spec.Preflight = &AstartePreflightSpec{Enable: true}
+			Expect(cr.Spec.Preflight.IsEnabled()).To(BeTrue())
+		})
+		It("should return false if AstartePreflightSpec is disabled", func() {
+			cr.Spec.Preflight = &AstartePreflightSpec{HoldDeployment: true}
+			Expect(cr.Spec.Preflight.IsEnabled()).To(BeFalse())
+		})
+		It("should return false if AstartePreflightSpec is nil", func() {
+			cr.Spec.Preflight = nil
+			Expect(cr.Spec.Preflight.IsEnabled()).To(BeFalse())
+		})
+	})
+
 	Describe("Test CFSSL.GetPodLabels()", func() {
 		It("should return a map with the correct pod labels", func() {
 			// Set some labels to AstarteCFSSLSpec
@@ -183,4 +199,51 @@ var _ = Describe("Astarte types testing", Ordered, Serial, func() {
 			})
 		})
 	})
+
+	Describe("Test AstarteDeletionPolicySpec", func() {
+		It("should delete everything by default", func() {
+			var p *AstarteDeletionPolicySpec
+			Expect(p.GetPersistentVolumeClaimsPolicy()).To(Equal(DeletionPolicyDelete))
+			Expect(p.GetSecretsPolicy()).To(Equal(DeletionPolicyDelete))
+			p = &AstarteDeletionPolicySpec{}
+			Expect(p.GetPersistentVolumeClaimsPolicy()).To(Equal(DeletionPolicyDelete))
+			Expect(p.GetSecretsPolicy()).To(Equal(DeletionPolicyDelete))
+		})
+
+		It("should apply the overall policy, retaining Secrets when snapshotting", func() {
+			p := &AstarteDeletionPolicySpec{Policy: DeletionPolicySnapshot}
+			Expect(p.GetPersistentVolumeClaimsPolicy()).To(Equal(DeletionPolicySnapshot))
+			Expect(p.GetSecretsPolicy()).To(Equal(DeletionPolicyRetain))
+		})
+
+		It("should let kind-specific policies override the overall one", func() {
+			p := &AstarteDeletionPolicySpec{Policy: DeletionPolicyRetain, PersistentVolumeClaims: DeletionPolicyDelete, Secrets: DeletionPolicyDelete}
+			Expect(p.GetPersistentVolumeClaimsPolicy()).To(Equal(DeletionPolicyDelete))
+			Expect(p.GetSecretsPolicy()).To(Equal(DeletionPolicyDelete))
+		})
+	})
+
+	Describe("Test Astarte.GetReferencedSecretNames()", func() {
+		It("should return nothing when no Secret is referenced", func() {
+			Expect((&Astarte{}).GetReferencedSecretNames()).To(BeEmpty())
+		})
+
+		It("should return the deduplicated user-provided Secrets", func() {
+			r := &Astarte{Spec: AstarteSpec{
+				RabbitMQ: AstarteRabbitMQSpec{Connection: &AstarteRabbitMQConnectionSpec{GenericConnectionSpec: GenericConnectionSpec{
+					CredentialsSecret: &LoginCredentialsSecret{Name: "rabbitmq-credentials"},
+					SSLConfiguration:  GenericSSLConfigurationSpec{CustomCASecret: v1.LocalObjectReference{Name: "custom-ca"}},
+				}}},
+				Cassandra: AstarteCassandraSpec{Connection: &AstarteCassandraConnectionSpec{GenericConnectionSpec: GenericConnectionSpec{
+					ConnectionStringSecret: &ConnectionStringSecret{Name: "cassandra-connection"},
+					SSLConfiguration:       GenericSSLConfigurationSpec{CustomCASecret: v1.LocalObjectReference{Name: "custom-ca"}},
+				}}},
+				VerneMQ: AstarteVerneMQSpec{SSLListenerCertSecretName: "vernemq-tls"},
+				CFSSL:   AstarteCFSSLSpec{CASecret: v1.LocalObjectReference{Name: "devices-ca"}},
+			}}
+			Expect(r.GetReferencedSecretNames()).To(Equal([]string{
+				"cassandra-connection", "custom-ca", "devices-ca", "rabbitmq-credentials", "vernemq-tls",
+			}))
+		})
+	})
 })
@@ -21,16 +21,27 @@ package v2alpha1
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	semver "github.com/Masterminds/semver/v3"
 	"go.openly.dev/pointy"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +51,9 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/connstring"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/version"
 )
 
 // log is for logging in this package.
@@ -65,13 +79,13 @@ var _ webhook.Defaulter = &Astarte{}
 func (r *Astarte) Default() {
 	astartelog.Info("default", "name", r.Name)
 
-	// TODO(user): fill in your defaulting logic.
+	r.SetDefaults()
 }
 
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
-// +kubebuilder:webhook:path=/validate-api-astarte-platform-org-v2alpha1-astarte,mutating=false,failurePolicy=fail,sideEffects=None,groups=api.astarte-platform.org,resources=astartes,verbs=create;update,versions=v2alpha1,name=vastarte.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-api-astarte-platform-org-v2alpha1-astarte,mutating=false,failurePolicy=fail,sideEffects=None,groups=api.astarte-platform.org,resources=astartes,verbs=create;update;delete,versions=v2alpha1,name=vastarte.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &Astarte{}
 
@@ -93,11 +107,19 @@ func (r *Astarte) ValidateCreate() (admission.Warnings, error) {
 		allErrs = append(allErrs, errList...)
 	}
 
+	if errList := r.validateConnections(nil); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	errList, warnings := r.validateReferencedSecrets(nil)
+	allErrs = append(allErrs, errList...)
+	warnings = append(warnings, r.getDeprecationWarnings()...)
+
 	if len(allErrs) == 0 {
-		return nil, nil
+		return warnings, nil
 	}
 
-	return nil, apierrors.NewInvalid(
+	return warnings, apierrors.NewInvalid(
 		schema.GroupKind{Group: "api", Kind: "Astarte"},
 		r.Name,
 		allErrs,
@@ -119,26 +141,57 @@ func (r *Astarte) ValidateUpdate(old runtime.Object) (admission.Warnings, error)
 		allErrs = append(allErrs, err)
 	}
 
+	if errList := r.validateUpdatePersistentStorage(oldAstarte); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
 	if errList := r.validateAstarte(); len(errList) > 0 {
 		allErrs = append(allErrs, errList...)
 	}
 
+	if errList := r.validateConnections(oldAstarte); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	errList, warnings := r.validateUpdateAstarteVersion(oldAstarte)
+	allErrs = append(allErrs, errList...)
+
+	errList, secretsWarnings := r.validateReferencedSecrets(oldAstarte)
+	allErrs = append(allErrs, errList...)
+	warnings = append(warnings, secretsWarnings...)
+	warnings = append(warnings, r.getDeprecationWarnings()...)
+
 	if len(allErrs) == 0 {
-		return nil, nil
+		return warnings, nil
 	}
 
-	return nil, apierrors.NewInvalid(
+	return warnings, apierrors.NewInvalid(
 		schema.GroupKind{Group: "api", Kind: "Astarte"},
 		r.Name,
 		allErrs,
 	)
 }
 
+// getDeprecationWarnings warns about the deprecated fields which are set, and have no effect
+func (r *Astarte) getDeprecationWarnings() admission.Warnings {
+	var warnings admission.Warnings
+	if r.Spec.CFSSL.Storage != nil {
+		warnings = append(warnings, "spec.cfssl.storage is deprecated and has no effect, as CFSSL is deployed without persistent storage")
+	}
+	return warnings
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *Astarte) ValidateDelete() (admission.Warnings, error) {
 	astartelog.Info("validate delete", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object deletion.
+	if r.IsDeletionProtected() {
+		err := fmt.Errorf("deletion of Astarte %s is forbidden: clear spec.deletionProtection and the %s annotation first",
+			r.Name, AnnotationDeletionProtection)
+		astartelog.Info(err.Error())
+		return nil, apierrors.NewForbidden(schema.GroupResource{Group: "api.astarte-platform.org", Resource: "astartes"}, r.Name, err)
+	}
+
 	return nil, nil
 }
 
@@ -194,13 +247,295 @@ func (r *Astarte) validateAstarte() field.ErrorList {
 		allErrs = append(allErrs, err)
 	}
 
+	if errList := r.validateAutoscalerSpecs(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	if errList := r.validateDataUpdaterPlantQueueAutoscaler(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
 	if err := r.validateCFSSLDefinition(); err != nil {
 		allErrs = append(allErrs, err)
 	}
 
+	if errList := r.validateDeviceCABundle(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	if errList := r.validateVerneMQListeners(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	if errList := r.validateVerneMQConfigOverrides(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	if errList := r.validateRequiredHosts(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	if errList := r.validateRabbitMQProvisioning(); len(errList) > 0 {
+		allErrs = append(allErrs, errList...)
+	}
+
+	return allErrs
+}
+
+// validateConnections ensures RabbitMQ and Cassandra are given either credentials or a connection string, that
+// their address is not set both in the spec and in the connection string, and that client certificates come with SSL.
+// Instances predating connection strings might have no Cassandra nodes: they are required on creation, or when the
+// Cassandra connection changes.
+func (r *Astarte) validateConnections(oldAstarte *Astarte) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if connection := r.Spec.RabbitMQ.Connection; connection != nil {
+		fldPath := field.NewPath("spec").Child("rabbitmq").Child("connection")
+		allErrs = append(allErrs, validateConnectionSecrets(fldPath, &connection.GenericConnectionSpec)...)
+
+		if connection.ConnectionStringSecret != nil {
+			if connection.Host != "" {
+				allErrs = append(allErrs, forbiddenWithConnectionString(fldPath.Child("host")))
+			}
+			if connection.Port != nil {
+				allErrs = append(allErrs, forbiddenWithConnectionString(fldPath.Child("port")))
+			}
+			if connection.VirtualHost != "" {
+				allErrs = append(allErrs, forbiddenWithConnectionString(fldPath.Child("virtualHost")))
+			}
+		} else if connection.Host == "" {
+			err := errors.New("the RabbitMQ host is required, unless connectionStringSecret is set")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Required(fldPath.Child("host"), err.Error()))
+		}
+	}
+
+	if connection := r.Spec.Cassandra.Connection; connection != nil {
+		fldPath := field.NewPath("spec").Child("cassandra").Child("connection")
+		allErrs = append(allErrs, validateConnectionSecrets(fldPath, &connection.GenericConnectionSpec)...)
+
+		if connection.ConnectionStringSecret != nil {
+			if len(connection.Nodes) > 0 {
+				allErrs = append(allErrs, forbiddenWithConnectionString(fldPath.Child("nodes")))
+			}
+		} else if len(connection.Nodes) == 0 && (oldAstarte == nil || !reflect.DeepEqual(connection, oldAstarte.Spec.Cassandra.Connection)) {
+			err := errors.New("at least one Cassandra node is required, unless connectionStringSecret is set")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Required(fldPath.Child("nodes"), err.Error()))
+		}
+
+		for i, node := range connection.Nodes {
+			allErrs = append(allErrs, validateHostAndPort(fldPath.Child("nodes").Index(i), node)...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateRequiredHosts ensures the VerneMQ and the FDO Rendezvous Server addresses have a host. Ports have defaults.
+func (r *Astarte) validateRequiredHosts() field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if r.Spec.VerneMQ.Host == "" {
+		err := errors.New("the VerneMQ host is required")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("vernemq").Child("host"), err.Error()))
+	}
+
+	if fdo := r.Spec.Features.FDO; fdo != nil && fdo.Enable && fdo.RendezvousServer.Host == "" {
+		err := errors.New("the host of the FDO Rendezvous Server is required")
+		astartelog.Info(err.Error())
+		fldPath := field.NewPath("spec").Child("features").Child("fdo").Child("rendezvousServer").Child("host")
+		allErrs = append(allErrs, field.Required(fldPath, err.Error()))
+	}
+
+	return allErrs
+}
+
+// validateHostAndPort ensures both the host and the port of an address are set
+func validateHostAndPort(fldPath *field.Path, address HostAndPort) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if address.Host == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("host"), "the host is required"))
+	}
+	if address.Port == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("port"), "the port is required"))
+	}
+	return allErrs
+}
+
+// validateRabbitMQProvisioning ensures the Operator knows which user to provision, and how to mirror the data queues
+func (r *Astarte) validateRabbitMQProvisioning() field.ErrorList {
+	allErrs := field.ErrorList{}
+	provisioning := r.Spec.RabbitMQ.Provisioning
+	if provisioning == nil {
+		return allErrs
+	}
+	fldPath := field.NewPath("spec").Child("rabbitmq").Child("provisioning")
+
+	if provisioning.AdminCredentialsSecret == nil {
+		err := errors.New("the credentials of a RabbitMQ administrator are required for provisioning")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Required(fldPath.Child("adminCredentialsSecret"), err.Error()))
+	}
+
+	if connection := r.Spec.RabbitMQ.Connection; connection != nil && connection.UsesClientCertificateOnly() {
+		err := errors.New("the Astarte user cannot be provisioned when RabbitMQ is given no credentials")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Forbidden(fldPath, err.Error()))
+	}
+
+	if policy := provisioning.DataQueuesPolicy; policy != nil {
+		policyPath := fldPath.Child("dataQueuesPolicy")
+		if policy.HAMode == "exactly" && policy.HAParams == nil {
+			err := errors.New("must be set when haMode is exactly")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Required(policyPath.Child("haParams"), err.Error()))
+		} else if policy.HAMode != "exactly" && policy.HAParams != nil {
+			err := errors.New("can be set only when haMode is exactly")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Forbidden(policyPath.Child("haParams"), err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+func validateConnectionSecrets(fldPath *field.Path, connection *GenericConnectionSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if connection.CredentialsSecret != nil && connection.ConnectionStringSecret != nil {
+		err := errors.New("credentialsSecret and connectionStringSecret are mutually exclusive, as the connection string holds the credentials")
+		astartelog.Info(err.Error(), "path", fldPath.String())
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("credentialsSecret"), err.Error()))
+	}
+
+	// Without SSL the client certificate would be silently ignored, and the connection would not authenticate
+	if connection.SSLConfiguration.ClientCertSecret.Name != "" && !connection.SSLConfiguration.Enable {
+		err := errors.New("a client certificate can be used only when sslConfiguration.enable is true")
+		astartelog.Info(err.Error(), "path", fldPath.String())
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("sslConfiguration").Child("clientCertSecret"), err.Error()))
+	}
+
+	return allErrs
+}
+
+func forbiddenWithConnectionString(fldPath *field.Path) *field.Error {
+	err := errors.New("must not be set together with connectionStringSecret, which already provides it")
+	astartelog.Info(err.Error(), "path", fldPath.String())
+	return field.Forbidden(fldPath, err.Error())
+}
+
+// validateVerneMQListeners ensures additional listeners don't clash with each other, nor with what the Operator configures
+func (r *Astarte) validateVerneMQListeners() field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec").Child("vernemq").Child("listeners")
+
+	names := map[string]bool{}
+	ports := map[int32]bool{}
+	for i, listener := range r.Spec.VerneMQ.Listeners {
+		if reservedVerneMQListenerNames[listener.Name] || names[listener.Name] {
+			err := fmt.Errorf("listener name %s is already in use", listener.Name)
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), listener.Name, err.Error()))
+		}
+		names[listener.Name] = true
+
+		if isReservedVerneMQPort(listener.Port) || ports[listener.Port] {
+			err := fmt.Errorf("port %d is already in use", listener.Port)
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("port"), listener.Port, err.Error()))
+		}
+		ports[listener.Port] = true
+
+		if listener.ProxyProtocol && listener.Type.IsTLS() {
+			err := errors.New("the PROXY protocol is only supported by tcp and ws listeners")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("proxyProtocol"), listener.ProxyProtocol, err.Error()))
+		}
+
+		// TLS listeners rely on the certificate mounted for the SSL listener
+		if listener.Type.IsTLS() && (!pointy.BoolValue(r.Spec.VerneMQ.SSLListener, false) || r.Spec.VerneMQ.SSLListenerCertSecretName == "") {
+			err := errors.New("TLS listeners require sslListener to be enabled, with sslListenerCertSecretName set")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("type"), listener.Type, err.Error()))
+		}
+	}
+
 	return allErrs
 }
 
+// validateVerneMQConfigOverrides ensures overrides are valid vernemq.conf keys, and don't touch settings the
+// Operator manages
+func (r *Astarte) validateVerneMQConfigOverrides() field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec").Child("vernemq").Child("configOverrides")
+
+	// Sort keys, so that errors are reported in a stable order
+	keys := make([]string, 0, len(r.Spec.VerneMQ.ConfigOverrides))
+	for key := range r.Spec.VerneMQ.ConfigOverrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !verneMQConfigKeyRegexp.MatchString(key) {
+			err := errors.New("must be a vernemq.conf key made of lowercase alphanumeric words separated by '.' or '_'")
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), key, err.Error()))
+			continue
+		}
+
+		if isReservedVerneMQConfigKey(key) {
+			err := fmt.Errorf("%s is managed by the Operator and cannot be overridden", key)
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+func isReservedVerneMQPort(port int32) bool {
+	switch {
+	case port == 80, port == 1883, port == 1885, port == VerneMQProxyProtocolPort, port == 4369, port == 8883, port == 8888, port == 44053:
+		return true
+	case port >= 9100 && port <= 9109:
+		// Erlang distribution
+		return true
+	}
+	return false
+}
+
+func isReservedVerneMQConfigKey(key string) bool {
+	if reservedVerneMQConfigKeys[key] {
+		return true
+	}
+	for _, prefix := range reservedVerneMQConfigKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	verneMQConfigKeyRegexp = regexp.MustCompile(`^[a-z0-9]+([._][a-z0-9]+)*$`)
+
+	// Port names used by the VerneMQ StatefulSet and Service, plus the name of the listeners in the default configuration
+	reservedVerneMQListenerNames = map[string]bool{
+		"default": true, "mqtt": true, "mqtt-ssl": true, "mqtt-reverse": true, "acme-verify": true,
+		"vmq-msg-dist": true, "epmd": true, "metrics": true, "webadmin": true, "mqtt-proxy": true, "proxy": true,
+	}
+
+	// vernemq.conf settings the Operator manages, either directly or through a dedicated field
+	reservedVerneMQConfigKeys = map[string]bool{
+		"nodename": true, "distributed_cookie": true, "discovery_kubernetes": true, "kubernetes_label_selector": true,
+		"persistent_client_expiration": true, "max_offline_messages": true,
+	}
+	reservedVerneMQConfigKeyPrefixes = []string{"listener.", "astarte_vmq_plugin.", "plugins.astarte_vmq_plugin"}
+)
+
 func (r *Astarte) validateSSLListener() field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -214,8 +549,8 @@ func (r *Astarte) validateSSLListener() field.ErrorList {
 			err := errors.New("must be set when sslListener is true")
 			astartelog.Info(err.Error())
 			allErrs = append(allErrs, field.Invalid(fldPath, secretName, err.Error()))
-		} else {
-			// If the name is set, then ensure the Secret resource exists.
+		} else if r.DeletionTimestamp == nil {
+			// If the name is set, then ensure the Secret resource exists, unless the Astarte is going away anyway.
 			secret := &v1.Secret{}
 			if err := c.Get(context.Background(), types.NamespacedName{Name: secretName, Namespace: r.Namespace}, secret); err != nil {
 				astartelog.Info(err.Error())
@@ -257,7 +592,9 @@ func validatePodLabelsForClusteredResource(r PodLabelsGetter) field.ErrorList {
 }
 
 func validateAutoscalerForClusteredResources(r *Astarte) *field.Error {
-	// We have no constraints on autoscaling except for these components
+	// We have no constraints on autoscaling except for these components.
+	// DataUpdaterPlant is sharded across single-replica deployments. CFSSL is a singleton too, but it has no
+	// autoscaler field at all, so the CRD schema already rejects one.
 	excludedResources := []AstarteGenericClusteredResource{
 		r.Spec.Components.DataUpdaterPlant.AstarteGenericClusteredResource,
 	}
@@ -268,9 +605,9 @@ func validateAutoscalerForClusteredResources(r *Astarte) *field.Error {
 func validateAutoscalerForClusteredResourcesExcluding(r *Astarte, excluded []AstarteGenericClusteredResource) *field.Error {
 	if r.Spec.Features.Autoscaling {
 		for _, v := range excluded {
-			if v.Autoscale != nil && v.Autoscale.Horizontal != "" {
+			if v.Autoscale.IsHorizontal() {
 				fldPath := field.NewPath("")
-				err := errors.New("invalid autoscaler: cannot autoscale horizontally RabbitMQ, DataUpdaterPlant or Cassandra")
+				err := errors.New("invalid autoscaler: cannot autoscale horizontally DataUpdaterPlant, which is sharded across single-replica Deployments")
 				astartelog.Info(err.Error())
 				return field.Invalid(fldPath, "", err.Error())
 			}
@@ -279,6 +616,92 @@ func validateAutoscalerForClusteredResourcesExcluding(r *Astarte, excluded []Ast
 	return nil
 }
 
+func (r *Astarte) validateAutoscalerSpecs() field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !r.Spec.Features.Autoscaling {
+		return allErrs
+	}
+
+	componentsPath := field.NewPath("spec").Child("components")
+	resources := []struct {
+		fldPath  *field.Path
+		resource AstarteGenericClusteredResource
+	}{
+		{field.NewPath("spec").Child("vernemq"), r.Spec.VerneMQ.AstarteGenericClusteredResource},
+		{componentsPath.Child("flow"), r.Spec.Components.Flow.AstarteGenericClusteredResource},
+		{componentsPath.Child("housekeeping"), r.Spec.Components.Housekeeping.AstarteGenericClusteredResource},
+		{componentsPath.Child("realmManagement"), r.Spec.Components.RealmManagement.AstarteGenericClusteredResource},
+		{componentsPath.Child("pairing"), r.Spec.Components.Pairing.AstarteGenericClusteredResource},
+		{componentsPath.Child("appengineApi"), r.Spec.Components.AppengineAPI.AstarteGenericClusteredResource},
+		{componentsPath.Child("triggerEngine"), r.Spec.Components.TriggerEngine.AstarteGenericClusteredResource},
+		{componentsPath.Child("dashboard"), r.Spec.Components.Dashboard.AstarteGenericClusteredResource},
+	}
+	for _, v := range resources {
+		allErrs = append(allErrs, validateAutoscalerSpec(v.resource.Autoscale, v.fldPath.Child("autoscaler"))...)
+	}
+
+	return allErrs
+}
+
+func validateAutoscalerSpec(a *AstarteGenericClusteredResourceAutoscalerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if a == nil {
+		return allErrs
+	}
+
+	if a.Horizontal != "" && a.IsOperatorManaged() {
+		err := errors.New("cannot both reference an existing HorizontalPodAutoscaler and have the Operator manage one")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("horizontal"), a.Horizontal, err.Error()))
+	}
+
+	if !a.IsOperatorManaged() && (a.MinReplicas != nil || a.TargetCPUUtilizationPercentage != nil ||
+		a.TargetMemoryUtilizationPercentage != nil || a.Behavior != nil) {
+		err := errors.New("must be set when configuring the HorizontalPodAutoscaler")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxReplicas"), err.Error()))
+	}
+
+	if a.IsOperatorManaged() && a.MinReplicas != nil && *a.MinReplicas > *a.MaxReplicas {
+		err := errors.New("minReplicas cannot be greater than maxReplicas")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *a.MinReplicas, err.Error()))
+	}
+
+	return allErrs
+}
+
+func (r *Astarte) validateDataUpdaterPlantQueueAutoscaler() field.ErrorList {
+	allErrs := field.ErrorList{}
+	autoscaler := r.Spec.Components.DataUpdaterPlant.QueueAutoscaler
+	if !autoscaler.IsEnabled() {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec").Child("components").Child("dataUpdaterPlant").Child("queueAutoscaler")
+	if autoscaler.MaxShards < 1 {
+		err := errors.New("must be set when the queue autoscaler is enabled")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxShards"), err.Error()))
+		return allErrs
+	}
+
+	if autoscaler.MinShards != nil && *autoscaler.MinShards > autoscaler.MaxShards {
+		err := errors.New("minShards cannot be greater than maxShards")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minShards"), *autoscaler.MinShards, err.Error()))
+	}
+
+	// Every shard needs at least a data queue to consume from
+	if dataQueueCount := pointy.IntValue(r.Spec.Components.DataUpdaterPlant.DataQueueCount, 128); int(autoscaler.MaxShards) > dataQueueCount {
+		err := fmt.Errorf("maxShards cannot be greater than the data queue count (%d)", dataQueueCount)
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxShards"), autoscaler.MaxShards, err.Error()))
+	}
+
+	return allErrs
+}
+
 func (r *Astarte) validateAstartePriorityClasses() *field.Error {
 	if r.Spec.Features.AstartePodPriorities.IsEnabled() {
 		return r.validatePriorityClassesValues()
@@ -380,7 +803,260 @@ func (r *Astarte) validateUpdateAstarteSystemKeyspace(oldAstarte *Astarte) *fiel
 	return nil
 }
 
+// validateUpdatePersistentStorage ensures storage is never shrunk, as volumes can only be expanded
+func (r *Astarte) validateUpdatePersistentStorage(oldAstarte *Astarte) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// VerneMQ defaults to 4G of storage
+	defaultVerneMQSize := resource.NewScaledQuantity(4, resource.Giga)
+	fldPath := field.NewPath("spec").Child("vernemq").Child("storage")
+	if err := validatePersistentStorageUpdate(fldPath.Child("size"),
+		oldAstarte.Spec.VerneMQ.Storage, r.Spec.VerneMQ.Storage, defaultVerneMQSize); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	// Growing storage expands the existing volumes, which their StorageClass must allow
+	oldSize, newSize := getPersistentStorageSizes(oldAstarte.Spec.VerneMQ.Storage, r.Spec.VerneMQ.Storage, defaultVerneMQSize)
+	if oldSize != nil && newSize != nil && newSize.Cmp(*oldSize) > 0 {
+		className := ""
+		if r.Spec.VerneMQ.Storage != nil {
+			className = r.Spec.VerneMQ.Storage.ClassName
+		}
+		if err := validateStorageClassAllowsExpansion(fldPath.Child("size"), className); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	return allErrs
+}
+
+func validatePersistentStorageUpdate(fldPath *field.Path, oldStorage, newStorage *AstartePersistentStorageSpec, defaultSize *resource.Quantity) *field.Error {
+	oldSize, newSize := getPersistentStorageSizes(oldStorage, newStorage, defaultSize)
+	if oldSize == nil || newSize == nil || newSize.Cmp(*oldSize) >= 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("storage cannot be shrunk from %s to %s, as volumes can only be expanded", oldSize.String(), newSize.String())
+	astartelog.Info(err.Error())
+	return field.Invalid(fldPath, newSize.String(), err.Error())
+}
+
+// getPersistentStorageSizes returns the storage sizes before and after an update, or nil when the volume is
+// not managed by the Operator
+func getPersistentStorageSizes(oldStorage, newStorage *AstartePersistentStorageSpec, defaultSize *resource.Quantity) (*resource.Quantity, *resource.Quantity) {
+	oldSize, newSize := defaultSize, defaultSize
+	if oldStorage != nil {
+		// A custom volume is not managed by the Operator
+		if oldStorage.VolumeDefinition != nil {
+			return nil, nil
+		}
+		if oldStorage.Size != nil {
+			oldSize = oldStorage.Size
+		}
+	}
+	if newStorage != nil {
+		if newStorage.VolumeDefinition != nil {
+			return nil, nil
+		}
+		if newStorage.Size != nil {
+			newSize = newStorage.Size
+		}
+	}
+
+	return oldSize, newSize
+}
+
+// defaultStorageClassAnnotation marks the StorageClass used by claims which do not name one
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// validateStorageClassAllowsExpansion ensures the volumes of the given StorageClass, or of the default one when
+// empty, can be expanded. Classes which can't be found are left to the reconciler.
+func validateStorageClassAllowsExpansion(fldPath *field.Path, className string) *field.Error {
+	if c == nil {
+		return nil
+	}
+
+	var storageClass *storagev1.StorageClass
+	if className == "" {
+		storageClasses := &storagev1.StorageClassList{}
+		if err := c.List(context.Background(), storageClasses); err != nil {
+			astartelog.Info(err.Error())
+			return nil
+		}
+		for i := range storageClasses.Items {
+			if storageClasses.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+				storageClass = &storageClasses.Items[i]
+			}
+		}
+	} else {
+		storageClass = &storagev1.StorageClass{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: className}, storageClass); err != nil {
+			astartelog.Info(err.Error())
+			return nil
+		}
+	}
+
+	if storageClass == nil || pointy.BoolValue(storageClass.AllowVolumeExpansion, false) {
+		return nil
+	}
+	err := fmt.Errorf("StorageClass %s does not allow volume expansion, hence storage cannot grow", storageClass.Name)
+	astartelog.Info(err.Error())
+	return field.Forbidden(fldPath, err.Error())
+}
+
+// validateUpdateAstarteVersion enforces the upgrade paths supported by the Operator, so that an unsupported
+// version change is refused at admission time rather than stalling the reconciliation
+func (r *Astarte) validateUpdateAstarteVersion(oldAstarte *Astarte) (field.ErrorList, admission.Warnings) {
+	allErrs := field.ErrorList{}
+	var warnings admission.Warnings
+
+	if r.Spec.Version != oldAstarte.Spec.Version {
+		// Check the path from the version actually running, so that a bump which was never applied can be
+		// reverted and chained edits cannot skip minor versions. A new installation runs nothing yet.
+		runningVersion := oldAstarte.Spec.Version
+		if oldAstarte.Status.AstarteVersion != "" {
+			runningVersion = oldAstarte.Status.AstarteVersion
+		}
+		if err := validateAstarteUpgradePath(field.NewPath("spec").Child("version"), runningVersion, r.Spec.Version); err != nil {
+			allErrs = append(allErrs, err)
+		}
+
+		// A new installation has no health to speak of yet, and going back to the running version upgrades nothing
+		if oldAstarte.Status.AstarteVersion != "" && r.Spec.Version != runningVersion &&
+			oldAstarte.Status.Health != AstarteClusterHealthGreen {
+			warning := fmt.Sprintf("Astarte %s is reporting %s health: the upgrade to %s will not start until the cluster is green",
+				r.Name, oldAstarte.Status.Health, r.Spec.Version)
+			astartelog.Info(warning)
+			warnings = append(warnings, warning)
+		}
+	}
+
+	componentsPath := field.NewPath("spec").Child("components")
+	components := []struct {
+		fldPath    *field.Path
+		oldVersion string
+		newVersion string
+	}{
+		{field.NewPath("spec").Child("vernemq"), oldAstarte.Spec.VerneMQ.Version, r.Spec.VerneMQ.Version},
+		{componentsPath.Child("flow"), oldAstarte.Spec.Components.Flow.Version, r.Spec.Components.Flow.Version},
+		{componentsPath.Child("housekeeping"), oldAstarte.Spec.Components.Housekeeping.Version, r.Spec.Components.Housekeeping.Version},
+		{componentsPath.Child("realmManagement"), oldAstarte.Spec.Components.RealmManagement.Version, r.Spec.Components.RealmManagement.Version},
+		{componentsPath.Child("pairing"), oldAstarte.Spec.Components.Pairing.Version, r.Spec.Components.Pairing.Version},
+		{componentsPath.Child("dataUpdaterPlant"), oldAstarte.Spec.Components.DataUpdaterPlant.Version, r.Spec.Components.DataUpdaterPlant.Version},
+		{componentsPath.Child("appengineApi"), oldAstarte.Spec.Components.AppengineAPI.Version, r.Spec.Components.AppengineAPI.Version},
+		{componentsPath.Child("triggerEngine"), oldAstarte.Spec.Components.TriggerEngine.Version, r.Spec.Components.TriggerEngine.Version},
+		{componentsPath.Child("dashboard"), oldAstarte.Spec.Components.Dashboard.Version, r.Spec.Components.Dashboard.Version},
+	}
+	for _, c := range components {
+		// Leave untouched overrides alone, unless the Astarte version they refer to changed
+		if c.newVersion == "" || (c.newVersion == c.oldVersion && r.Spec.Version == oldAstarte.Spec.Version) {
+			continue
+		}
+		if err := validateAstarteComponentVersion(c.fldPath.Child("version"), c.newVersion, r.Spec.Version); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	return allErrs, warnings
+}
+
+// validateAstarteUpgradePath checks that moving from oldVersion to newVersion is an upgrade the Operator can
+// carry out: no downgrades, no skipped minor versions, and snapshots only when the Operator can manage them
+func validateAstarteUpgradePath(fldPath *field.Path, oldVersion, newVersion string) *field.Error {
+	if !version.CanManageVersion(newVersion) {
+		err := fmt.Errorf("version %s cannot be managed by this Operator", newVersion)
+		astartelog.Info(err.Error())
+		return field.Invalid(fldPath, newVersion, err.Error())
+	}
+
+	if isAstarteSnapshotVersion(oldVersion) != isAstarteSnapshotVersion(newVersion) && !version.CanManageSnapshot() {
+		err := fmt.Errorf("transitioning from %s to %s requires an Operator able to manage snapshots", oldVersion, newVersion)
+		astartelog.Info(err.Error())
+		return field.Forbidden(fldPath, err.Error())
+	}
+
+	// The snapshot tracks the development branch, there is no meaningful ordering to it
+	if oldVersion == version.SnapshotVersion || newVersion == version.SnapshotVersion {
+		return nil
+	}
+
+	oldSemVer, err := getAstarteReleaseSemanticVersion(oldVersion)
+	if err != nil {
+		// We have no way to know where we're coming from, let the reconciler take it from here
+		return nil
+	}
+	newSemVer, err := getAstarteReleaseSemanticVersion(newVersion)
+	if err != nil {
+		astartelog.Info(err.Error())
+		return field.Invalid(fldPath, newVersion, err.Error())
+	}
+
+	switch {
+	case newSemVer.LessThan(oldSemVer):
+		err = fmt.Errorf("downgrading Astarte from %s to %s is not supported", oldVersion, newVersion)
+	case newSemVer.Major() == oldSemVer.Major() && newSemVer.Minor() > oldSemVer.Minor()+1,
+		newSemVer.Major() > oldSemVer.Major()+1,
+		newSemVer.Major() == oldSemVer.Major()+1 && newSemVer.Minor() > 0:
+		err = fmt.Errorf("upgrading Astarte from %s to %s skips a minor version. Please upgrade one minor version at a time", oldVersion, newVersion)
+	default:
+		return nil
+	}
+
+	astartelog.Info(err.Error())
+	return field.Forbidden(fldPath, err.Error())
+}
+
+// validateAstarteComponentVersion checks that a component version override is managed by the Operator and
+// belongs to the same minor release as the Astarte version
+func validateAstarteComponentVersion(fldPath *field.Path, componentVersion, astarteVersion string) *field.Error {
+	if componentVersion == version.SnapshotVersion {
+		if version.CanManageSnapshot() {
+			return nil
+		}
+		err := fmt.Errorf("version %s cannot be managed by this Operator", componentVersion)
+		astartelog.Info(err.Error())
+		return field.Invalid(fldPath, componentVersion, err.Error())
+	}
+
+	constraints := []string{version.AstarteVersionConstraintString}
+	if astarteSemVer, err := getAstarteReleaseSemanticVersion(astarteVersion); err == nil && astarteVersion != version.SnapshotVersion {
+		constraints = append(constraints, fmt.Sprintf("~%d.%d.0", astarteSemVer.Major(), astarteSemVer.Minor()))
+	}
+
+	for _, constraint := range constraints {
+		if err := version.CheckConstraintAgainstAstarteComponentVersion(constraint, componentVersion, astarteVersion); err != nil {
+			if errors.Is(err, version.ErrConstraintNotSatisfied) {
+				err = fmt.Errorf("version %s does not satisfy %s, required by Astarte %s", componentVersion, constraint, astarteVersion)
+			}
+			astartelog.Info(err.Error())
+			return field.Invalid(fldPath, componentVersion, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// isAstarteSnapshotVersion returns whether v is either the development snapshot or a release snapshot
+func isAstarteSnapshotVersion(v string) bool {
+	return v == version.SnapshotVersion || strings.Contains(v, "-snapshot")
+}
+
+// getAstarteReleaseSemanticVersion parses v stripping its prerelease, so that release snapshots are compared
+// as the release they precede, just like the reconciler does
+func getAstarteReleaseSemanticVersion(v string) (*semver.Version, error) {
+	semVer, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, err
+	}
+	*semVer, err = semVer.SetPrerelease("")
+	return semVer, err
+}
+
 func (r *Astarte) validateCFSSLDefinition() *field.Error {
+	if r.Spec.CFSSL.Backend == DeviceCABackendOperator {
+		return r.validateOperatorDeviceCABackend()
+	}
+
 	if pointy.BoolValue(r.Spec.CFSSL.Deploy, true) {
 		return nil
 	}
@@ -404,3 +1080,274 @@ func (r *Astarte) validateCFSSLDefinition() *field.Error {
 
 	return nil
 }
+
+func (r *Astarte) validateOperatorDeviceCABackend() *field.Error {
+	// The Operator signs certificates on its own, and does not keep track of them in a database
+	if r.Spec.CFSSL.DBConfig != nil {
+		err := errors.New("The operator device CA backend does not support a database configuration")
+		fldPath := field.NewPath("spec").Child("cfssl").Child("dbConfig")
+		astartelog.Info(err.Error())
+		return field.Invalid(fldPath, r.Spec.CFSSL.DBConfig, err.Error())
+	}
+
+	return nil
+}
+
+func (r *Astarte) validateDeviceCABundle() field.ErrorList {
+	allErrs := field.ErrorList{}
+	bundle := r.Spec.CFSSL.PublishCABundle
+	if bundle == nil || !bundle.Enable {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec").Child("cfssl").Child("publishCABundle")
+
+	// We can publish only a CA we know about
+	if r.Spec.CFSSL.Backend != DeviceCABackendOperator && !pointy.BoolValue(r.Spec.CFSSL.Deploy, true) && r.Spec.CFSSL.CASecret.Name == "" {
+		err := errors.New("When not deploying CFSSL, the 'caSecret' must be specified to publish the devices CA bundle")
+		astartelog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("enable"), bundle.Enable, err.Error()))
+	}
+
+	if bundle.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(bundle.NamespaceSelector); err != nil {
+			astartelog.Info(err.Error())
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaceSelector"), bundle.NamespaceSelector, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// certificateExpiryWarningThreshold is how close to its expiry a certificate must be to raise a warning
+const certificateExpiryWarningThreshold = 30 * 24 * time.Hour
+
+// validateReferencedSecrets ensures the Secrets referenced by the instance exist and hold what Astarte expects.
+// Certificates which are expired or about to expire only raise warnings.
+func (r *Astarte) validateReferencedSecrets(oldAstarte *Astarte) (field.ErrorList, admission.Warnings) {
+	// The referenced Secrets may be gone already while the Astarte is being deleted, e.g. along with its namespace,
+	// and that must not prevent its finalizer from being removed
+	if r.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	allErrs := field.ErrorList{}
+	warnings := admission.Warnings{}
+
+	// On update, only the references which changed are validated, so that a Secret deleted afterwards does not
+	// block unrelated updates
+	changed := func(current, previous any) bool {
+		return oldAstarte == nil || !reflect.DeepEqual(current, previous)
+	}
+
+	for _, service := range []string{"rabbitmq", "cassandra"} {
+		connection := r.getGenericConnection(service)
+		if connection == nil {
+			continue
+		}
+		oldConnection := &GenericConnectionSpec{}
+		if oldAstarte != nil && oldAstarte.getGenericConnection(service) != nil {
+			oldConnection = oldAstarte.getGenericConnection(service)
+		}
+		fldPath := field.NewPath("spec").Child(service).Child("connection")
+
+		if s := connection.CredentialsSecret; s != nil && changed(s, oldConnection.CredentialsSecret) {
+			if secret, err := r.getReferencedSecret(s.Name, fldPath.Child("credentialsSecret")); err != nil {
+				allErrs = append(allErrs, err)
+			} else {
+				allErrs = append(allErrs, validateSecretKeys(secret, fldPath.Child("credentialsSecret"), s.UsernameKey, s.PasswordKey)...)
+			}
+		}
+
+		if s := connection.ConnectionStringSecret; s != nil && changed(s, oldConnection.ConnectionStringSecret) {
+			if secret, err := r.getReferencedSecret(s.Name, fldPath.Child("connectionStringSecret")); err != nil {
+				allErrs = append(allErrs, err)
+			} else {
+				allErrs = append(allErrs, validateConnectionString(service, secret, fldPath.Child("connectionStringSecret"), s.Key)...)
+			}
+		}
+
+		if name := connection.SSLConfiguration.CustomCASecret.Name; name != "" && changed(name, oldConnection.SSLConfiguration.CustomCASecret.Name) {
+			caPath := fldPath.Child("sslConfiguration").Child("customCASecret")
+			if secret, err := r.getReferencedSecret(name, caPath); err != nil {
+				allErrs = append(allErrs, err)
+			} else {
+				errs, w := validateSecretCertificates(secret, caPath, "ca.crt")
+				allErrs = append(allErrs, errs...)
+				warnings = append(warnings, w...)
+			}
+		}
+
+		if name := connection.SSLConfiguration.ClientCertSecret.Name; name != "" && changed(name, oldConnection.SSLConfiguration.ClientCertSecret.Name) {
+			certPath := fldPath.Child("sslConfiguration").Child("clientCertSecret")
+			if secret, err := r.getReferencedSecret(name, certPath); err != nil {
+				allErrs = append(allErrs, err)
+			} else {
+				errs, w := validateTLSSecret(secret, certPath)
+				allErrs = append(allErrs, errs...)
+				warnings = append(warnings, w...)
+			}
+		}
+	}
+
+	var oldAdminCredentialsSecret *LoginCredentialsSecret
+	if oldAstarte != nil && oldAstarte.Spec.RabbitMQ.Provisioning != nil {
+		oldAdminCredentialsSecret = oldAstarte.Spec.RabbitMQ.Provisioning.AdminCredentialsSecret
+	}
+	if p := r.Spec.RabbitMQ.Provisioning; p != nil && p.AdminCredentialsSecret != nil && changed(p.AdminCredentialsSecret, oldAdminCredentialsSecret) {
+		fldPath := field.NewPath("spec").Child("rabbitmq").Child("provisioning").Child("adminCredentialsSecret")
+		if secret, err := r.getReferencedSecret(p.AdminCredentialsSecret.Name, fldPath); err != nil {
+			allErrs = append(allErrs, err)
+		} else {
+			allErrs = append(allErrs, validateSecretKeys(secret, fldPath, p.AdminCredentialsSecret.UsernameKey, p.AdminCredentialsSecret.PasswordKey)...)
+		}
+	}
+
+	// A missing SSL listener Secret is already reported by validateSSLListener
+	if pointy.BoolValue(r.Spec.VerneMQ.SSLListener, false) && r.Spec.VerneMQ.SSLListenerCertSecretName != "" &&
+		(oldAstarte == nil || !pointy.BoolValue(oldAstarte.Spec.VerneMQ.SSLListener, false) ||
+			changed(r.Spec.VerneMQ.SSLListenerCertSecretName, oldAstarte.Spec.VerneMQ.SSLListenerCertSecretName)) {
+		fldPath := field.NewPath("spec").Child("vernemq").Child("sslListenerCertSecretName")
+		if secret, err := r.getReferencedSecret(r.Spec.VerneMQ.SSLListenerCertSecretName, fldPath); err == nil {
+			errs, w := validateTLSSecret(secret, fldPath)
+			allErrs = append(allErrs, errs...)
+			warnings = append(warnings, w...)
+		}
+	}
+
+	if name := r.Spec.CFSSL.CASecret.Name; name != "" && (oldAstarte == nil || changed(name, oldAstarte.Spec.CFSSL.CASecret.Name)) {
+		fldPath := field.NewPath("spec").Child("cfssl").Child("caSecret")
+		if secret, err := r.getReferencedSecret(name, fldPath); err != nil {
+			allErrs = append(allErrs, err)
+		} else {
+			errs, w := validateTLSSecret(secret, fldPath)
+			allErrs = append(allErrs, errs...)
+			warnings = append(warnings, w...)
+		}
+	}
+
+	if len(warnings) == 0 {
+		return allErrs, nil
+	}
+	return allErrs, warnings
+}
+
+// getGenericConnection returns the connection to service, either rabbitmq or cassandra, if any
+func (r *Astarte) getGenericConnection(service string) *GenericConnectionSpec {
+	switch {
+	case service == "rabbitmq" && r.Spec.RabbitMQ.Connection != nil:
+		return &r.Spec.RabbitMQ.Connection.GenericConnectionSpec
+	case service == "cassandra" && r.Spec.Cassandra.Connection != nil:
+		return &r.Spec.Cassandra.Connection.GenericConnectionSpec
+	}
+	return nil
+}
+
+func (r *Astarte) getReferencedSecret(name string, fldPath *field.Path) (*v1.Secret, *field.Error) {
+	secret := &v1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: r.Namespace}, secret); err != nil {
+		astartelog.Info(err.Error())
+		if apierrors.IsNotFound(err) {
+			return nil, field.NotFound(fldPath, name)
+		}
+		return nil, field.InternalError(fldPath, err)
+	}
+	return secret, nil
+}
+
+// validateSecretKeys ensures secret holds all keys
+func validateSecretKeys(secret *v1.Secret, fldPath *field.Path, keys ...string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			err := fmt.Errorf("the Secret has no %s key", key)
+			astartelog.Info(err.Error(), "secret", secret.Name)
+			allErrs = append(allErrs, field.Invalid(fldPath, secret.Name, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateConnectionString ensures the given key of secret holds a connection string suitable for service.
+// Connection strings usually hold credentials, hence errors report the Secret rather than its content.
+func validateConnectionString(service string, secret *v1.Secret, fldPath *field.Path, key string) field.ErrorList {
+	if errs := validateSecretKeys(secret, fldPath, key); len(errs) > 0 {
+		return errs
+	}
+
+	var err error
+	switch service {
+	case "rabbitmq":
+		_, err = connstring.ParseAMQPURI(string(secret.Data[key]))
+	case "cassandra":
+		_, err = connstring.ParseCassandraContactPoints(string(secret.Data[key]))
+	}
+	if err != nil {
+		err = fmt.Errorf("the %s key of the Secret holds no valid connection string: %w", key, err)
+		astartelog.Info(err.Error(), "secret", secret.Name)
+		return field.ErrorList{field.Invalid(fldPath, secret.Name, err.Error())}
+	}
+
+	return nil
+}
+
+// validateTLSSecret ensures secret holds a certificate and its matching private key
+func validateTLSSecret(secret *v1.Secret, fldPath *field.Path) (field.ErrorList, admission.Warnings) {
+	if errs := validateSecretKeys(secret, fldPath, v1.TLSCertKey, v1.TLSPrivateKeyKey); len(errs) > 0 {
+		return errs, nil
+	}
+
+	if _, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]); err != nil {
+		err = fmt.Errorf("the Secret holds no valid certificate and private key pair: %w", err)
+		astartelog.Info(err.Error(), "secret", secret.Name)
+		return field.ErrorList{field.Invalid(fldPath, secret.Name, err.Error())}, nil
+	}
+
+	return validateSecretCertificates(secret, fldPath, v1.TLSCertKey)
+}
+
+// validateSecretCertificates ensures the key of secret holds PEM certificates, warning about the expired ones
+func validateSecretCertificates(secret *v1.Secret, fldPath *field.Path, key string) (field.ErrorList, admission.Warnings) {
+	if errs := validateSecretKeys(secret, fldPath, key); len(errs) > 0 {
+		return errs, nil
+	}
+
+	certificates := []*x509.Certificate{}
+	rest := secret.Data[key]
+	for {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			err = fmt.Errorf("the %s key of the Secret holds an invalid certificate: %w", key, err)
+			astartelog.Info(err.Error(), "secret", secret.Name)
+			return field.ErrorList{field.Invalid(fldPath, secret.Name, err.Error())}, nil
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		err := fmt.Errorf("the %s key of the Secret holds no PEM certificate", key)
+		astartelog.Info(err.Error(), "secret", secret.Name)
+		return field.ErrorList{field.Invalid(fldPath, secret.Name, err.Error())}, nil
+	}
+
+	warnings := admission.Warnings{}
+	now := time.Now()
+	for _, certificate := range certificates {
+		switch {
+		case now.After(certificate.NotAfter):
+			warnings = append(warnings, fmt.Sprintf("%s: the certificate %q in Secret %s expired on %s",
+				fldPath, certificate.Subject.CommonName, secret.Name, certificate.NotAfter.Format(time.RFC3339)))
+		case now.Add(certificateExpiryWarningThreshold).After(certificate.NotAfter):
+			warnings = append(warnings, fmt.Sprintf("%s: the certificate %q in Secret %s expires on %s",
+				fldPath, certificate.Subject.CommonName, secret.Name, certificate.NotAfter.Format(time.RFC3339)))
+		}
+	}
+
+	return nil, warnings
+}
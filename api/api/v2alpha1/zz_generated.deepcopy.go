@@ -24,7 +24,9 @@ package v2alpha1
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -34,7 +36,7 @@ func (in *Astarte) DeepCopyInto(out *Astarte) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Astarte.
@@ -260,6 +262,11 @@ func (in *AstarteCFSSLSpec) DeepCopyInto(out *AstarteCFSSLSpec) {
 		*out = new(AstarteCFSSLCARootConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PublishCABundle != nil {
+		in, out := &in.PublishCABundle, &out.PublishCABundle
+		*out = new(AstarteDeviceCABundleSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PodLabels != nil {
 		in, out := &in.PodLabels, &out.PodLabels
 		*out = make(map[string]string, len(*in))
@@ -327,6 +334,21 @@ func (in *AstarteCassandraConnectionSpec) DeepCopy() *AstarteCassandraConnection
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteCassandraDataCenterStatus) DeepCopyInto(out *AstarteCassandraDataCenterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteCassandraDataCenterStatus.
+func (in *AstarteCassandraDataCenterStatus) DeepCopy() *AstarteCassandraDataCenterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteCassandraDataCenterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteCassandraSpec) DeepCopyInto(out *AstarteCassandraSpec) {
 	*out = *in
@@ -336,6 +358,11 @@ func (in *AstarteCassandraSpec) DeepCopyInto(out *AstarteCassandraSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.AstarteSystemKeyspace = in.AstarteSystemKeyspace
+	if in.TopologyCheck != nil {
+		in, out := &in.TopologyCheck, &out.TopologyCheck
+		*out = new(AstarteCassandraTopologyCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteCassandraSpec.
@@ -348,6 +375,46 @@ func (in *AstarteCassandraSpec) DeepCopy() *AstarteCassandraSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteCassandraTopologyCheckSpec) DeepCopyInto(out *AstarteCassandraTopologyCheckSpec) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteCassandraTopologyCheckSpec.
+func (in *AstarteCassandraTopologyCheckSpec) DeepCopy() *AstarteCassandraTopologyCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteCassandraTopologyCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteCassandraTopologyStatus) DeepCopyInto(out *AstarteCassandraTopologyStatus) {
+	*out = *in
+	if in.DataCenters != nil {
+		in, out := &in.DataCenters, &out.DataCenters
+		*out = make([]AstarteCassandraDataCenterStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteCassandraTopologyStatus.
+func (in *AstarteCassandraTopologyStatus) DeepCopy() *AstarteCassandraTopologyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteCassandraTopologyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteComponentsSpec) DeepCopyInto(out *AstarteComponentsSpec) {
 	*out = *in
@@ -428,6 +495,76 @@ func (in *AstarteDashboardSpec) DeepCopy() *AstarteDashboardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDataUpdaterPlantQueueAutoscalerSpec) DeepCopyInto(out *AstarteDataUpdaterPlantQueueAutoscalerSpec) {
+	*out = *in
+	if in.MinShards != nil {
+		in, out := &in.MinShards, &out.MinShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetBacklogPerShard != nil {
+		in, out := &in.TargetBacklogPerShard, &out.TargetBacklogPerShard
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ScaleDownThresholdPercentage != nil {
+		in, out := &in.ScaleDownThresholdPercentage, &out.ScaleDownThresholdPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleUpCooldownSeconds != nil {
+		in, out := &in.ScaleUpCooldownSeconds, &out.ScaleUpCooldownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleDownCooldownSeconds != nil {
+		in, out := &in.ScaleDownCooldownSeconds, &out.ScaleDownCooldownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PollIntervalSeconds != nil {
+		in, out := &in.PollIntervalSeconds, &out.PollIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDataUpdaterPlantQueueAutoscalerSpec.
+func (in *AstarteDataUpdaterPlantQueueAutoscalerSpec) DeepCopy() *AstarteDataUpdaterPlantQueueAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDataUpdaterPlantQueueAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDataUpdaterPlantShardStatus) DeepCopyInto(out *AstarteDataUpdaterPlantShardStatus) {
+	*out = *in
+	if in.QueueRangeStart != nil {
+		in, out := &in.QueueRangeStart, &out.QueueRangeStart
+		*out = new(int32)
+		**out = **in
+	}
+	if in.QueueRangeEnd != nil {
+		in, out := &in.QueueRangeEnd, &out.QueueRangeEnd
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDataUpdaterPlantShardStatus.
+func (in *AstarteDataUpdaterPlantShardStatus) DeepCopy() *AstarteDataUpdaterPlantShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDataUpdaterPlantShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteDataUpdaterPlantSpec) DeepCopyInto(out *AstarteDataUpdaterPlantSpec) {
 	*out = *in
@@ -442,6 +579,11 @@ func (in *AstarteDataUpdaterPlantSpec) DeepCopyInto(out *AstarteDataUpdaterPlant
 		*out = new(int)
 		**out = **in
 	}
+	if in.QueueAutoscaler != nil {
+		in, out := &in.QueueAutoscaler, &out.QueueAutoscaler
+		*out = new(AstarteDataUpdaterPlantQueueAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDataUpdaterPlantSpec.
@@ -454,6 +596,64 @@ func (in *AstarteDataUpdaterPlantSpec) DeepCopy() *AstarteDataUpdaterPlantSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDataUpdaterPlantStatus) DeepCopyInto(out *AstarteDataUpdaterPlantStatus) {
+	*out = *in
+	if in.LastObservationTime != nil {
+		in, out := &in.LastObservationTime, &out.LastObservationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDataUpdaterPlantStatus.
+func (in *AstarteDataUpdaterPlantStatus) DeepCopy() *AstarteDataUpdaterPlantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDataUpdaterPlantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDeletionPolicySpec) DeepCopyInto(out *AstarteDeletionPolicySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDeletionPolicySpec.
+func (in *AstarteDeletionPolicySpec) DeepCopy() *AstarteDeletionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDeletionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDeviceCABundleSpec) DeepCopyInto(out *AstarteDeviceCABundleSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDeviceCABundleSpec.
+func (in *AstarteDeviceCABundleSpec) DeepCopy() *AstarteDeviceCABundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDeviceCABundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteFDOSpec) DeepCopyInto(out *AstarteFDOSpec) {
 	*out = *in
@@ -576,7 +776,7 @@ func (in *AstarteGenericClusteredResource) DeepCopyInto(out *AstarteGenericClust
 	if in.Autoscale != nil {
 		in, out := &in.Autoscale, &out.Autoscale
 		*out = new(AstarteGenericClusteredResourceAutoscalerSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.LivenessProbe != nil {
 		in, out := &in.LivenessProbe, &out.LivenessProbe
@@ -608,6 +808,31 @@ func (in *AstarteGenericClusteredResource) DeepCopy() *AstarteGenericClusteredRe
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteGenericClusteredResourceAutoscalerSpec) DeepCopyInto(out *AstarteGenericClusteredResourceAutoscalerSpec) {
 	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteGenericClusteredResourceAutoscalerSpec.
@@ -707,6 +932,26 @@ func (in *AstartePodPrioritiesSpec) DeepCopy() *AstartePodPrioritiesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstartePreflightSpec) DeepCopyInto(out *AstartePreflightSpec) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstartePreflightSpec.
+func (in *AstartePreflightSpec) DeepCopy() *AstartePreflightSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstartePreflightSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteRabbitMQConnectionSpec) DeepCopyInto(out *AstarteRabbitMQConnectionSpec) {
 	*out = *in
@@ -724,6 +969,51 @@ func (in *AstarteRabbitMQConnectionSpec) DeepCopy() *AstarteRabbitMQConnectionSp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteRabbitMQProvisioningSpec) DeepCopyInto(out *AstarteRabbitMQProvisioningSpec) {
+	*out = *in
+	if in.AdminCredentialsSecret != nil {
+		in, out := &in.AdminCredentialsSecret, &out.AdminCredentialsSecret
+		*out = new(LoginCredentialsSecret)
+		**out = **in
+	}
+	if in.DataQueuesPolicy != nil {
+		in, out := &in.DataQueuesPolicy, &out.DataQueuesPolicy
+		*out = new(AstarteRabbitMQQueuePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteRabbitMQProvisioningSpec.
+func (in *AstarteRabbitMQProvisioningSpec) DeepCopy() *AstarteRabbitMQProvisioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteRabbitMQProvisioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteRabbitMQQueuePolicySpec) DeepCopyInto(out *AstarteRabbitMQQueuePolicySpec) {
+	*out = *in
+	if in.HAParams != nil {
+		in, out := &in.HAParams, &out.HAParams
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteRabbitMQQueuePolicySpec.
+func (in *AstarteRabbitMQQueuePolicySpec) DeepCopy() *AstarteRabbitMQQueuePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteRabbitMQQueuePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteRabbitMQSpec) DeepCopyInto(out *AstarteRabbitMQSpec) {
 	*out = *in
@@ -732,6 +1022,11 @@ func (in *AstarteRabbitMQSpec) DeepCopyInto(out *AstarteRabbitMQSpec) {
 		*out = new(AstarteRabbitMQConnectionSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Provisioning != nil {
+		in, out := &in.Provisioning, &out.Provisioning
+		*out = new(AstarteRabbitMQProvisioningSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteRabbitMQSpec.
@@ -769,6 +1064,16 @@ func (in *AstarteSpec) DeepCopyInto(out *AstarteSpec) {
 	in.VerneMQ.DeepCopyInto(&out.VerneMQ)
 	in.CFSSL.DeepCopyInto(&out.CFSSL)
 	in.Components.DeepCopyInto(&out.Components)
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(AstarteDeletionPolicySpec)
+		**out = **in
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(AstartePreflightSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteSpec.
@@ -784,6 +1089,39 @@ func (in *AstarteSpec) DeepCopy() *AstarteSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteStatus) DeepCopyInto(out *AstarteStatus) {
 	*out = *in
+	if in.DeviceCAExpiry != nil {
+		in, out := &in.DeviceCAExpiry, &out.DeviceCAExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.DataUpdaterPlant != nil {
+		in, out := &in.DataUpdaterPlant, &out.DataUpdaterPlant
+		*out = new(AstarteDataUpdaterPlantStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataUpdaterPlantShards != nil {
+		in, out := &in.DataUpdaterPlantShards, &out.DataUpdaterPlantShards
+		*out = make([]AstarteDataUpdaterPlantShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VerneMQUpdate != nil {
+		in, out := &in.VerneMQUpdate, &out.VerneMQUpdate
+		*out = new(AstarteVerneMQUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CassandraTopology != nil {
+		in, out := &in.CassandraTopology, &out.CassandraTopology
+		*out = new(AstarteCassandraTopologyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteStatus.
@@ -827,6 +1165,21 @@ func (in *AstarteTriggerEngineSpec) DeepCopy() *AstarteTriggerEngineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteVerneMQListenerSpec) DeepCopyInto(out *AstarteVerneMQListenerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteVerneMQListenerSpec.
+func (in *AstarteVerneMQListenerSpec) DeepCopy() *AstarteVerneMQListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteVerneMQListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteVerneMQSpec) DeepCopyInto(out *AstarteVerneMQSpec) {
 	*out = *in
@@ -847,6 +1200,28 @@ func (in *AstarteVerneMQSpec) DeepCopyInto(out *AstarteVerneMQSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(AstarteVerneMQUpdateStrategySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]AstarteVerneMQListenerSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteVerneMQSpec.
@@ -859,6 +1234,65 @@ func (in *AstarteVerneMQSpec) DeepCopy() *AstarteVerneMQSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteVerneMQUpdateStatus) DeepCopyInto(out *AstarteVerneMQUpdateStatus) {
+	*out = *in
+	if in.Sessions != nil {
+		in, out := &in.Sessions, &out.Sessions
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SessionsBeforeLastStep != nil {
+		in, out := &in.SessionsBeforeLastStep, &out.SessionsBeforeLastStep
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastStepTime != nil {
+		in, out := &in.LastStepTime, &out.LastStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteVerneMQUpdateStatus.
+func (in *AstarteVerneMQUpdateStatus) DeepCopy() *AstarteVerneMQUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteVerneMQUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteVerneMQUpdateStrategySpec) DeepCopyInto(out *AstarteVerneMQUpdateStrategySpec) {
+	*out = *in
+	if in.DrainTimeoutSeconds != nil {
+		in, out := &in.DrainTimeoutSeconds, &out.DrainTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SessionRecoveryPercentage != nil {
+		in, out := &in.SessionRecoveryPercentage, &out.SessionRecoveryPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SessionRedistributionTimeoutSeconds != nil {
+		in, out := &in.SessionRedistributionTimeoutSeconds, &out.SessionRedistributionTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteVerneMQUpdateStrategySpec.
+func (in *AstarteVerneMQUpdateStrategySpec) DeepCopy() *AstarteVerneMQUpdateStrategySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteVerneMQUpdateStrategySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectionStringSecret) DeepCopyInto(out *ConnectionStringSecret) {
 	*out = *in
@@ -909,6 +1343,7 @@ func (in *GenericSSLConfigurationSpec) DeepCopyInto(out *GenericSSLConfiguration
 		*out = new(bool)
 		**out = **in
 	}
+	out.ClientCertSecret = in.ClientCertSecret
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericSSLConfigurationSpec.
@@ -0,0 +1,176 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// FlowStateUnknown represents an Unknown State of the Flow. When in this state, it might
+	// have never been reconciled.
+	FlowStateUnknown FlowState = ""
+	// FlowStateUnstable means the Flow is either reconciling or restarting some of its blocks.
+	// It usually transitions to this State before moving to Flowing.
+	FlowStateUnstable FlowState = "Unstable"
+	// FlowStateUnhealthy means the Flow is currently having some non-transient or unrecoverable errors.
+	// Manual intervention might be required.
+	FlowStateUnhealthy FlowState = "Unhealthy"
+	// FlowStateFlowing means the Flow is currently active and all of its blocks are stable. A healthy flow should stay
+	// in this state for most of its lifecycle.
+	FlowStateFlowing FlowState = "Flowing"
+)
+
+// FlowState describes the global state of a Flow
+type FlowState string
+
+// FlowSpec defines the desired state of Flow
+type FlowSpec struct {
+	Astarte      v1.LocalObjectReference `json:"astarte"`
+	AstarteRealm string                  `json:"astarteRealm"`
+	// Defines the amount of non-container blocks in the Flow
+	NativeBlocks int `json:"nativeBlocks"`
+	// Defines the overall resources consumed by Native Blocks
+	NativeBlocksResources v1.ResourceList `json:"nativeBlocksResources"`
+	// EE Only: Defines the Flow Pool in which the Flow will be allocated.
+	FlowPool        v1.LocalObjectReference `json:"flowPool,omitempty"`
+	ContainerBlocks []ContainerBlockSpec    `json:"blocks"`
+}
+
+// FlowStatus defines the observed state of Flow
+type FlowStatus struct {
+	// State defines the overall state of the Flow
+	State FlowState `json:"state"`
+	// Represents the total number of the Container Blocks in the Flow
+	TotalContainerBlocks int `json:"totalContainerBlocks"`
+	// Represents the total number of Ready Container Blocks in the Flow. In a healthy Flow,
+	// this matches the number of Total Container Blocks.
+	ReadyContainerBlocks int `json:"readyContainerBlocks"`
+	// The overall resources allocated in the cluster for this Block
+	Resources v1.ResourceList `json:"resources"`
+	// Represents the total number of Container Blocks with non temporary failures. Present only
+	// if any of the Blocks is in such state. When present, manual intervention is most likely required.
+	// +kubebuilder:validation:Optional
+	FailingContainerBlocks int `json:"failingContainerBlocks,omitempty"`
+	// UnrecoverableFailures lists all the ContainerStates of failing containers, for further inspection.
+	// +kubebuilder:validation:Optional
+	UnrecoverableFailures []v1.ContainerState `json:"unrecoverableFailures,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+
+// Flow is the Schema for the flows API
+type Flow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FlowSpec   `json:"spec,omitempty"`
+	Status FlowStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FlowList contains a list of Flow
+type FlowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Flow `json:"items"`
+}
+
+// RabbitMQConfig represents configuration for RabbitMQ
+type RabbitMQConfig struct {
+	Host string `json:"host"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Optional
+	SSL *bool `json:"ssl,omitempty"`
+	// The path of the CA certificate used to verify RabbitMQ, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLCAFile string `json:"sslCAFile,omitempty"`
+	// Disables Server Name Indication, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLDisableSNI *bool `json:"sslDisableSNI,omitempty"`
+	// The server name to send through SNI in place of the host, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLCustomSNI string `json:"sslCustomSNI,omitempty"`
+	// The path of the client certificate used to authenticate through mutual TLS
+	// +kubebuilder:validation:Optional
+	SSLCertFile string `json:"sslCertFile,omitempty"`
+	// The path of the key of the client certificate used to authenticate through mutual TLS
+	// +kubebuilder:validation:Optional
+	SSLKeyFile string `json:"sslKeyFile,omitempty"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+}
+
+// RabbitMQExchange is a representation of a RabbitMQ Exchange
+type RabbitMQExchange struct {
+	Name       string `json:"name"`
+	RoutingKey string `json:"routingKey"`
+}
+
+// RabbitMQDataProvider is a representation of a Data Provider based upon RabbitMQ
+type RabbitMQDataProvider struct {
+	// +kubebuilder:validation:Optional
+	Queues []string `json:"queues,omitempty"`
+	// +kubebuilder:validation:Optional
+	Exchange *RabbitMQExchange `json:"exchange,omitempty"`
+	// RabbitMQConfig is an optional field which allows to specify configuration for an external RabbitMQ
+	// broker. If not specified, Astarte's main Broker will be used.
+	// +kubebuilder:validation:Optional
+	RabbitMQConfig *RabbitMQConfig `json:"rabbitmq,omitempty"`
+}
+
+// DataProvider is a struct which defines which Data Providers (e.g. Brokers) are available for a
+// Worker
+type DataProvider struct {
+	// +kubebuilder:validation:Optional
+	RabbitMQ *RabbitMQDataProvider `json:"rabbitmq,omitempty"`
+}
+
+// BlockWorker defines a Worker for a Container Block
+type BlockWorker struct {
+	WorkerID     string       `json:"id"`
+	DataProvider DataProvider `json:"dataProvider"`
+}
+
+// ContainerBlockSpec defines a Container Block in a Flow
+type ContainerBlockSpec struct {
+	BlockID string `json:"id"`
+	Image   string `json:"image"`
+	// +kubebuilder:validation:Optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// +kubebuilder:validation:Optional
+	Environment []v1.EnvVar `json:"environment"`
+	// +kubebuilder:validation:Optional
+	Resources v1.ResourceRequirements `json:"resources"`
+	// Configuration represents the JSON string carrying the user configuration for this block
+	Configuration string `json:"config"`
+	// +kubebuilder:validation:MinItems:=1
+	Workers []BlockWorker `json:"workers"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Flow{}, &FlowList{})
+}
@@ -0,0 +1,62 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v1beta1"
+)
+
+var _ conversion.Convertible = &Flow{}
+
+// ConvertTo converts this Flow to the Hub version (v1beta1)
+func (src *Flow) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Flow)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// The two versions share the same JSON representation, except for the TypeMeta
+	// embedded in nested types, which is dropped in v1beta1
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	return convertJSON(&src.Status, &dst.Status)
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version
+func (dst *Flow) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Flow)
+	dst.ObjectMeta = src.ObjectMeta
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	return convertJSON(&src.Status, &dst.Status)
+}
+
+// convertJSON copies src into dst through their JSON representation. Fields unknown to dst are dropped.
+func convertJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,79 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v1beta1"
+)
+
+var _ = Describe("Flow conversion", func() {
+	var flow *Flow
+
+	BeforeEach(func() {
+		flow = &Flow{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-flow", Namespace: "default"},
+			Spec: FlowSpec{
+				Astarte:               v1.LocalObjectReference{Name: "example-astarte"},
+				AstarteRealm:          "test",
+				NativeBlocks:          1,
+				NativeBlocksResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				ContainerBlocks: []ContainerBlockSpec{{
+					BlockID: "block",
+					Image:   "example/block:1.0",
+					Workers: []BlockWorker{{
+						WorkerID: "worker",
+						DataProvider: DataProvider{RabbitMQ: &RabbitMQDataProvider{
+							Queues:   []string{"queue"},
+							Exchange: &RabbitMQExchange{Name: "exchange", RoutingKey: "key"},
+						}},
+					}},
+				}},
+			},
+			Status: FlowStatus{State: FlowStateFlowing, TotalContainerBlocks: 1, ReadyContainerBlocks: 1},
+		}
+	})
+
+	It("should convert to v1beta1 and back without losing data", func() {
+		hub := &v1beta1.Flow{}
+		Expect(flow.ConvertTo(hub)).To(Succeed())
+		Expect(hub.ObjectMeta).To(Equal(flow.ObjectMeta))
+		Expect(hub.Spec.ContainerBlocks[0].Workers[0].DataProvider.RabbitMQ.Exchange.Name).To(Equal("exchange"))
+
+		converted := &Flow{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(equality.Semantic.DeepEqual(converted, flow)).To(BeTrue())
+	})
+
+	It("should drop the TypeMeta embedded in nested types", func() {
+		flow.Spec.TypeMeta = metav1.TypeMeta{Kind: "FlowSpec"}
+
+		hub := &v1beta1.Flow{}
+		Expect(flow.ConvertTo(hub)).To(Succeed())
+		converted := &Flow{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec.TypeMeta).To(Equal(metav1.TypeMeta{}))
+	})
+})
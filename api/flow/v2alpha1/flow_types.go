@@ -106,9 +106,24 @@ type RabbitMQConfig struct {
 	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
 	// +kubebuilder:validation:Optional
-	SSL      *bool  `json:"ssl,omitempty"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	SSL *bool `json:"ssl,omitempty"`
+	// The path of the CA certificate used to verify RabbitMQ, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLCAFile string `json:"sslCAFile,omitempty"`
+	// Disables Server Name Indication, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLDisableSNI *bool `json:"sslDisableSNI,omitempty"`
+	// The server name to send through SNI in place of the host, when SSL is enabled
+	// +kubebuilder:validation:Optional
+	SSLCustomSNI string `json:"sslCustomSNI,omitempty"`
+	// The path of the client certificate used to authenticate through mutual TLS
+	// +kubebuilder:validation:Optional
+	SSLCertFile string `json:"sslCertFile,omitempty"`
+	// The path of the key of the client certificate used to authenticate through mutual TLS
+	// +kubebuilder:validation:Optional
+	SSLKeyFile string `json:"sslKeyFile,omitempty"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
 }
 
 // RabbitMQExchange is a representation of a RabbitMQ Exchange
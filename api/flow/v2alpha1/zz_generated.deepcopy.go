@@ -231,6 +231,11 @@ func (in *RabbitMQConfig) DeepCopyInto(out *RabbitMQConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SSLDisableSNI != nil {
+		in, out := &in.SSLDisableSNI, &out.SSLDisableSNI
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitMQConfig.
@@ -0,0 +1,70 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v1beta1"
+)
+
+var _ = Describe("AstarteDefaultIngress conversion", func() {
+	var adi *AstarteDefaultIngress
+
+	BeforeEach(func() {
+		adi = &AstarteDefaultIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "adi", Namespace: "default"},
+			Spec: AstarteDefaultIngressSpec{
+				Astarte:      "example-astarte",
+				IngressClass: "nginx",
+				API:          AstarteDefaultIngressAPISpec{Deploy: pointy.Bool(true), Cors: pointy.Bool(true)},
+				Dashboard:    AstarteDefaultIngressDashboardSpec{Deploy: pointy.Bool(true), Host: "dashboard.example.com"},
+				Broker:       AstarteDefaultIngressBrokerSpec{Deploy: pointy.Bool(true), ServiceType: v1.ServiceTypeLoadBalancer},
+			},
+			Status: AstarteDefaultIngressStatus{
+				BrokerStatus: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "10.0.0.1"}}}},
+			},
+		}
+	})
+
+	It("should convert to v1beta1 and back without losing data", func() {
+		hub := &v1beta1.AstarteDefaultIngress{}
+		Expect(adi.ConvertTo(hub)).To(Succeed())
+		Expect(hub.ObjectMeta).To(Equal(adi.ObjectMeta))
+		Expect(hub.Spec.Dashboard.Host).To(Equal("dashboard.example.com"))
+
+		converted := &AstarteDefaultIngress{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted).To(Equal(adi))
+	})
+
+	It("should drop the TypeMeta embedded in nested types", func() {
+		adi.Spec.API.TypeMeta = metav1.TypeMeta{Kind: "AstarteDefaultIngressAPISpec"}
+
+		hub := &v1beta1.AstarteDefaultIngress{}
+		Expect(adi.ConvertTo(hub)).To(Succeed())
+		converted := &AstarteDefaultIngress{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec.API.TypeMeta).To(Equal(metav1.TypeMeta{}))
+	})
+})
@@ -157,6 +157,39 @@ type AstarteDefaultIngressBrokerSpec struct {
 	// Additional annotations for the service exposing this broker.
 	// +optional
 	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+	// Configure the load balancer to send the PROXY protocol header to VerneMQ, so that the real IP of
+	// devices is preserved. The broker service then targets the VerneMQ tcp listener expecting the header,
+	// hence the load balancer must terminate TLS. It must match the proxyProtocol setting of VerneMQ in the
+	// main Astarte resource.
+	// +optional
+	ProxyProtocol *AstarteDefaultIngressBrokerProxyProtocolSpec `json:"proxyProtocol,omitempty"`
+}
+
+// AstarteDefaultIngressBrokerProxyProtocolSpec defines how the PROXY protocol is enabled on the broker load balancer.
+type AstarteDefaultIngressBrokerProxyProtocolSpec struct {
+	// When true, the load balancer exposing the broker sends the PROXY protocol header.
+	Enabled bool `json:"enabled"`
+	// The cloud provider of the load balancer. When set, the Service annotations enabling the PROXY protocol
+	// on that provider are added to the broker service; serviceAnnotations take precedence over them. When
+	// not set, the load balancer must be configured by other means, e.g. through serviceAnnotations.
+	// +kubebuilder:validation:Enum:=aws;digitalocean;hetzner;scaleway
+	// +optional
+	Provider AstarteDefaultIngressLoadBalancerProvider `json:"provider,omitempty"`
+}
+
+// AstarteDefaultIngressLoadBalancerProvider identifies the cloud provider of a load balancer
+type AstarteDefaultIngressLoadBalancerProvider string
+
+const (
+	AWSLoadBalancerProvider          AstarteDefaultIngressLoadBalancerProvider = "aws"
+	DigitalOceanLoadBalancerProvider AstarteDefaultIngressLoadBalancerProvider = "digitalocean"
+	HetznerLoadBalancerProvider      AstarteDefaultIngressLoadBalancerProvider = "hetzner"
+	ScalewayLoadBalancerProvider     AstarteDefaultIngressLoadBalancerProvider = "scaleway"
+)
+
+// IsEnabled returns whether the PROXY protocol is enabled
+func (s *AstarteDefaultIngressBrokerProxyProtocolSpec) IsEnabled() bool {
+	return s != nil && s.Enabled
 }
 
 // useHAProxyIngressController checks if the selector annotation in the ADI
@@ -20,6 +20,7 @@ package v2alpha1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.openly.dev/pointy"
@@ -75,7 +76,7 @@ func (r *AstarteDefaultIngress) Default() {
 	}
 }
 
-// +kubebuilder:webhook:path=/validate-ingress-astarte-platform-org-v2alpha1-astartedefaultingress,mutating=false,failurePolicy=fail,sideEffects=None,groups=ingress.astarte-platform.org,resources=astartedefaultingresses,verbs=create;update,versions=v2alpha1,name=vastartedefaultingress.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-ingress-astarte-platform-org-v2alpha1-astartedefaultingress,mutating=false,failurePolicy=fail,sideEffects=None,groups=ingress.astarte-platform.org,resources=astartedefaultingresses,verbs=create;update;delete,versions=v2alpha1,name=vastartedefaultingress.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &AstarteDefaultIngress{}
 
@@ -97,8 +98,27 @@ func (r *AstarteDefaultIngress) ValidateUpdate(old runtime.Object) (admission.Wa
 func (r *AstarteDefaultIngress) ValidateDelete() (admission.Warnings, error) {
 	astartedefaultingresslog.Info("validate delete", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object deletion.
-	return nil, nil
+	// When the referenced Astarte is gone, there's nothing left to protect
+	theAstarte := &apiv2alpha1.Astarte{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: r.Spec.Astarte, Namespace: r.Namespace}, theAstarte); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return nil, r.validateDeletionProtection(theAstarte)
+}
+
+// validateDeletionProtection rejects the deletion of ingresses exposing an Astarte protected from deletion
+func (r *AstarteDefaultIngress) validateDeletionProtection(astarte *apiv2alpha1.Astarte) error {
+	if !astarte.IsDeletionProtected() {
+		return nil
+	}
+
+	err := fmt.Errorf("deletion of AstarteDefaultIngress %s is forbidden as Astarte %s is protected from deletion", r.Name, astarte.Name)
+	astartedefaultingresslog.Info(err.Error())
+	return apierrors.NewForbidden(schema.GroupResource{Group: "ingress.astarte-platform.org", Resource: "astartedefaultingresses"}, r.Name, err)
 }
 
 func (r *AstarteDefaultIngress) validateAstarteDefaultIngress() error {
@@ -116,6 +136,7 @@ func (r *AstarteDefaultIngress) validateAstarteDefaultIngress() error {
 		if err := r.validateAPITLSConfig(astarte); err != nil {
 			allErrors = append(allErrors, err)
 		}
+		allErrors = append(allErrors, r.validateBrokerProxyProtocol(astarte)...)
 	}
 	if err := r.validateDashboardTLSConfig(); err != nil {
 		allErrors = append(allErrors, err)
@@ -160,6 +181,32 @@ func (r *AstarteDefaultIngress) validateBrokerTLSConfig(astarte *apiv2alpha1.Ast
 	return nil
 }
 
+// validateBrokerProxyProtocol ensures the load balancer and VerneMQ agree on the PROXY protocol,
+// as a mismatch makes every device connection fail
+func (r *AstarteDefaultIngress) validateBrokerProxyProtocol(astarte *apiv2alpha1.Astarte) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !pointy.BoolValue(r.Spec.Broker.Deploy, true) {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec").Child("broker")
+	enabled := r.Spec.Broker.ProxyProtocol.IsEnabled()
+	if enabled != pointy.BoolValue(astarte.Spec.VerneMQ.ProxyProtocol, false) {
+		err := fmt.Errorf("must match spec.vernemq.proxyProtocol in Astarte %s", astarte.Name)
+		astartedefaultingresslog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("proxyProtocol").Child("enabled"), enabled, err.Error()))
+	}
+
+	// NodePort services are reached directly, with nothing in between sending the header
+	if enabled && r.Spec.Broker.ServiceType == v1.ServiceTypeNodePort {
+		err := errors.New("the PROXY protocol requires the broker to be exposed by a LoadBalancer service")
+		astartedefaultingresslog.Info(err.Error())
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("serviceType"), r.Spec.Broker.ServiceType, err.Error()))
+	}
+
+	return allErrs
+}
+
 func (r *AstarteDefaultIngress) validateDashboardTLSConfig() *field.Error {
 	if r.Spec.TLSSecret == "" && pointy.BoolValue(r.Spec.Dashboard.SSL, true) &&
 		pointy.BoolValue(r.Spec.Dashboard.Deploy, true) && r.Spec.Dashboard.TLSSecret == "" {
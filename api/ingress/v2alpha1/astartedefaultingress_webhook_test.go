@@ -20,6 +20,13 @@ package v2alpha1
 
 import (
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 )
 
 var _ = Describe("AstarteDefaultIngress Webhook", func() {
@@ -46,4 +53,83 @@ var _ = Describe("AstarteDefaultIngress Webhook", func() {
 		})
 	})
 
+	Context("When validating the broker PROXY protocol", func() {
+		var adi *AstarteDefaultIngress
+		var astarte *apiv2alpha1.Astarte
+
+		BeforeEach(func() {
+			adi = &AstarteDefaultIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "adi", Namespace: "default"},
+				Spec: AstarteDefaultIngressSpec{
+					Astarte: "example-astarte",
+					Broker:  AstarteDefaultIngressBrokerSpec{ServiceType: v1.ServiceTypeLoadBalancer},
+				},
+			}
+			astarte = &apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{Name: "example-astarte", Namespace: "default"}}
+		})
+
+		It("Should admit matching settings", func() {
+			Expect(adi.validateBrokerProxyProtocol(astarte)).To(BeEmpty())
+
+			adi.Spec.Broker.ProxyProtocol = &AstarteDefaultIngressBrokerProxyProtocolSpec{Enabled: true, Provider: AWSLoadBalancerProvider}
+			astarte.Spec.VerneMQ.ProxyProtocol = pointy.Bool(true)
+			Expect(adi.validateBrokerProxyProtocol(astarte)).To(BeEmpty())
+		})
+
+		It("Should deny settings disagreeing with VerneMQ", func() {
+			adi.Spec.Broker.ProxyProtocol = &AstarteDefaultIngressBrokerProxyProtocolSpec{Enabled: true}
+			errs := adi.validateBrokerProxyProtocol(astarte)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.broker.proxyProtocol.enabled"))
+
+			adi.Spec.Broker.ProxyProtocol = nil
+			astarte.Spec.VerneMQ.ProxyProtocol = pointy.Bool(true)
+			errs = adi.validateBrokerProxyProtocol(astarte)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.broker.proxyProtocol.enabled"))
+		})
+
+		It("Should deny the PROXY protocol on NodePort services", func() {
+			adi.Spec.Broker.ServiceType = v1.ServiceTypeNodePort
+			adi.Spec.Broker.ProxyProtocol = &AstarteDefaultIngressBrokerProxyProtocolSpec{Enabled: true}
+			astarte.Spec.VerneMQ.ProxyProtocol = pointy.Bool(true)
+			errs := adi.validateBrokerProxyProtocol(astarte)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.broker.serviceType"))
+		})
+
+		It("Should skip the check when the broker is not deployed", func() {
+			adi.Spec.Broker.Deploy = pointy.Bool(false)
+			astarte.Spec.VerneMQ.ProxyProtocol = pointy.Bool(true)
+			Expect(adi.validateBrokerProxyProtocol(astarte)).To(BeEmpty())
+		})
+	})
+
+	Context("When deleting AstarteDefaultIngress under Validating Webhook", func() {
+		var adi *AstarteDefaultIngress
+		var astarte *apiv2alpha1.Astarte
+
+		BeforeEach(func() {
+			adi = &AstarteDefaultIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "adi", Namespace: "default"},
+				Spec:       AstarteDefaultIngressSpec{Astarte: "example-astarte"},
+			}
+			astarte = &apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{Name: "example-astarte", Namespace: "default"}}
+		})
+
+		It("Should admit the deletion when Astarte is not protected", func() {
+			Expect(adi.validateDeletionProtection(astarte)).To(Succeed())
+		})
+
+		It("Should deny the deletion when Astarte is protected", func() {
+			astarte.Spec.DeletionProtection = true
+			err := adi.validateDeletionProtection(astarte)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+			astarte.Spec.DeletionProtection = false
+			astarte.SetAnnotations(map[string]string{apiv2alpha1.AnnotationDeletionProtection: "true"})
+			err = adi.validateDeletionProtection(astarte)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+	})
 })
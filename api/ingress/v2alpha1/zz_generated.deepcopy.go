@@ -84,6 +84,21 @@ func (in *AstarteDefaultIngressAPISpec) DeepCopy() *AstarteDefaultIngressAPISpec
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AstarteDefaultIngressBrokerProxyProtocolSpec) DeepCopyInto(out *AstarteDefaultIngressBrokerProxyProtocolSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDefaultIngressBrokerProxyProtocolSpec.
+func (in *AstarteDefaultIngressBrokerProxyProtocolSpec) DeepCopy() *AstarteDefaultIngressBrokerProxyProtocolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AstarteDefaultIngressBrokerProxyProtocolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AstarteDefaultIngressBrokerSpec) DeepCopyInto(out *AstarteDefaultIngressBrokerSpec) {
 	*out = *in
@@ -100,6 +115,11 @@ func (in *AstarteDefaultIngressBrokerSpec) DeepCopyInto(out *AstarteDefaultIngre
 			(*out)[key] = val
 		}
 	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(AstarteDefaultIngressBrokerProxyProtocolSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AstarteDefaultIngressBrokerSpec.
@@ -61,3 +61,51 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Restrict the webhooks to the namespaces and the objects the Operator manages, as other Operators may
+manage the rest. The label selector is turned into match expressions, e.g. "team=iot,tier in (a,b),!legacy".
+*/}}
+{{- define "astarte-operator.webhookSelectors" -}}
+{{- $namespaces := .Values.watchNamespaces | default (dig "cache" "watchNamespaces" list .Values.operatorConfig) }}
+{{- $selector := .Values.managedLabelSelector | default (dig "cache" "managedLabelSelector" "" .Values.operatorConfig) }}
+{{- if $namespaces }}
+namespaceSelector:
+  matchExpressions:
+  - key: kubernetes.io/metadata.name
+    operator: In
+    values:
+    {{- toYaml $namespaces | nindent 4 }}
+{{- end }}
+{{- if $selector }}
+objectSelector:
+  matchExpressions:
+  {{- range regexFindAll "[^,(]+(\\([^)]*\\))?" $selector -1 }}
+  {{- $term := trim . }}
+  {{- if regexMatch "^\\S+\\s+(in|notin)\\s*\\(" $term }}
+  - key: {{ regexFind "^\\S+" $term }}
+    operator: {{ ternary "In" "NotIn" (regexMatch "^\\S+\\s+in\\s*\\(" $term) }}
+    values:
+    {{- range splitList "," (regexReplaceAll "^[^(]*\\((.*)\\)$" $term "${1}") }}
+    - {{ trim . | quote }}
+    {{- end }}
+  {{- else if hasPrefix "!" $term }}
+  - key: {{ trimPrefix "!" $term | trim }}
+    operator: DoesNotExist
+  {{- else if contains "!=" $term }}
+  - key: {{ regexReplaceAll "\\s*!=.*$" $term "" }}
+    operator: NotIn
+    values:
+    - {{ regexReplaceAll "^.*!=\\s*" $term "" | quote }}
+  {{- else if contains "=" $term }}
+  - key: {{ regexReplaceAll "\\s*==?.*$" $term "" }}
+    operator: In
+    values:
+    - {{ regexReplaceAll "^[^=]*==?\\s*" $term "" | quote }}
+  {{- else }}
+  - key: {{ $term }}
+    operator: Exists
+  {{- end }}
+  {{- end }}
+{{- end }}
+{{- end }}
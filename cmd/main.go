@@ -22,27 +22,41 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	apiv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v1beta1"
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	flowv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v1beta1"
 	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+	ingressv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v1beta1"
 	ingressv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/casigner"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/config"
 	apicontroller "github.com/astarte-platform/astarte-kubernetes-operator/internal/controller/api"
 	flowcontroller "github.com/astarte-platform/astarte-kubernetes-operator/internal/controller/flow"
 	ingresscontroller "github.com/astarte-platform/astarte-kubernetes-operator/internal/controller/ingress"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/migration"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/scope"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -57,6 +71,9 @@ func init() {
 	utilruntime.Must(ingressv2alpha1.AddToScheme(scheme))
 	utilruntime.Must(apiv2alpha1.AddToScheme(scheme))
 	utilruntime.Must(flowv2alpha1.AddToScheme(scheme))
+	utilruntime.Must(ingressv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apiv1beta1.AddToScheme(scheme))
+	utilruntime.Must(flowv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -66,6 +83,11 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var caSignerAddr string
+	var caSignerURL string
+	var watchNamespaces string
+	var managedLabelSelector string
+	var configFile string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -77,6 +99,16 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&caSignerAddr, "ca-signer-bind-address", "0", "The address the device CA signer binds to. "+
+		"Leave as 0 to disable the device CA signer, which is required by the \"operator\" device CA backend.")
+	flag.StringVar(&caSignerURL, "ca-signer-url", "", "The base URL the device CA signer is reachable at from within "+
+		"the cluster, e.g. http://astarte-operator-ca-signer-service.astarte-operator.svc.cluster.local:8090.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", os.Getenv("WATCH_NAMESPACE"), "A comma-separated list of "+
+		"namespaces the Operator watches. Leave empty to watch all namespaces.")
+	flag.StringVar(&managedLabelSelector, "managed-label-selector", "", "A label selector restricting the Astarte, "+
+		"Flow and AstarteDefaultIngress objects managed by the Operator, e.g. team=iot. Leave empty to manage all of them.")
+	flag.StringVar(&configFile, "config", "", "The path of the Operator configuration file. "+
+		"Command line flags take precedence over the settings in the file.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -85,6 +117,37 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	operatorConfig := &config.OperatorConfiguration{}
+	if configFile != "" {
+		var err error
+		if operatorConfig, err = config.Load(configFile); err != nil {
+			setupLog.Error(err, "unable to load the Operator configuration", "config", configFile)
+			os.Exit(1)
+		}
+		setFlags := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		if !setFlags["leader-elect"] && operatorConfig.LeaderElection.Enable != nil {
+			enableLeaderElection = *operatorConfig.LeaderElection.Enable
+		}
+		if watchNamespaces == "" {
+			watchNamespaces = strings.Join(operatorConfig.Cache.WatchNamespaces, ",")
+		}
+		if managedLabelSelector == "" {
+			managedLabelSelector = operatorConfig.Cache.ManagedLabelSelector
+		}
+	}
+
+	operatorScope, err := scope.Parse(watchNamespaces, managedLabelSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid --managed-label-selector")
+		os.Exit(1)
+	}
+	scope.Set(operatorScope)
+	if operatorScope.IsRestricted() {
+		setupLog.Info("restricting the Operator scope", "namespaces", operatorScope.Namespaces,
+			"selector", operatorScope.Selector.String())
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -128,13 +191,27 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	// Secrets and ConfigMaps are read straight from the API server: caching them would mean keeping all
+	// of them in memory, while the controllers only watch their metadata.
+	clientOptions := client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&v1.Secret{}, &v1.ConfigMap{}}}}
+
+	cacheOptions := operatorScope.CacheOptions()
+	if operatorConfig.SyncPeriod != nil {
+		cacheOptions.SyncPeriod = &operatorConfig.SyncPeriod.Duration
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
+		Client:                 clientOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "30c4295b.astarte-platform.org",
+		LeaseDuration:          durationOrNil(operatorConfig.LeaderElection.LeaseDuration),
+		RenewDeadline:          durationOrNil(operatorConfig.LeaderElection.RenewDeadline),
+		RetryPeriod:            durationOrNil(operatorConfig.LeaderElection.RetryPeriod),
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -157,6 +234,7 @@ func main() {
 		Log:      ctrl.Log.WithName("controllers").WithName("Astarte"),
 		Recorder: mgr.GetEventRecorderFor("astarte-controller"),
 		Scheme:   mgr.GetScheme(),
+		Config:   operatorConfig.Controllers.Astarte,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Astarte")
 		os.Exit(1)
@@ -164,6 +242,7 @@ func main() {
 	if err = (&flowcontroller.FlowReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Config: operatorConfig.Controllers.Flow,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Flow")
 		os.Exit(1)
@@ -172,26 +251,52 @@ func main() {
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("ingress").WithName("AstarteDefaultIngress"),
 		Scheme: mgr.GetScheme(),
+		Config: operatorConfig.Controllers.AstarteDefaultIngress,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AstarteDefaultIngress")
 		os.Exit(1)
 	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-		if err = (&apiv2alpha1.Astarte{}).SetupWebhookWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create webhook", "webhook", "Astarte")
+		// The CRDs rely on the conversion webhook to serve all of their versions, hence it is not
+		// affected by the Webhooks feature gate.
+		mgr.GetWebhookServer().Register("/convert", conversion.NewWebhookHandler(mgr.GetScheme()))
+
+		if operatorConfig.IsEnabled(config.WebhooksFeatureGate) {
+			if err = (&apiv2alpha1.Astarte{}).SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "Astarte")
+				os.Exit(1)
+			}
+			if err = (&flowv2alpha1.Flow{}).SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "Flow")
+				os.Exit(1)
+			}
+			if err = (&ingressv2alpha1.AstarteDefaultIngress{}).SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "AstarteDefaultIngress")
+				os.Exit(1)
+			}
+		}
+	}
+	// +kubebuilder:scaffold:builder
+
+	if caSignerAddr != "0" {
+		if caSignerURL == "" {
+			setupLog.Error(nil, "--ca-signer-url is required when the device CA signer is enabled")
 			os.Exit(1)
 		}
-		if err = (&flowv2alpha1.Flow{}).SetupWebhookWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create webhook", "webhook", "Flow")
+		reconcile.SetOperatorCASignerURL(caSignerURL)
+		if err := mgr.Add(&casigner.Server{Client: mgr.GetClient(), BindAddress: caSignerAddr}); err != nil {
+			setupLog.Error(err, "unable to set up device CA signer")
 			os.Exit(1)
 		}
-		if err = (&ingressv2alpha1.AstarteDefaultIngress{}).SetupWebhookWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create webhook", "webhook", "AstarteDefaultIngress")
+	}
+
+	if operatorConfig.IsEnabled(config.StorageVersionMigrationFeatureGate) {
+		if err := mgr.Add(&migration.StorageVersionMigrator{Client: mgr.GetClient(), Scope: operatorScope}); err != nil {
+			setupLog.Error(err, "unable to set up storage version migration")
 			os.Exit(1)
 		}
 	}
-	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -208,3 +313,11 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// durationOrNil returns the duration d holds, if any
+func durationOrNil(d *metav1.Duration) *time.Duration {
+	if d == nil {
+		return nil
+	}
+	return &d.Duration
+}
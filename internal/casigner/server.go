@@ -0,0 +1,193 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package casigner implements the Operator's device CA signer, which exposes the same CFSSL-compatible
+// HTTP API Pairing and VerneMQ use, for all Astarte instances using the "operator" device CA backend.
+package casigner
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cfssl/api"
+	"github.com/cloudflare/cfssl/api/health"
+	"github.com/cloudflare/cfssl/api/info"
+	"github.com/cloudflare/cfssl/api/signhandler"
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/cloudflare/cfssl/signer"
+	"github.com/cloudflare/cfssl/signer/local"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
+)
+
+var log = logf.Log.WithName("ca_signer")
+
+// Server serves the CFSSL-compatible API for all Astarte instances using the "operator" device CA backend.
+// Requests are routed by path, i.e. /<namespace>/<name>/<token>/api/v1/cfssl/<endpoint>, which is why the
+// URL handed out to Astarte components embeds the namespace and the name of the instance. The token, derived
+// from the devices CA of the instance, makes sure callers can only have certificates signed by their own CA.
+type Server struct {
+	Client      client.Client
+	BindAddress string
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica of the Operator
+// serves signing requests, as they only depend on the CA Secret.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, and blocks until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("Starting device CA signer", "address", s.BindAddress)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Handler returns the http.Handler serving the CFSSL-compatible API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{namespace}/{name}/{token}/api/v1/cfssl/{endpoint}", s.serveCFSSLEndpoint)
+	return mux
+}
+
+func (s *Server) serveCFSSLEndpoint(w http.ResponseWriter, r *http.Request) {
+	cr := &apiv2alpha1.Astarte{}
+	nn := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	if err := s.Client.Get(r.Context(), nn, cr); err != nil {
+		if kerrors.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, fmt.Sprintf("Astarte %s not found", nn))
+			return
+		}
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Do not sign on behalf of instances which did not opt in
+	if cr.Spec.CFSSL.Backend != apiv2alpha1.DeviceCABackendOperator {
+		sendError(w, http.StatusNotFound, fmt.Sprintf("Astarte %s does not use the operator device CA backend", nn))
+		return
+	}
+
+	caSecret := &v1.Secret{}
+	if err := s.Client.Get(r.Context(), types.NamespacedName{Namespace: cr.Namespace, Name: reconcile.GetDeviceCASecretName(cr)}, caSecret); err != nil {
+		log.Error(err, "Could not get the devices CA", "astarte", nn)
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Without a key, the token would be known to anyone
+	if len(caSecret.Data[v1.TLSPrivateKeyKey]) == 0 {
+		sendError(w, http.StatusInternalServerError, fmt.Sprintf("the devices CA of Astarte %s has no key", nn))
+		return
+	}
+
+	// Only the components of the instance are handed its token
+	token := reconcile.GetDeviceCASignerToken(cr, caSecret.Data[v1.TLSPrivateKeyKey])
+	if subtle.ConstantTimeCompare([]byte(r.PathValue("token")), []byte(token)) != 1 {
+		sendError(w, http.StatusForbidden, fmt.Sprintf("invalid token for Astarte %s", nn))
+		return
+	}
+
+	endpoint := r.PathValue("endpoint")
+	if endpoint == "health" {
+		health.NewHealthCheck().ServeHTTP(w, r)
+		return
+	}
+
+	sgn, err := getSignerFor(cr, caSecret)
+	if err != nil {
+		log.Error(err, "Could not build the device CA signer", "astarte", nn)
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var h http.Handler
+	switch endpoint {
+	case "sign":
+		h, err = signhandler.NewHandlerFromSigner(sgn)
+	case "info":
+		h, err = info.NewHandler(sgn)
+	default:
+		sendError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint %s", endpoint))
+		return
+	}
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+func getSignerFor(cr *apiv2alpha1.Astarte, caSecret *v1.Secret) (signer.Signer, error) {
+	caCert, err := helpers.ParseCertificatePEM(caSecret.Data[v1.TLSCertKey])
+	if err != nil {
+		return nil, err
+	}
+	caKey, err := helpers.ParsePrivateKeyPEM(caSecret.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := reconcile.GetDeviceCASigningPolicy(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	return local.NewSigner(caKey, caCert, signer.DefaultSigAlgo(caKey), policy)
+}
+
+func sendError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(api.NewErrorResponse(message, code)); err != nil {
+		log.Error(err, "Could not send error response")
+	}
+}
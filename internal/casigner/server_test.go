@@ -0,0 +1,150 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package casigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	cfsslcsr "github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("CA Signer testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "casigner-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+	var server *httptest.Server
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		reconcile.SetOperatorCASignerURL("http://ca-signer.astarte-operator.svc.cluster.local:8090")
+		server = httptest.NewServer((&Server{Client: k8sClient}).Handler())
+	})
+
+	AfterAll(func() {
+		server.Close()
+		reconcile.SetOperatorCASignerURL("")
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendOperator
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	getBaseURL := func(token string) string {
+		return server.URL + "/" + CustomAstarteNamespace + "/" + CustomAstarteName + "/" + token
+	}
+
+	getToken := func() string {
+		tokenSecret := &v1.Secret{}
+		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: reconcile.GetDeviceCASignerTokenSecretName(cr),
+			Namespace: CustomAstarteNamespace}, tokenSecret)).To(Succeed())
+		return string(tokenSecret.Data[reconcile.DeviceCASignerTokenKey])
+	}
+
+	Describe("Test the CFSSL-compatible API", func() {
+		It("should report healthy and sign device certificates with the devices CA", func() {
+			Expect(reconcile.EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			baseURL := getBaseURL(getToken())
+
+			resp, err := http.Get(baseURL + "/api/v1/cfssl/health")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			csrPEM, _, err := cfsslcsr.ParseRequest(&cfsslcsr.CertificateRequest{
+				CN:         "test-realm/device-id",
+				KeyRequest: cfsslcsr.NewKeyRequest(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			payload, err := json.Marshal(map[string]string{"certificate_request": string(csrPEM)})
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err = http.Post(baseURL+"/api/v1/cfssl/sign", "application/json", bytes.NewReader(payload))
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			signResponse := struct {
+				Success bool `json:"success"`
+				Result  struct {
+					Certificate string `json:"certificate"`
+				} `json:"result"`
+			}{}
+			Expect(json.NewDecoder(resp.Body).Decode(&signResponse)).To(Succeed())
+			Expect(signResponse.Success).To(BeTrue())
+
+			cert, err := helpers.ParseCertificatePEM([]byte(signResponse.Result.Certificate))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert.Subject.CommonName).To(Equal("test-realm/device-id"))
+			Expect(cert.Issuer.CommonName).To(Equal("Astarte Root CA"))
+		})
+
+		It("should refuse requests without the token of the instance", func() {
+			Expect(reconcile.EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			for _, token := range []string{"", "invalid", reconcile.GetDeviceCASignerToken(cr, []byte("another CA key"))} {
+				resp, err := http.Post(getBaseURL(token)+"/api/v1/cfssl/sign", "application/json", bytes.NewReader([]byte("{}")))
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Or(Equal(http.StatusForbidden), Equal(http.StatusNotFound)))
+			}
+		})
+
+		It("should refuse serving instances using the CFSSL backend", func() {
+			Expect(reconcile.EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			token := getToken()
+
+			cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendCFSSL
+			Expect(k8sClient.Update(context.Background(), cr)).To(Succeed())
+
+			resp, err := http.Get(getBaseURL(token) + "/api/v1/cfssl/health")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,261 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config holds the configuration file of the Operator, meant to be mounted from a ConfigMap.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// APIVersion is the only supported version of the configuration file
+	APIVersion = "config.astarte-platform.org/v1alpha1"
+	// Kind is the kind of the configuration file
+	Kind = "OperatorConfiguration"
+)
+
+// FeatureGate identifies an optional feature of the Operator
+type FeatureGate string
+
+const (
+	// WebhooksFeatureGate enables the validating and mutating webhooks. Enabled by default.
+	WebhooksFeatureGate FeatureGate = "Webhooks"
+	// StorageVersionMigrationFeatureGate rewrites stored objects in the storage version of their CRD when
+	// the Operator starts. Enabled by default.
+	StorageVersionMigrationFeatureGate FeatureGate = "StorageVersionMigration"
+)
+
+var defaultFeatureGates = map[FeatureGate]bool{
+	WebhooksFeatureGate:                true,
+	StorageVersionMigrationFeatureGate: true,
+}
+
+// OperatorConfiguration is the configuration file of the Operator. Command line flags, when set, take precedence.
+type OperatorConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+	// Settings of each controller.
+	Controllers ControllersConfiguration `json:"controllers,omitempty"`
+	// How often all watched objects are reconciled, even when nothing changed. Defaults to 10 hours.
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+	// Leader election settings.
+	LeaderElection LeaderElectionConfiguration `json:"leaderElection,omitempty"`
+	// Restricts the objects the Operator watches.
+	Cache CacheConfiguration `json:"cache,omitempty"`
+	// Enables or disables optional features of the Operator.
+	FeatureGates map[FeatureGate]bool `json:"featureGates,omitempty"`
+}
+
+// ControllersConfiguration holds the settings of each controller
+type ControllersConfiguration struct {
+	Astarte               ControllerConfiguration `json:"astarte,omitempty"`
+	Flow                  ControllerConfiguration `json:"flow,omitempty"`
+	AstarteDefaultIngress ControllerConfiguration `json:"astarteDefaultIngress,omitempty"`
+}
+
+// ControllerConfiguration holds the settings of a controller
+type ControllerConfiguration struct {
+	// How many objects can be reconciled concurrently. Defaults to 1.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+	// How long to wait before reconciling again an object whose dependencies are not there yet,
+	// such as a Flow whose Astarte is missing.
+	RequeueAfter *metav1.Duration `json:"requeueAfter,omitempty"`
+	// The exponential backoff applied to objects failing to reconcile.
+	Backoff *BackoffConfiguration `json:"backoff,omitempty"`
+}
+
+// BackoffConfiguration is an exponential backoff, doubling at each failure
+type BackoffConfiguration struct {
+	// The delay after the first failure.
+	BaseDelay metav1.Duration `json:"baseDelay"`
+	// The maximum delay between two attempts.
+	MaxDelay metav1.Duration `json:"maxDelay"`
+}
+
+// LeaderElectionConfiguration holds the leader election settings
+type LeaderElectionConfiguration struct {
+	// Enables leader election, as --leader-elect does.
+	Enable *bool `json:"enable,omitempty"`
+	// How long non-leaders wait before trying to take over the leadership. Defaults to 15 seconds.
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+	// How long the leader keeps trying to renew its leadership before giving it up. Defaults to 10 seconds.
+	RenewDeadline *metav1.Duration `json:"renewDeadline,omitempty"`
+	// How long clients wait between attempts. Defaults to 2 seconds.
+	RetryPeriod *metav1.Duration `json:"retryPeriod,omitempty"`
+}
+
+// CacheConfiguration restricts the objects the Operator watches, as --watch-namespaces and --managed-label-selector do
+type CacheConfiguration struct {
+	WatchNamespaces      []string `json:"watchNamespaces,omitempty"`
+	ManagedLabelSelector string   `json:"managedLabelSelector,omitempty"`
+}
+
+// Load reads and validates the configuration file at path. Unknown fields are rejected.
+func Load(path string) (*OperatorConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a configuration file. Unknown fields are rejected.
+func Parse(data []byte) (*OperatorConfiguration, error) {
+	jsonData, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &OperatorConfiguration{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("invalid Operator configuration: %w", err)
+	}
+
+	if err := cfg.Validate().ToAggregate(); err != nil {
+		return nil, fmt.Errorf("invalid Operator configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate returns the errors in the configuration
+func (c *OperatorConfiguration) Validate() field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.APIVersion != APIVersion {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("apiVersion"), c.APIVersion, []string{APIVersion}))
+	}
+	if c.Kind != Kind {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("kind"), c.Kind, []string{Kind}))
+	}
+
+	controllersPath := field.NewPath("controllers")
+	allErrs = append(allErrs, c.Controllers.Astarte.validate(controllersPath.Child("astarte"))...)
+	allErrs = append(allErrs, c.Controllers.Flow.validate(controllersPath.Child("flow"))...)
+	allErrs = append(allErrs, c.Controllers.AstarteDefaultIngress.validate(controllersPath.Child("astarteDefaultIngress"))...)
+
+	allErrs = append(allErrs, validatePositiveDuration(field.NewPath("syncPeriod"), c.SyncPeriod)...)
+	allErrs = append(allErrs, c.LeaderElection.validate(field.NewPath("leaderElection"))...)
+
+	if _, err := labels.Parse(c.Cache.ManagedLabelSelector); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("cache").Child("managedLabelSelector"), c.Cache.ManagedLabelSelector, err.Error()))
+	}
+
+	for gate := range c.FeatureGates {
+		if _, ok := defaultFeatureGates[gate]; !ok {
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("featureGates").Key(string(gate)), gate, []FeatureGate{WebhooksFeatureGate, StorageVersionMigrationFeatureGate}))
+		}
+	}
+
+	return allErrs
+}
+
+func (c ControllerConfiguration) validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.MaxConcurrentReconciles < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxConcurrentReconciles"), c.MaxConcurrentReconciles, "must not be negative"))
+	}
+	allErrs = append(allErrs, validatePositiveDuration(fldPath.Child("requeueAfter"), c.RequeueAfter)...)
+
+	if c.Backoff != nil {
+		backoffPath := fldPath.Child("backoff")
+		allErrs = append(allErrs, validatePositiveDuration(backoffPath.Child("baseDelay"), &c.Backoff.BaseDelay)...)
+		allErrs = append(allErrs, validatePositiveDuration(backoffPath.Child("maxDelay"), &c.Backoff.MaxDelay)...)
+		if c.Backoff.MaxDelay.Duration < c.Backoff.BaseDelay.Duration {
+			allErrs = append(allErrs, field.Invalid(backoffPath.Child("maxDelay"), c.Backoff.MaxDelay.Duration.String(), "must not be shorter than baseDelay"))
+		}
+	}
+
+	return allErrs
+}
+
+func (c LeaderElectionConfiguration) validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validatePositiveDuration(fldPath.Child("leaseDuration"), c.LeaseDuration)...)
+	allErrs = append(allErrs, validatePositiveDuration(fldPath.Child("renewDeadline"), c.RenewDeadline)...)
+	allErrs = append(allErrs, validatePositiveDuration(fldPath.Child("retryPeriod"), c.RetryPeriod)...)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	// The same constraints client-go enforces, reported before the manager gets to start
+	leaseDuration := durationValue(c.LeaseDuration, 15*time.Second)
+	renewDeadline := durationValue(c.RenewDeadline, 10*time.Second)
+	retryPeriod := durationValue(c.RetryPeriod, 2*time.Second)
+	if leaseDuration <= renewDeadline {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leaseDuration"), leaseDuration.String(), "must be longer than renewDeadline"))
+	}
+	if renewDeadline <= retryPeriod {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewDeadline"), renewDeadline.String(), "must be longer than retryPeriod"))
+	}
+
+	return allErrs
+}
+
+func validatePositiveDuration(fldPath *field.Path, d *metav1.Duration) field.ErrorList {
+	if d != nil && d.Duration <= 0 {
+		return field.ErrorList{field.Invalid(fldPath, d.Duration.String(), "must be positive")}
+	}
+	return nil
+}
+
+func durationValue(d *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil {
+		return defaultValue
+	}
+	return d.Duration
+}
+
+// IsEnabled returns whether the feature gate is enabled. A nil configuration has all gates set to their defaults.
+func (c *OperatorConfiguration) IsEnabled(gate FeatureGate) bool {
+	if c != nil {
+		if enabled, ok := c.FeatureGates[gate]; ok {
+			return enabled
+		}
+	}
+	return defaultFeatureGates[gate]
+}
+
+// GetRequeueAfter returns how long to wait for missing dependencies, or defaultValue if unset
+func (c ControllerConfiguration) GetRequeueAfter(defaultValue time.Duration) time.Duration {
+	return durationValue(c.RequeueAfter, defaultValue)
+}
+
+// Options returns the controller options matching the configuration
+func (c ControllerConfiguration) Options() controller.Options {
+	opts := controller.Options{MaxConcurrentReconciles: c.MaxConcurrentReconciles}
+	if c.Backoff != nil {
+		opts.RateLimiter = workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](c.Backoff.BaseDelay.Duration, c.Backoff.MaxDelay.Duration)
+	}
+	return opts
+}
@@ -0,0 +1,110 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OperatorConfiguration", func() {
+	It("should accept a minimal configuration, keeping the defaults", func() {
+		cfg, err := Parse([]byte("apiVersion: config.astarte-platform.org/v1alpha1\nkind: OperatorConfiguration\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.IsEnabled(WebhooksFeatureGate)).To(BeTrue())
+		Expect(cfg.Controllers.Astarte.GetRequeueAfter(time.Minute)).To(Equal(time.Minute))
+		Expect(cfg.Controllers.Astarte.Options().MaxConcurrentReconciles).To(BeZero())
+		Expect(cfg.Controllers.Astarte.Options().RateLimiter).To(BeNil())
+	})
+
+	It("should parse a complete configuration", func() {
+		cfg, err := Parse([]byte(`
+apiVersion: config.astarte-platform.org/v1alpha1
+kind: OperatorConfiguration
+controllers:
+  astarte:
+    maxConcurrentReconciles: 4
+    requeueAfter: 2m
+    backoff:
+      baseDelay: 1s
+      maxDelay: 5m
+syncPeriod: 1h
+leaderElection:
+  enable: true
+  leaseDuration: 30s
+  renewDeadline: 20s
+  retryPeriod: 5s
+cache:
+  watchNamespaces: [team-a, team-b]
+  managedLabelSelector: team=iot
+featureGates:
+  Webhooks: false
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Controllers.Astarte.Options().MaxConcurrentReconciles).To(Equal(4))
+		Expect(cfg.Controllers.Astarte.Options().RateLimiter).ToNot(BeNil())
+		Expect(cfg.Controllers.Astarte.GetRequeueAfter(time.Minute)).To(Equal(2 * time.Minute))
+		Expect(cfg.Controllers.Flow.GetRequeueAfter(30 * time.Second)).To(Equal(30 * time.Second))
+		Expect(cfg.SyncPeriod.Duration).To(Equal(time.Hour))
+		Expect(cfg.Cache.WatchNamespaces).To(Equal([]string{"team-a", "team-b"}))
+		Expect(cfg.IsEnabled(WebhooksFeatureGate)).To(BeFalse())
+	})
+
+	It("should reject unknown versions and fields", func() {
+		_, err := Parse([]byte("apiVersion: config.astarte-platform.org/v2\nkind: OperatorConfiguration\n"))
+		Expect(err).To(HaveOccurred())
+		_, err = Parse([]byte("apiVersion: config.astarte-platform.org/v1alpha1\nkind: OperatorConfiguration\nsyncPeriods: 1h\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid settings", func() {
+		_, err := Parse([]byte(`
+apiVersion: config.astarte-platform.org/v1alpha1
+kind: OperatorConfiguration
+controllers:
+  flow:
+    maxConcurrentReconciles: -1
+    backoff:
+      baseDelay: 1m
+      maxDelay: 1s
+leaderElection:
+  leaseDuration: 10s
+  renewDeadline: 15s
+cache:
+  managedLabelSelector: "team==,"
+featureGates:
+  Unknown: true
+`))
+		Expect(err).To(MatchError(And(
+			ContainSubstring("controllers.flow.maxConcurrentReconciles"),
+			ContainSubstring("controllers.flow.backoff.maxDelay"),
+			ContainSubstring("leaderElection.leaseDuration"),
+			ContainSubstring("cache.managedLabelSelector"),
+			ContainSubstring("featureGates[Unknown]"),
+		)))
+	})
+
+	It("should keep the default feature gates without a configuration", func() {
+		var cfg *OperatorConfiguration
+		Expect(cfg.IsEnabled(WebhooksFeatureGate)).To(BeTrue())
+		Expect(cfg.IsEnabled(StorageVersionMigrationFeatureGate)).To(BeTrue())
+	})
+})
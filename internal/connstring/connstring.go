@@ -0,0 +1,177 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package connstring parses the connection strings Astarte can be given to reach RabbitMQ and Cassandra.
+// Errors never include credentials, as connection strings are usually stored in Secrets.
+package connstring
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultAMQPPort is the port of amqp:// URIs without one
+	DefaultAMQPPort int32 = 5672
+	// DefaultAMQPSPort is the port of amqps:// URIs without one
+	DefaultAMQPSPort int32 = 5671
+	// DefaultCassandraPort is the port of Cassandra contact points without one
+	DefaultCassandraPort int32 = 9042
+)
+
+// AMQP is a connection to RabbitMQ
+type AMQP struct {
+	Host        string
+	Port        int32
+	VirtualHost string
+	Username    string
+	Password    string
+	SSL         bool
+}
+
+// Cassandra is a connection to a Cassandra cluster
+type Cassandra struct {
+	Nodes    []Node
+	Username string
+	Password string
+}
+
+// Node is a Cassandra contact point
+type Node struct {
+	Host string
+	Port int32
+}
+
+// ParseAMQPURI parses a URI in the form amqp[s]://[username[:password]@]host[:port][/virtual-host], as defined
+// by the RabbitMQ URI specification. The virtual host defaults to "/". Surrounding whitespace is ignored.
+func ParseAMQPURI(uri string) (AMQP, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		// url.Error embeds the whole URI: keep only the cause
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return AMQP{}, fmt.Errorf("invalid AMQP URI: %w", err)
+	}
+
+	conn := AMQP{VirtualHost: "/"}
+	switch u.Scheme {
+	case "amqp":
+		conn.Port = DefaultAMQPPort
+	case "amqps":
+		conn.Port = DefaultAMQPSPort
+		conn.SSL = true
+	default:
+		return AMQP{}, fmt.Errorf("invalid AMQP URI: unsupported scheme %q, must be amqp or amqps", u.Scheme)
+	}
+
+	if conn.Host = u.Hostname(); conn.Host == "" {
+		return AMQP{}, errors.New("invalid AMQP URI: missing host")
+	}
+	if u.Port() != "" {
+		if conn.Port, err = parsePort(u.Port()); err != nil {
+			return AMQP{}, fmt.Errorf("invalid AMQP URI: %w", err)
+		}
+	}
+
+	if u.User != nil {
+		conn.Username = u.User.Username()
+		conn.Password, _ = u.User.Password()
+	}
+
+	if vhost := strings.TrimPrefix(u.EscapedPath(), "/"); vhost != "" {
+		if strings.Contains(vhost, "/") {
+			return AMQP{}, errors.New("invalid AMQP URI: slashes in the virtual host must be escaped as %2F")
+		}
+		if conn.VirtualHost, err = url.PathUnescape(vhost); err != nil {
+			return AMQP{}, errors.New("invalid AMQP URI: invalid virtual host")
+		}
+	}
+
+	return conn, nil
+}
+
+// ParseCassandraContactPoints parses a comma-separated list of contact points in the form
+// [username[:password]@]host[:port][,host[:port]...]. Credentials are URL-encoded, ports default to 9042,
+// and IPv6 addresses must be enclosed in square brackets. Surrounding whitespace is ignored.
+func ParseCassandraContactPoints(s string) (Cassandra, error) {
+	conn := Cassandra{}
+
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		credentials := strings.SplitN(s[:i], ":", 2)
+		s = s[i+1:]
+
+		var err error
+		if conn.Username, err = url.PathUnescape(credentials[0]); err != nil || conn.Username == "" {
+			return Cassandra{}, errors.New("invalid Cassandra contact points: invalid username")
+		}
+		if len(credentials) == 2 {
+			if conn.Password, err = url.PathUnescape(credentials[1]); err != nil {
+				return Cassandra{}, errors.New("invalid Cassandra contact points: invalid password")
+			}
+		}
+	}
+
+	for _, contactPoint := range strings.Split(s, ",") {
+		contactPoint = strings.TrimSpace(contactPoint)
+		if contactPoint == "" {
+			return Cassandra{}, errors.New("invalid Cassandra contact points: empty contact point")
+		}
+
+		node, err := parseContactPoint(contactPoint)
+		if err != nil {
+			return Cassandra{}, fmt.Errorf("invalid Cassandra contact points: %w", err)
+		}
+		conn.Nodes = append(conn.Nodes, node)
+	}
+
+	return conn, nil
+}
+
+func parseContactPoint(contactPoint string) (Node, error) {
+	// Add the default port to hostnames, IPv4 addresses and bracketed IPv6 addresses without one
+	bracketed := strings.HasPrefix(contactPoint, "[")
+	if (bracketed && strings.HasSuffix(contactPoint, "]")) || (!bracketed && !strings.Contains(contactPoint, ":")) {
+		contactPoint = net.JoinHostPort(strings.Trim(contactPoint, "[]"), strconv.Itoa(int(DefaultCassandraPort)))
+	}
+
+	host, port, err := net.SplitHostPort(contactPoint)
+	if err != nil || host == "" || strings.ContainsAny(host, "/[] ") {
+		return Node{}, fmt.Errorf("invalid contact point %q", contactPoint)
+	}
+	p, err := parsePort(port)
+	if err != nil {
+		return Node{}, err
+	}
+
+	return Node{Host: host, Port: p}, nil
+}
+
+func parsePort(port string) (int32, error) {
+	p, err := strconv.ParseInt(port, 10, 32)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, fmt.Errorf("invalid port %q", port)
+	}
+	return int32(p), nil
+}
@@ -39,18 +39,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/config"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/controllerutils"
+	recon "github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/version"
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 )
 
+// referencedSecretsIndexField indexes Astarte instances by the names of the Secrets they reference
+const referencedSecretsIndexField = ".spec.referencedSecrets"
+
 // AstarteReconciler reconciles a Astarte object
 type AstarteReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Log      logr.Logger
 	Recorder record.EventRecorder
+	// Config tunes the controller, its zero value keeps the defaults
+	Config config.ControllerConfiguration
 }
 
 // +kubebuilder:rbac:groups=api.astarte-platform.org,resources=astartes,verbs=get;list;watch;create;update;patch;delete
@@ -63,8 +70,8 @@ type AstarteReconciler struct {
 // +kubebuilder:rbac:groups=apps,resourceNames=astarte-operator,resources=deployments/finalizers,verbs=update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create
-// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
-// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,6 +92,9 @@ func (r *AstarteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
+	// Resources stored while the defaulting webhook was disabled might lack some defaults: fill them in memory,
+	// so that the reconciliation sees the same explicit spec in either case.
+	instance.SetDefaults()
 	reqLogger.Info("Reconciling Astarte")
 
 	reconciler := controllerutils.ReconcileHelper{
@@ -121,10 +131,12 @@ func (r *AstarteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	// Are we capable of handling the requested version?
 	newAstarteSemVersion, err := version.GetAstarteSemanticVersionFrom(instance.Spec.Version)
 	if err != nil {
-		// Reconcile every minute if we're here
+		// Reconcile every minute if we're here. Returning the error would make controller-runtime ignore the
+		// interval and back off instead, hence it is reported through the event and the log only.
 		r.Recorder.Eventf(instance, "Warning", apiv2alpha1.AstarteResourceEventInconsistentVersion.String(),
 			err.Error(), instance.Spec.Version)
-		return ctrl.Result{RequeueAfter: time.Minute}, err
+		reqLogger.Error(err, "Cannot handle the requested Astarte version", "Version", instance.Spec.Version)
+		return ctrl.Result{RequeueAfter: r.Config.GetRequeueAfter(time.Minute)}, nil
 	}
 
 	// Check if the Astarte instance is marked to be deleted, which is
@@ -183,9 +195,14 @@ func (r *AstarteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	// Reconciliation was successful. Log a message and return
+	// Reconciliation was successful. Log a message and return, coming back when the data queues need to be polled
+	// or when a VerneMQ update needs to move forward, whichever comes first
 	reqLogger.Info("Astarte Reconciled successfully")
-	return ctrl.Result{}, nil
+	requeueAfter := recon.GetDataUpdaterPlantQueueAutoscalerPollInterval(instance)
+	if vmqPollInterval := recon.GetVerneMQUpdatePollInterval(instance); vmqPollInterval > 0 && (requeueAfter == 0 || vmqPollInterval < requeueAfter) {
+		requeueAfter = vmqPollInterval
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // remove removes all occurrences of s from list.
@@ -206,17 +223,51 @@ func (r *AstarteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	}
 
-	genericToAstarteReconcileRequestFunc := func(_ context.Context, obj client.Object) []reconcile.Request {
+	// Index Astarte instances by the Secrets they reference, so that a Secret event only requeues the instances using it
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &apiv2alpha1.Astarte{}, referencedSecretsIndexField,
+		func(obj client.Object) []string {
+			return obj.(*apiv2alpha1.Astarte).GetReferencedSecretNames()
+		}); err != nil {
+		return err
+	}
+
+	secretToAstarteReconcileRequestFunc := func(_ context.Context, obj client.Object) []reconcile.Request {
 		ret := []reconcile.Request{}
 		astarteList := &apiv2alpha1.AstarteList{}
-		// TODO: maybe there is a better way to do this
-		_ = r.List(context.Background(), astarteList, client.InNamespace(obj.GetNamespace()))
+		_ = r.List(context.Background(), astarteList, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{referencedSecretsIndexField: obj.GetName()})
+
+		for _, item := range astarteList.Items {
+			ret = append(ret, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			})
+		}
+
+		return ret
+	}
 
-		if len(astarteList.Items) == 0 {
-			return ret
+	// Mirrored devices CA bundles live in other namespaces, hence they can't be owned by their Astarte
+	mirroredConfigMapToAstarteReconcileRequestFunc := func(_ context.Context, obj client.Object) []reconcile.Request {
+		name, namespace := obj.GetLabels()[recon.DeviceCABundleSourceNameLabel], obj.GetLabels()[recon.DeviceCABundleSourceNamespaceLabel]
+		if name == "" || namespace == "" {
+			return []reconcile.Request{}
 		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+	}
 
+	namespaceToAstarteReconcileRequestFunc := func(_ context.Context, obj client.Object) []reconcile.Request {
+		ret := []reconcile.Request{}
+		astarteList := &apiv2alpha1.AstarteList{}
+		_ = r.List(context.Background(), astarteList)
+
+		// Only instances mirroring their devices CA bundle care about namespaces
 		for _, item := range astarteList.Items {
+			if item.Spec.CFSSL.PublishCABundle == nil || item.Spec.CFSSL.PublishCABundle.NamespaceSelector == nil {
+				continue
+			}
 			ret = append(ret, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      item.GetName(),
@@ -229,16 +280,28 @@ func (r *AstarteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(r.Config.Options()).
 		For(&apiv2alpha1.Astarte{}, builder.WithPredicates(pred)).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.StatefulSet{}).
+		// Secrets and ConfigMaps are only watched through their metadata, there's no need to keep their data in memory.
+		// Operator-generated ones are owned by their Astarte, user-provided Secrets are looked up through the index.
+		Owns(&v1.Secret{}, builder.OnlyMetadata).
+		Owns(&v1.ConfigMap{}, builder.OnlyMetadata).
 		Watches(
 			&v1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(genericToAstarteReconcileRequestFunc),
+			handler.EnqueueRequestsFromMapFunc(secretToAstarteReconcileRequestFunc),
+			builder.OnlyMetadata,
 		).
 		Watches(
 			&v1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(genericToAstarteReconcileRequestFunc),
+			handler.EnqueueRequestsFromMapFunc(mirroredConfigMapToAstarteReconcileRequestFunc),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&v1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(namespaceToAstarteReconcileRequestFunc),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
 		).
 		Complete(r)
 }
@@ -104,7 +104,7 @@ var _ = Describe("Astarte Controller", Ordered, Serial, func() {
 				NamespacedName: typeNamespacedName,
 			})
 
-			Expect(err).To(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
 			// Check that we're requeuing after a minute due to version error
 			Expect(result.RequeueAfter).To(Equal(time.Minute))
 		})
@@ -20,7 +20,9 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"slices"
+	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -35,8 +37,12 @@ func (r *AstarteReconciler) handleFinalization(instance *v2alpha1.Astarte) (ctrl
 		// Run finalization logic for astarteFinalizer. If the
 		// finalization logic fails, don't remove the finalizer so
 		// that we can retry during the next reconciliation.
-		if e := controllerutils.FinalizeAstarte(r.Client, instance.Name, instance.Namespace,
+		if e := controllerutils.FinalizeAstarte(r.Client, instance,
 			r.Log.WithValues("Request.Namespace", instance.Namespace, "Request.Name", instance.Name)); e != nil {
+			// Snapshots take a while, there's no point in backing off
+			if errors.Is(e, controllerutils.ErrVolumeSnapshotsNotReady) {
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+			}
 			return ctrl.Result{}, e
 		}
 
@@ -20,7 +20,6 @@ package flow
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"time"
 
@@ -31,11 +30,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/config"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/flow"
 	"github.com/go-logr/logr"
 )
@@ -45,6 +46,8 @@ type FlowReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Config tunes the controller, its zero value keeps the defaults
+	Config config.ControllerConfiguration
 }
 
 // +kubebuilder:rbac:groups=flow.astarte-platform.org,resources=flows,verbs=get;list;watch;create;update;patch;delete
@@ -69,7 +72,7 @@ func (r *FlowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	// Get the rest
 	astarte, existingBlocks, reconcileResult, err := r.getResourcesForReconciliationFor(instance)
-	if err != nil {
+	if err != nil || astarte == nil {
 		return reconcileResult, err
 	}
 
@@ -147,9 +150,10 @@ func (r *FlowReconciler) getResourcesForReconciliationFor(instance *flowv2alpha1
 	astarte := &apiv2alpha1.Astarte{}
 	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.Astarte.Name, Namespace: instance.Namespace}, astarte); err != nil {
 		if errors.IsNotFound(err) {
-			d, _ := time.ParseDuration("30s")
-			return nil, nil, reconcile.Result{Requeue: true, RequeueAfter: d},
-				fmt.Errorf("The Astarte Instance %s associated to this Flow cannot be found", instance.Spec.Astarte)
+			// Wait for the Astarte instance without an error, which would make controller-runtime back off instead
+			r.Log.Info("The Astarte instance associated to this Flow cannot be found, waiting for it",
+				"Flow", instance.Name, "Astarte", instance.Spec.Astarte.Name)
+			return nil, nil, reconcile.Result{RequeueAfter: r.Config.GetRequeueAfter(30 * time.Second)}, nil
 		}
 		// Error reading the object - requeue the request.
 		return nil, nil, reconcile.Result{}, err
@@ -259,9 +263,10 @@ func computePodsFailureForBlock(podList *v1.PodList) (bool, []v1.ContainerState)
 // SetupWithManager sets up the controller with the Manager.
 func (r *FlowReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(r.Config.Options()).
 		For(&flowv2alpha1.Flow{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.StatefulSet{}).
-		Owns(&v1.Secret{}).
+		Owns(&v1.Secret{}, builder.OnlyMetadata).
 		Complete(r)
 }
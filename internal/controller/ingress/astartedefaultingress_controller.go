@@ -20,7 +20,6 @@ package ingress
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -39,6 +38,7 @@ import (
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	ingressv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/config"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/controllerutils"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/defaultingress"
 )
@@ -48,6 +48,8 @@ type AstarteDefaultIngressReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Config tunes the controller, its zero value keeps the defaults
+	Config config.ControllerConfiguration
 }
 
 // +kubebuilder:rbac:groups=ingress.astarte-platform.org,resources=astartedefaultingresses,verbs=get;list;watch;create;update;patch;delete
@@ -80,9 +82,10 @@ func (r *AstarteDefaultIngressReconciler) Reconcile(ctx context.Context, req ctr
 	astarte := &apiv2alpha1.Astarte{}
 	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.Astarte, Namespace: instance.Namespace}, astarte); err != nil {
 		if errors.IsNotFound(err) {
-			d, _ := time.ParseDuration("30s")
-			return ctrl.Result{Requeue: true, RequeueAfter: d},
-				fmt.Errorf("the Astarte Instance %s associated to this Ingress object cannot be found", instance.Spec.Astarte)
+			// Wait for the Astarte instance without an error, which would make controller-runtime back off instead
+			reqLogger.Info("The Astarte instance associated to this Ingress object cannot be found, waiting for it",
+				"Astarte", instance.Spec.Astarte)
+			return ctrl.Result{RequeueAfter: r.Config.GetRequeueAfter(30 * time.Second)}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
@@ -168,6 +171,7 @@ func (r *AstarteDefaultIngressReconciler) SetupWithManager(mgr ctrl.Manager) err
 
 	// Watch for changes to secondary resource Ingress and requeue the owner AstarteDefaultIngress
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(r.Config.Options()).
 		For(&ingressv2alpha1.AstarteDefaultIngress{}, builder.WithPredicates(pred)).
 		Owns(&networkingv1.Ingress{}).
 		Watches(
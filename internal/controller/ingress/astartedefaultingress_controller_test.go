@@ -20,9 +20,17 @@ package ingress
 
 import (
 	context "context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
-
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ingressv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/config"
 	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
 )
 
@@ -40,7 +48,25 @@ var _ = Describe("AstarteDefaultIngress Controller", Ordered, Serial, func() {
 	})
 
 	Context("Test Reconcile function", func() {
-		// To be implemented
+		It("should wait for a missing Astarte instance without failing", func() {
+			ingress := &ingressv2alpha1.AstarteDefaultIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "missing-astarte", Namespace: CustomIngressNamespace},
+				Spec:       ingressv2alpha1.AstarteDefaultIngressSpec{Astarte: "missing"},
+			}
+			Expect(k8sClient.Create(context.Background(), ingress)).To(Succeed())
+
+			reconciler := &AstarteDefaultIngressReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Log:    ctrl.Log.WithName("test-reconciler"),
+				Config: config.ControllerConfiguration{RequeueAfter: &metav1.Duration{Duration: 5 * time.Second}},
+			}
+			result, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Second))
+		})
 	})
 
 	Context("Test EnsureAPIIngress function", func() {
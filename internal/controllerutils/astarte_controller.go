@@ -20,6 +20,7 @@ package controllerutils
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,7 +28,10 @@ import (
 	"github.com/go-logr/logr"
 	"go.openly.dev/pointy"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -52,8 +56,8 @@ type ReconcileHelper struct {
 
 // CheckAndPerformUpgrade carries over an upgrade, if needed, of an Astarte resource
 func (r *ReconcileHelper) CheckAndPerformUpgrade(reqLogger logr.Logger, instance *apiv2alpha1.Astarte, newAstarteSemVersion *semver.Version) (ctrl.Result, error) {
-	// TODO: This should go in the Admission Webhook too, going forward, to prevent deadlocks.
-	// Given we're at a high chance of deadlocking here, we want to compute the status again and don't trust what
+	// The Admission Webhook already refuses unsupported upgrade paths, and warns when the cluster isn't green. Still,
+	// given we're at a high chance of deadlocking here, we want to compute the status again and don't trust what
 	// was reported in a previous reconciliation exclusively. On the other hand, in some scenarios (e.g.: failed upgrade
 	// due to a temporary issue) we don't want to trust the computed health exclusively if the upgrade started at a time
 	// when the cluster was healthy. As such, proceed if one among the computed health and the reported health are green.
@@ -125,25 +129,13 @@ func (r *ReconcileHelper) ComputeClusterHealth(reqLogger logr.Logger, instance *
 	return apiv2alpha1.AstarteClusterHealthRed
 }
 
-// nolint:dupl
 func (r *ReconcileHelper) computeCFSSLHealth(reqLogger logr.Logger, instance *apiv2alpha1.Astarte) bool {
-	if !pointy.BoolValue(instance.Spec.CFSSL.Deploy, true) {
+	if recon.GetDeviceCABackend(instance).IsHealthy(instance, r.Client) {
 		return true
 	}
 
-	cfsslDeployment := &appsv1.Deployment{}
-	if err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-cfssl"},
-		cfsslDeployment); err == nil {
-		if cfsslDeployment.Status.ReadyReplicas == 0 {
-			return false
-		}
-	} else {
-		// Just increase the count - it might be a temporary error as the Deployment is being created.
-		reqLogger.V(1).Info("Could not Get Astarte CFSSL Deployment to compute health.")
-		return false
-	}
-
-	return true
+	reqLogger.V(1).Info("Astarte device CA backend is not healthy yet.")
+	return false
 }
 
 // EnsureStatusCoherency ensures status coherency
@@ -244,6 +236,23 @@ func (r *ReconcileHelper) ComputeAstarteStatusResource(reqLogger logr.Logger, in
 	newAstarteStatus.BaseAPIURL = "https://" + instance.Spec.API.Host
 	newAstarteStatus.BrokerURL = misc.GetVerneMQBrokerURL(instance)
 
+	// Expose the devices CA details, if the CA is known to us
+	if fingerprint, expiry, err := recon.GetDeviceCAFingerprintAndExpiry(instance, r.Client); err == nil {
+		newAstarteStatus.DeviceCAFingerprint = fingerprint
+		newAstarteStatus.DeviceCAExpiry = &metav1.Time{Time: expiry}
+	} else {
+		reqLogger.V(1).Info("Could not compute the devices CA details.", "error", err.Error())
+		newAstarteStatus.DeviceCAFingerprint = ""
+		newAstarteStatus.DeviceCAExpiry = nil
+	}
+
+	// Report which data queues each Data Updater Plant shard is consuming from
+	if shards, err := recon.GetDataUpdaterPlantShardsStatus(instance, r.Client); err == nil {
+		newAstarteStatus.DataUpdaterPlantShards = shards
+	} else {
+		reqLogger.V(1).Info("Could not compute the Data Updater Plant shards status.", "error", err.Error())
+	}
+
 	if instance.Spec.ManualMaintenanceMode {
 		newAstarteStatus.ReconciliationPhase = apiv2alpha1.ReconciliationPhaseManualMaintenanceMode
 	}
@@ -254,6 +263,25 @@ func (r *ReconcileHelper) ComputeAstarteStatusResource(reqLogger logr.Logger, in
 
 // ReconcileAstarteResources reconciles all third-party dependencies, when needed
 func (r *ReconcileHelper) ReconcileAstarteResources(instance *apiv2alpha1.Astarte) error {
+	// Take back whatever was retained by a previous instance with the same name, before generating new keys.
+	// This can only happen until the first reconciliation succeeds, which sets the Astarte version in the status.
+	if instance.Status.AstarteVersion == "" {
+		if err := recon.AdoptRetainedResources(instance, r.Client, r.Scheme); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the connection strings first, as every Astarte component relies on them
+	if err := recon.EnsureConnectionStrings(instance, r.Client, r.Scheme); err != nil {
+		r.Recorder.Event(instance, "Warning", apiv2alpha1.AstarteResourceEventReconciliationFailed.String(), err.Error())
+		return err
+	}
+
+	// Then provision RabbitMQ, if requested, so that it's ready for Astarte
+	if err := r.EnsureRabbitMQProvisioning(instance); err != nil {
+		return err
+	}
+
 	// Start by ensuring the housekeeping key
 	if err := recon.EnsureHousekeepingKey(instance, r.Client, r.Scheme); err != nil {
 		return err
@@ -280,8 +308,28 @@ func (r *ReconcileHelper) ReconcileAstarteResources(instance *apiv2alpha1.Astart
 	}
 
 	// Dependencies Dance!
-	// CFSSL
-	if err := recon.EnsureCFSSL(instance, r.Client, r.Scheme); err != nil {
+	// Device CA (CFSSL or any other backend)
+	if err := recon.EnsureDeviceCA(instance, r.Client, r.Scheme); err != nil {
+		return err
+	}
+
+	// All the keys are there: make sure the ones to be retained on deletion don't belong to Astarte
+	if err := recon.EnsureRetainableSecretsOwnership(instance, r.Client, r.Scheme); err != nil {
+		return err
+	}
+
+	// Publish the devices CA bundle, if requested
+	if err := recon.EnsureDeviceCABundle(instance, r.Client, r.Scheme); err != nil {
+		return err
+	}
+
+	// Make sure RabbitMQ and Cassandra can be reached before deploying anything relying on them
+	if err := r.EnsureBackingServicesPreflight(instance); err != nil {
+		return err
+	}
+
+	// Then check that the Cassandra topology can hold the Astarte keyspace, if requested
+	if err := r.EnsureCassandraTopologyCheck(instance); err != nil {
 		return err
 	}
 
@@ -304,6 +352,157 @@ func (r *ReconcileHelper) ReconcileAstarteResources(instance *apiv2alpha1.Astart
 	return nil
 }
 
+// EnsureBackingServicesPreflight checks whether RabbitMQ and Cassandra are reachable, reporting the outcome through
+// the Astarte conditions and events. It fails when any check fails and the preflight holds back the deployment.
+func (r *ReconcileHelper) EnsureBackingServicesPreflight(instance *apiv2alpha1.Astarte) error {
+	conditions := slices.Clone(instance.Status.Conditions)
+
+	failures := []string{}
+	if instance.Spec.Preflight.IsEnabled() {
+		for _, condition := range recon.RunBackingServicesPreflight(instance, r.Client) {
+			condition.ObservedGeneration = instance.Generation
+			service := strings.TrimSuffix(condition.Type, "Reachable")
+			previous := meta.FindStatusCondition(instance.Status.Conditions, condition.Type)
+			meta.SetStatusCondition(&conditions, condition)
+
+			// Report failures once, and the recoveries
+			switch {
+			case condition.Status == metav1.ConditionFalse:
+				failures = append(failures, fmt.Sprintf("%s: %s", service, condition.Message))
+				if previous == nil || previous.Status != metav1.ConditionFalse || previous.Message != condition.Message {
+					r.Recorder.Eventf(instance, "Warning", apiv2alpha1.AstarteResourceEventPreflightFailed.String(),
+						"%s preflight failed: %s", service, condition.Message)
+				}
+			case previous != nil && previous.Status == metav1.ConditionFalse:
+				r.Recorder.Eventf(instance, "Normal", apiv2alpha1.AstarteResourceEventPreflight.String(),
+					"%s preflight passed", service)
+			}
+		}
+	} else {
+		meta.RemoveStatusCondition(&conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)
+		meta.RemoveStatusCondition(&conditions, apiv2alpha1.AstarteConditionCassandraReachable)
+	}
+
+	if err := r.patchConditions(instance, conditions); err != nil {
+		return err
+	}
+
+	if len(failures) > 0 && instance.Spec.Preflight.HoldsDeployment() {
+		return fmt.Errorf("holding back the Astarte components until the preflight passes. %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// EnsureRabbitMQProvisioning provisions RabbitMQ for Astarte, if requested, reporting the outcome through the
+// Astarte conditions and events
+func (r *ReconcileHelper) EnsureRabbitMQProvisioning(instance *apiv2alpha1.Astarte) error {
+	conditions := slices.Clone(instance.Status.Conditions)
+	if instance.Spec.RabbitMQ.Provisioning == nil {
+		meta.RemoveStatusCondition(&conditions, apiv2alpha1.AstarteConditionRabbitMQProvisioned)
+		return r.patchConditions(instance, conditions)
+	}
+
+	condition := metav1.Condition{
+		Type:               apiv2alpha1.AstarteConditionRabbitMQProvisioned,
+		Status:             metav1.ConditionTrue,
+		Reason:             recon.RabbitMQProvisioningReasonProvisioned,
+		Message:            "The virtual host, the Astarte user and its permissions are provisioned",
+		ObservedGeneration: instance.Generation,
+	}
+	drifts, err := recon.EnsureRabbitMQProvisioning(instance, r.Client)
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = recon.RabbitMQProvisioningReasonFailed
+		condition.Message = err.Error()
+		// Report the same failure once
+		previous := meta.FindStatusCondition(instance.Status.Conditions, condition.Type)
+		if previous == nil || previous.Status != metav1.ConditionFalse || previous.Message != condition.Message {
+			r.Recorder.Eventf(instance, "Warning", apiv2alpha1.AstarteResourceEventRabbitMQProvisioningFailed.String(),
+				"Could not provision RabbitMQ: %s", err.Error())
+		}
+	case len(drifts) > 0:
+		condition.Reason = recon.RabbitMQProvisioningReasonDriftCorrected
+		condition.Message = "Corrected " + strings.Join(drifts, "; ")
+		r.Recorder.Eventf(instance, "Warning", apiv2alpha1.AstarteResourceEventRabbitMQDrift.String(),
+			"RabbitMQ drifted from the Astarte configuration, corrected %s", strings.Join(drifts, "; "))
+	}
+
+	meta.SetStatusCondition(&conditions, condition)
+	if e := r.patchConditions(instance, conditions); e != nil {
+		return e
+	}
+	return err
+}
+
+// EnsureCassandraTopologyCheck reports the Cassandra topology in the Astarte status, if requested, flagging
+// replication settings of the Astarte system keyspace which the cluster cannot satisfy. It never holds back
+// the deployment.
+func (r *ReconcileHelper) EnsureCassandraTopologyCheck(instance *apiv2alpha1.Astarte) error {
+	conditions := slices.Clone(instance.Status.Conditions)
+	if !instance.Spec.Cassandra.TopologyCheck.IsEnabled() {
+		recon.ForgetCassandraTopologyCheck(instance.UID)
+		meta.RemoveStatusCondition(&conditions, apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable)
+		return r.patchStatus(instance, func(status *apiv2alpha1.AstarteStatus) {
+			status.Conditions = conditions
+			status.CassandraTopology = nil
+		})
+	}
+
+	// The last outcome still holds as long as it is recent and the spec did not change
+	previous := meta.FindStatusCondition(instance.Status.Conditions, apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable)
+	if previous != nil && previous.ObservedGeneration == instance.Generation && !recon.IsCassandraTopologyCheckDue(instance) {
+		return nil
+	}
+
+	condition, topology := recon.CheckCassandraTopology(instance, r.Client)
+	condition.ObservedGeneration = instance.Generation
+	meta.SetStatusCondition(&conditions, condition)
+
+	// Report unsatisfiable replications once, and the recoveries. An unknown topology is no news.
+	switch {
+	case condition.Status == metav1.ConditionFalse:
+		if previous == nil || previous.Status != metav1.ConditionFalse || previous.Message != condition.Message {
+			r.Recorder.Eventf(instance, "Warning", apiv2alpha1.AstarteResourceEventCassandraReplicationUnsatisfiable.String(),
+				"The Cassandra cluster cannot satisfy the Astarte keyspace replication: %s", condition.Message)
+		}
+	case condition.Status == metav1.ConditionTrue && previous != nil && previous.Status == metav1.ConditionFalse:
+		r.Recorder.Eventf(instance, "Normal", apiv2alpha1.AstarteResourceEventCassandraReplication.String(),
+			"The Cassandra cluster can satisfy the Astarte keyspace replication again")
+	}
+
+	return r.patchStatus(instance, func(status *apiv2alpha1.AstarteStatus) {
+		status.Conditions = conditions
+		// Keep the last known topology when Cassandra cannot be reached
+		if topology != nil {
+			status.CassandraTopology = topology
+		}
+	})
+}
+
+// patchConditions stores the given conditions in the status of instance, if they changed
+func (r *ReconcileHelper) patchConditions(instance *apiv2alpha1.Astarte, conditions []metav1.Condition) error {
+	return r.patchStatus(instance, func(status *apiv2alpha1.AstarteStatus) {
+		status.Conditions = conditions
+	})
+}
+
+// patchStatus applies mutate to the status of instance and stores it, if it changed
+func (r *ReconcileHelper) patchStatus(instance *apiv2alpha1.Astarte, mutate func(*apiv2alpha1.AstarteStatus)) error {
+	// Patch a copy: the in-memory spec of instance holds the resolved connection strings, which must be kept
+	newInstance := instance.DeepCopy()
+	mutate(&newInstance.Status)
+	if equality.Semantic.DeepEqual(instance.Status, newInstance.Status) {
+		return nil
+	}
+
+	if err := r.Client.Status().Patch(context.TODO(), newInstance, client.MergeFrom(instance)); err != nil {
+		return err
+	}
+	instance.Status = newInstance.Status
+	return nil
+}
+
 // EnsureAstarteMicroservices reconciles all Astarte microservices
 func (r *ReconcileHelper) EnsureAstarteMicroservices(instance *apiv2alpha1.Astarte) error {
 	// OK! Now it's time to reconcile all of Astarte Services, in a specific order.
@@ -332,7 +531,10 @@ func (r *ReconcileHelper) EnsureAstarteMicroservices(instance *apiv2alpha1.Astar
 		return err
 	}
 
-	// Now it's Data Updater plant turn
+	// Now it's Data Updater plant turn. Figure out how many shards we need first
+	if err := recon.EnsureDataUpdaterPlantQueueAutoscaling(instance, r.Client); err != nil {
+		return err
+	}
 	if err := recon.EnsureAstarteDataUpdaterPlant(instance, instance.Spec.Components.DataUpdaterPlant, r.Client, r.Scheme); err != nil {
 		return err
 	}
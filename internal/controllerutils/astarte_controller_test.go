@@ -20,10 +20,19 @@ package controllerutils
 
 import (
 	"context"
+	"net"
+	"strconv"
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("controllerutils tests", Ordered, Serial, func() {
@@ -56,4 +65,131 @@ var _ = Describe("controllerutils tests", Ordered, Serial, func() {
 
 	Describe("TestFunction", func() {
 	})
+
+	Describe("Test EnsureBackingServicesPreflight", func() {
+		var recorder *record.FakeRecorder
+		var reconciler *ReconcileHelper
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &ReconcileHelper{Client: k8sClient, Scheme: scheme.Scheme, Recorder: recorder}
+		})
+
+		// pointTo points RabbitMQ and Cassandra to address
+		pointTo := func(address string) {
+			host, port, err := net.SplitHostPort(address)
+			Expect(err).ToNot(HaveOccurred())
+			p, err := strconv.Atoi(port)
+			Expect(err).ToNot(HaveOccurred())
+			cr.Spec.RabbitMQ.Connection.Host = host
+			cr.Spec.RabbitMQ.Connection.Port = pointy.Int32(int32(p))
+			cr.Spec.Cassandra.Connection.Nodes = []apiv2alpha1.HostAndPort{{Host: host, Port: pointy.Int32(int32(p))}}
+		}
+
+		storedConditions := func() []metav1.Condition {
+			stored := &apiv2alpha1.Astarte{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cr), stored)).To(Succeed())
+			return stored.Status.Conditions
+		}
+
+		It("should not run unless enabled", func() {
+			cr.Spec.Preflight = nil
+			pointTo("127.0.0.1:1")
+
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).To(Succeed())
+			Expect(storedConditions()).To(BeEmpty())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should store the conditions, and hold back the deployment until the preflight passes", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			address := listener.Addr().String()
+			Expect(listener.Close()).To(Succeed())
+
+			cr.Spec.Preflight = &apiv2alpha1.AstartePreflightSpec{Enable: true, HoldDeployment: true, TimeoutSeconds: pointy.Int32(1)}
+			pointTo(address)
+
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).ToNot(Succeed())
+			Expect(meta.IsStatusConditionFalse(storedConditions(), apiv2alpha1.AstarteConditionRabbitMQReachable)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(storedConditions(), apiv2alpha1.AstarteConditionCassandraReachable)).To(BeTrue())
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(HavePrefix("Warning ErrPreflight RabbitMQ preflight failed"))
+			Expect(<-recorder.Events).To(HavePrefix("Warning ErrPreflight Cassandra preflight failed"))
+
+			// The same failures are not reported twice
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).ToNot(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+
+			listener, err = net.Listen("tcp", address)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(listener.Close)
+
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(storedConditions(), apiv2alpha1.AstarteConditionRabbitMQReachable)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(storedConditions(), apiv2alpha1.AstarteConditionCassandraReachable)).To(BeTrue())
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Normal Preflight RabbitMQ preflight passed"))
+		})
+
+		It("should not hold back the deployment unless requested, and drop the conditions once disabled", func() {
+			cr.Spec.Preflight = &apiv2alpha1.AstartePreflightSpec{Enable: true, TimeoutSeconds: pointy.Int32(1)}
+			pointTo("127.0.0.1:1")
+
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).To(Succeed())
+			Expect(storedConditions()).To(HaveLen(2))
+
+			cr.Spec.Preflight.Enable = false
+			Expect(reconciler.EnsureBackingServicesPreflight(cr)).To(Succeed())
+			Expect(storedConditions()).To(BeEmpty())
+			Expect(cr.Status.Conditions).To(BeEmpty())
+		})
+	})
+
+	Describe("Test EnsureRabbitMQProvisioning", func() {
+		It("should report the same failure once", func() {
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &ReconcileHelper{Client: k8sClient, Scheme: scheme.Scheme, Recorder: recorder}
+			cr.Spec.RabbitMQ.Provisioning = &apiv2alpha1.AstarteRabbitMQProvisioningSpec{
+				AdminCredentialsSecret: &apiv2alpha1.LoginCredentialsSecret{Name: "missing-admin", UsernameKey: "username", PasswordKey: "password"},
+			}
+
+			Expect(reconciler.EnsureRabbitMQProvisioning(cr)).ToNot(Succeed())
+			Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, apiv2alpha1.AstarteConditionRabbitMQProvisioned)).To(BeTrue())
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(HavePrefix("Warning ErrRabbitMQProvisioning Could not provision RabbitMQ"))
+
+			Expect(reconciler.EnsureRabbitMQProvisioning(cr)).ToNot(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	Describe("Test EnsureCassandraTopologyCheck", func() {
+		It("should report an unknown topology without events, and drop it once disabled", func() {
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &ReconcileHelper{Client: k8sClient, Scheme: scheme.Scheme, Recorder: recorder}
+			cr.Spec.Cassandra.TopologyCheck = &apiv2alpha1.AstarteCassandraTopologyCheckSpec{Enable: true, TimeoutSeconds: pointy.Int32(1)}
+			cr.Spec.Cassandra.Connection.Nodes = []apiv2alpha1.HostAndPort{{Host: "127.0.0.1", Port: pointy.Int32(1)}}
+
+			Expect(reconciler.EnsureCassandraTopologyCheck(cr)).To(Succeed())
+			stored := &apiv2alpha1.Astarte{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cr), stored)).To(Succeed())
+			Expect(meta.FindStatusCondition(stored.Status.Conditions, apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable)).To(
+				HaveField("Status", metav1.ConditionUnknown))
+			Expect(recorder.Events).To(BeEmpty())
+
+			// Unless the spec changes, Cassandra is not asked again right away
+			cr.Spec.Cassandra.Connection.Nodes = nil
+			Expect(reconciler.EnsureCassandraTopologyCheck(cr)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cr), stored)).To(Succeed())
+			Expect(meta.FindStatusCondition(stored.Status.Conditions, apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable).Message).ToNot(
+				ContainSubstring("no Cassandra nodes are configured"))
+
+			cr.Spec.Cassandra.TopologyCheck.Enable = false
+			Expect(reconciler.EnsureCassandraTopologyCheck(cr)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cr), stored)).To(Succeed())
+			Expect(stored.Status.Conditions).To(BeEmpty())
+			Expect(stored.Status.CassandraTopology).To(BeNil())
+		})
+	})
 })
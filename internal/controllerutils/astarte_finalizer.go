@@ -19,91 +19,210 @@ package controllerutils
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
-	scheduling "k8s.io/api/scheduling/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
 )
 
+// ErrVolumeSnapshotsNotReady is returned while the claims are waiting for their VolumeSnapshots to be ready
+// before being deleted. The finalization should be retried later on.
+var ErrVolumeSnapshotsNotReady = errors.New("waiting for VolumeSnapshots to be ready")
+
 // FinalizeAstarte handles the finalization logic for Astarte
-func FinalizeAstarte(c client.Client, name, namespace string, reqLogger logr.Logger) error {
+func FinalizeAstarte(c client.Client, cr *apiv2alpha1.Astarte, reqLogger logr.Logger) error {
 	reqLogger.Info("Finalizing Astarte")
 
-	// First of all - do we have the CA Secret still around?
-	theSecret := &v1.Secret{}
-	if err := c.Get(context.TODO(), types.NamespacedName{Name: name + "-cfssl-ca", Namespace: namespace}, theSecret); err == nil {
-		// The secret is there. Delete it.
-		if err := c.Delete(context.TODO(), theSecret); err != nil {
-			reqLogger.Error(err, "Error while finalizing Astarte. CFSSL CA Secret will need to be manually removed.")
-		}
+	// First of all - take care of the Secrets holding our keys
+	if err := finalizeSecrets(c, cr, reqLogger); err != nil {
+		return err
 	}
 
-	// Now it's time for our persistent volume claims. Look up all volumes, and see if we need to clear them out.
-	// Information in these volumes becomes meaningless after the instance deletion. If one wants to preserve Cassandra, he should take
-	// different measures.
-	erasePVCPrefixes := []string{
-		name + "-vernemq-data",
-		name + "-rabbitmq-data",
-		name + "-cfssl-data",
-		name + "-cassandra-data",
+	// Mirrors of the devices CA bundle live in other namespaces, so they won't be garbage collected.
+	if err := reconcile.DeleteDeviceCABundleMirrors(cr.Name, cr.Namespace, nil, c); err != nil {
+		reqLogger.Error(err, "Error while finalizing Astarte. Devices CA bundle mirrors will need to be manually removed.")
 	}
 
-	pvcs := &v1.PersistentVolumeClaimList{}
-	if err := c.List(context.TODO(), pvcs, client.InNamespace(namespace)); err == nil {
-		// Iterate and delete
-		for _, pvc := range pvcs.Items {
-			for _, prefix := range erasePVCPrefixes {
-				if strings.HasPrefix(pvc.GetName(), prefix) {
-					// Delete.
-					pvcCopy := pvc
-					if e := c.Delete(context.TODO(), &pvcCopy); e != nil {
-						reqLogger.Error(e, "Error while finalizing Astarte. A PersistentVolumeClaim will need to be manually removed.", "PVC", pvc)
-					}
-					break
-				}
-			}
-		}
-	} else if !errors.IsNotFound(err) {
-		// Notify
+	// Now it's time for our persistent volume claims.
+	if err := finalizePersistentVolumeClaims(c, cr, reqLogger); err != nil {
 		return err
 	}
 
-	// Last but not least, we remove the PriorityClasses introduced by Astarte.
-	if err := finalizePriorityClasses(c, reqLogger); err != nil {
+	// Last but not least, we remove the PriorityClasses introduced by Astarte, when no one else is using them.
+	if err := finalizePriorityClasses(c, cr, reqLogger); err != nil {
 		return err
 	}
 
+	reconcile.ForgetCassandraTopologyCheck(cr.UID)
+
 	// That's it. So long, and thanks for all the fish.
 	reqLogger.Info("Successfully finalized astarte")
 	return nil
 }
 
-func finalizePriorityClasses(c client.Client, reqLogger logr.Logger) error {
-	priorityClasses := &scheduling.PriorityClassList{}
-	err := c.List(context.TODO(), priorityClasses)
-	if err == nil {
-		// Iterate and delete
-		for _, priorityClass := range priorityClasses.Items {
-			if priorityClass.GetName() == reconcile.AstarteHighPriorityName ||
-				priorityClass.GetName() == reconcile.AstarteMidPriorityName ||
-				priorityClass.GetName() == reconcile.AstarteLowPriorityName {
-				priorityClassCopy := priorityClass
-				if err2 := c.Delete(context.TODO(), &priorityClassCopy); err2 != nil {
-					reqLogger.Error(err2, "Error while finalizing Astarte. A PriorityClass will need to be manually removed.", "PriorityClass", priorityClass)
-					return err2
-				}
+func finalizeSecrets(c client.Client, cr *apiv2alpha1.Astarte, reqLogger logr.Logger) error {
+	if cr.Spec.DeletionPolicy.GetSecretsPolicy() != apiv2alpha1.DeletionPolicyRetain {
+		// Everything else is owned by Astarte and will be garbage collected. Do we have the CA Secret still around?
+		theSecret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-cfssl-ca", Namespace: cr.Namespace}, theSecret); err == nil {
+			// The secret is there. Delete it.
+			if err := c.Delete(context.TODO(), theSecret); err != nil {
+				reqLogger.Error(err, "Error while finalizing Astarte. CFSSL CA Secret will need to be manually removed.")
+			}
+		}
+		return nil
+	}
+
+	// Retained Secrets are detached from Astarte while reconciling, see EnsureRetainableSecretsOwnership. Make sure
+	// of it anyway, in case the policy changed right before the deletion, and label them for adoption.
+	for _, secretName := range reconcile.GetRetainableSecretNames(cr) {
+		theSecret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cr.Namespace}, theSecret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		reqLogger.Info("Retaining Secret", "Secret", secretName)
+		ownerReferences := []metav1.OwnerReference{}
+		for _, ref := range theSecret.GetOwnerReferences() {
+			if ref.UID != cr.UID {
+				ownerReferences = append(ownerReferences, ref)
+			}
+		}
+		theSecret.SetOwnerReferences(ownerReferences)
+		setRetainedFromLabel(theSecret, cr.Name)
+		if err := c.Update(context.TODO(), theSecret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func finalizePersistentVolumeClaims(c client.Client, cr *apiv2alpha1.Astarte, reqLogger logr.Logger) error {
+	policy := cr.Spec.DeletionPolicy.GetPersistentVolumeClaimsPolicy()
+	prefixes := reconcile.GetRetainablePersistentVolumeClaimPrefixes(cr)
+
+	pvcs := &v1.PersistentVolumeClaimList{}
+	if err := c.List(context.TODO(), pvcs, client.InNamespace(cr.Namespace)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	snapshotsPending := false
+	for _, pvc := range pvcs.Items {
+		if !slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(pvc.GetName(), prefix) }) {
+			continue
+		}
+
+		pvcCopy := pvc
+		switch policy {
+		case apiv2alpha1.DeletionPolicyRetain:
+			reqLogger.Info("Retaining PersistentVolumeClaim", "PVC", pvc.GetName())
+			setRetainedFromLabel(&pvcCopy, cr.Name)
+			if err := c.Update(context.TODO(), &pvcCopy); err != nil {
+				return err
+			}
+		case apiv2alpha1.DeletionPolicySnapshot:
+			// Deleting the claim before its snapshot is ready might lose the volume, depending on its reclaim policy
+			ready, err := snapshotPersistentVolumeClaim(c, cr, &pvcCopy, reqLogger)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				snapshotsPending = true
+				continue
 			}
+			if err := c.Delete(context.TODO(), &pvcCopy); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		default:
+			// Information in these volumes becomes meaningless after the instance deletion.
+			if e := c.Delete(context.TODO(), &pvcCopy); e != nil {
+				reqLogger.Error(e, "Error while finalizing Astarte. A PersistentVolumeClaim will need to be manually removed.", "PVC", pvc)
+			}
+		}
+	}
+
+	if snapshotsPending {
+		return ErrVolumeSnapshotsNotReady
+	}
+	return nil
+}
+
+// snapshotPersistentVolumeClaim takes a VolumeSnapshot of pvc, unless it exists already, and returns whether it is ready to use
+func snapshotPersistentVolumeClaim(c client.Client, cr *apiv2alpha1.Astarte, pvc *v1.PersistentVolumeClaim, reqLogger logr.Logger) (bool, error) {
+	// Keep snapshots of different incarnations of the same Astarte apart
+	snapshotName := fmt.Sprintf("%s-%s", pvc.GetName(), strings.Split(string(cr.UID), "-")[0])
+	snapshotGVK := schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(snapshotGVK)
+	err := c.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: pvc.GetNamespace()}, existing)
+	if err == nil {
+		ready, _, _ := unstructured.NestedBool(existing.Object, "status", "readyToUse")
+		if !ready {
+			reqLogger.Info("Waiting for the VolumeSnapshot to be ready", "PVC", pvc.GetName(), "VolumeSnapshot", snapshotName)
 		}
-	} else if !errors.IsNotFound(err) {
-		// Notify
+		return ready, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	reqLogger.Info("Taking a VolumeSnapshot of PersistentVolumeClaim", "PVC", pvc.GetName(), "VolumeSnapshot", snapshotName)
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvc.GetName()},
+	}
+	if cr.Spec.DeletionPolicy.VolumeSnapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = cr.Spec.DeletionPolicy.VolumeSnapshotClassName
+	}
+
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetGroupVersionKind(snapshotGVK)
+	snapshot.SetName(snapshotName)
+	snapshot.SetNamespace(pvc.GetNamespace())
+	snapshot.SetLabels(map[string]string{reconcile.RetainedFromLabel: cr.Name})
+
+	if err := c.Create(context.TODO(), snapshot); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+	// A snapshot is never ready right after its creation
+	return false, nil
+}
+
+func setRetainedFromLabel(obj metav1.Object, astarteName string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[reconcile.RetainedFromLabel] = astarteName
+	obj.SetLabels(labels)
+}
+
+// finalizePriorityClasses releases the PriorityClasses of cr, removing the ones other Astarte instances do not use
+func finalizePriorityClasses(c client.Client, cr *apiv2alpha1.Astarte, reqLogger logr.Logger) error {
+	if err := reconcile.ReleaseAstartePriorityClasses(cr, c); err != nil {
+		reqLogger.Error(err, "Error while finalizing Astarte. PriorityClasses might need to be manually removed.")
 		return err
 	}
+
 	// All good
 	return nil
 }
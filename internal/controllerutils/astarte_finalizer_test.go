@@ -21,14 +21,23 @@ package controllerutils
 import (
 	"context"
 
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/reconcile"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	scheduling "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // FinalizeAstarte: not tested here because envtest doesn't run kube-controller-manager,
@@ -40,9 +49,11 @@ var _ = Describe("FinalizeAstarte", Serial, func() {
 
 var _ = Describe("finalizePriorityClasses", Ordered, Serial, func() {
 	var logger logr.Logger
+	var finalized *apiv2alpha1.Astarte
 
 	BeforeAll(func() {
 		logger = logr.Discard()
+		finalized = &apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{Name: "finalized-astarte", Namespace: "default"}}
 	})
 
 	AfterEach(func() {
@@ -54,12 +65,13 @@ var _ = Describe("finalizePriorityClasses", Ordered, Serial, func() {
 		_ = k8sClient.Delete(context.Background(), &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteLowPriorityName}})
 	})
 
-	It("deletes Astarte PriorityClasses and leaves others", func() {
+	It("deletes the PriorityClasses only the instance uses and leaves others", func() {
 		preempt := v1.PreemptNever
+		usedBy := map[string]string{reconcile.AstartePriorityClassUsersAnnotation: "default/finalized-astarte"}
 		// Create three Astarte priority classes and one foreign class with required fields
-		high := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteHighPriorityName}, Value: 1000000, GlobalDefault: false, PreemptionPolicy: &preempt}
-		mid := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteMidPriorityName}, Value: 500000, GlobalDefault: false, PreemptionPolicy: &preempt}
-		low := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteLowPriorityName}, Value: 100000, GlobalDefault: false, PreemptionPolicy: &preempt}
+		high := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteHighPriorityName, Annotations: usedBy}, Value: 1000000, GlobalDefault: false, PreemptionPolicy: &preempt}
+		mid := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteMidPriorityName, Annotations: usedBy}, Value: 500000, GlobalDefault: false, PreemptionPolicy: &preempt}
+		low := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteLowPriorityName, Annotations: usedBy}, Value: 100000, GlobalDefault: false, PreemptionPolicy: &preempt}
 		other := &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "unrelated-priority"}, Value: 1, GlobalDefault: false, PreemptionPolicy: &preempt}
 
 		Expect(k8sClient.Create(context.Background(), high)).To(Succeed())
@@ -80,7 +92,7 @@ var _ = Describe("finalizePriorityClasses", Ordered, Serial, func() {
 		Expect(found["unrelated-priority"]).To(BeTrue())
 
 		// Call finalize
-		Expect(finalizePriorityClasses(k8sClient, logger)).To(Succeed())
+		Expect(finalizePriorityClasses(k8sClient, finalized, logger)).To(Succeed())
 
 		// Astarte classes should be gone
 		exists := &scheduling.PriorityClass{}
@@ -92,12 +104,155 @@ var _ = Describe("finalizePriorityClasses", Ordered, Serial, func() {
 		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "unrelated-priority"}, &scheduling.PriorityClass{})).To(Succeed())
 	})
 
+	It("keeps PriorityClasses still used by other Astarte instances", func() {
+		preempt := v1.PreemptNever
+		for _, name := range []string{reconcile.AstarteHighPriorityName, reconcile.AstarteMidPriorityName, reconcile.AstarteLowPriorityName} {
+			pc := &scheduling.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Annotations: map[string]string{reconcile.AstartePriorityClassUsersAnnotation: "default/finalized-astarte,default/other-astarte"},
+				},
+				Value:            10,
+				PreemptionPolicy: &preempt,
+			}
+			Expect(k8sClient.Create(context.Background(), pc)).To(Succeed())
+		}
+		// The finalized instance uses a low priority class of its own
+		Expect(k8sClient.Create(context.Background(), &scheduling.PriorityClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "finalized-low-priority",
+				Annotations: map[string]string{reconcile.AstartePriorityClassUsersAnnotation: "default/finalized-astarte"},
+			},
+			Value:            10,
+			PreemptionPolicy: &preempt,
+		})).To(Succeed())
+		defer func() {
+			_ = k8sClient.Delete(context.Background(), &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "finalized-low-priority"}})
+		}()
+
+		Expect(finalizePriorityClasses(k8sClient, finalized, logger)).To(Succeed())
+
+		for _, name := range []string{reconcile.AstarteHighPriorityName, reconcile.AstarteMidPriorityName, reconcile.AstarteLowPriorityName} {
+			pc := &scheduling.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(reconcile.AstartePriorityClassUsersAnnotation, "default/other-astarte"))
+		}
+		pc := &scheduling.PriorityClass{}
+		err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "finalized-low-priority"}, pc)
+		Expect(err != nil || pc.GetDeletionTimestamp() != nil).To(BeTrue())
+	})
+
 	It("is a no-op when no Astarte PriorityClasses exist", func() {
 		// Ensure none exist
 		_ = k8sClient.Delete(context.Background(), &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteHighPriorityName}})
 		_ = k8sClient.Delete(context.Background(), &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteMidPriorityName}})
 		_ = k8sClient.Delete(context.Background(), &scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: reconcile.AstarteLowPriorityName}})
 
-		Expect(finalizePriorityClasses(k8sClient, logger)).To(Succeed())
+		Expect(finalizePriorityClasses(k8sClient, finalized, logger)).To(Succeed())
+	})
+})
+
+var _ = Describe("Astarte retention on deletion", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "astarte-retention-tests"
+	)
+
+	var cr *apiv2alpha1.Astarte
+	var logger logr.Logger
+
+	BeforeAll(func() {
+		logger = logr.Discard()
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	It("detaches and labels retained Secrets", func() {
+		cr.Spec.DeletionPolicy = &apiv2alpha1.AstarteDeletionPolicySpec{Policy: apiv2alpha1.DeletionPolicyRetain}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: reconcile.GetDeviceCASecretName(cr), Namespace: CustomAstarteNamespace}}
+		Expect(controllerutil.SetControllerReference(cr, secret, scheme.Scheme)).To(Succeed())
+		Expect(k8sClient.Create(context.Background(), secret)).To(Succeed())
+
+		Expect(finalizeSecrets(k8sClient, cr, logger)).To(Succeed())
+
+		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: secret.Name, Namespace: CustomAstarteNamespace}, secret)).To(Succeed())
+		Expect(secret.GetOwnerReferences()).To(BeEmpty())
+		Expect(secret.GetLabels()).To(HaveKeyWithValue(reconcile.RetainedFromLabel, CustomAstarteName))
+	})
+
+	It("labels retained PersistentVolumeClaims, leaving unrelated ones alone", func() {
+		cr.Spec.DeletionPolicy = &apiv2alpha1.AstarteDeletionPolicySpec{PersistentVolumeClaims: apiv2alpha1.DeletionPolicyRetain}
+		for _, name := range []string{CustomAstarteName + "-vernemq-data-" + CustomAstarteName + "-vernemq-0", "unrelated-data"} {
+			pvc := &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: CustomAstarteNamespace},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					Resources:   v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), pvc)).To(Succeed())
+		}
+
+		Expect(finalizePersistentVolumeClaims(k8sClient, cr, logger)).To(Succeed())
+
+		pvc := &v1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: CustomAstarteName + "-vernemq-data-" + CustomAstarteName + "-vernemq-0", Namespace: CustomAstarteNamespace}, pvc)).To(Succeed())
+		Expect(pvc.GetLabels()).To(HaveKeyWithValue(reconcile.RetainedFromLabel, CustomAstarteName))
+		Expect(pvc.GetDeletionTimestamp()).To(BeNil())
+		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "unrelated-data", Namespace: CustomAstarteNamespace}, pvc)).To(Succeed())
+		Expect(pvc.GetLabels()).ToNot(HaveKey(reconcile.RetainedFromLabel))
+	})
+
+	It("deletes snapshotted PersistentVolumeClaims only once their VolumeSnapshot is ready", func() {
+		cr.Spec.DeletionPolicy = &apiv2alpha1.AstarteDeletionPolicySpec{PersistentVolumeClaims: apiv2alpha1.DeletionPolicySnapshot}
+		pvcName := CustomAstarteName + "-vernemq-data-" + CustomAstarteName + "-vernemq-0"
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: CustomAstarteNamespace},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				Resources:   v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+		}
+		Expect(k8sClient.Create(context.Background(), pvc)).To(Succeed())
+
+		Expect(finalizePersistentVolumeClaims(k8sClient, cr, logger)).To(MatchError(ErrVolumeSnapshotsNotReady))
+
+		snapshots := &unstructured.UnstructuredList{}
+		snapshots.SetGroupVersionKind(schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshotList"})
+		Expect(k8sClient.List(context.Background(), snapshots, client.InNamespace(CustomAstarteNamespace))).To(Succeed())
+		Expect(snapshots.Items).To(HaveLen(1))
+		snapshot := &snapshots.Items[0]
+		Expect(snapshot.GetLabels()).To(HaveKeyWithValue(reconcile.RetainedFromLabel, CustomAstarteName))
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		Expect(source).To(Equal(pvcName))
+
+		// Not ready yet: the claim must survive another pass
+		Expect(finalizePersistentVolumeClaims(k8sClient, cr, logger)).To(MatchError(ErrVolumeSnapshotsNotReady))
+		Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: pvcName, Namespace: CustomAstarteNamespace}, pvc)).To(Succeed())
+		Expect(pvc.GetDeletionTimestamp()).To(BeNil())
+
+		Expect(unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")).To(Succeed())
+		Expect(k8sClient.Status().Update(context.Background(), snapshot)).To(Succeed())
+
+		Expect(finalizePersistentVolumeClaims(k8sClient, cr, logger)).To(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(context.Background(), types.NamespacedName{Name: pvcName, Namespace: CustomAstarteNamespace}, pvc)
+			return apierrors.IsNotFound(err) || pvc.GetDeletionTimestamp() != nil
+		}, Timeout, Interval).Should(BeTrue())
 	})
 })
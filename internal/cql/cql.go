@@ -0,0 +1,438 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cql implements the bits of the Cassandra native protocol (v4) the Operator relies upon.
+package cql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// See https://github.com/apache/cassandra/blob/trunk/doc/native_protocol_v4.spec
+const (
+	protocolVersion         = 0x04
+	responseProtocolVersion = 0x84
+	headerSize              = 9
+	maxFrameSize            = 16 << 20
+
+	opError         = 0x00
+	opStartup       = 0x01
+	opReady         = 0x02
+	opAuthenticate  = 0x03
+	opQuery         = 0x07
+	opResult        = 0x08
+	opAuthChallenge = 0x0E
+	opAuthResponse  = 0x0F
+	opAuthSuccess   = 0x10
+
+	errorCodeBadCredentials = 0x0100
+
+	consistencyOne = 0x0001
+
+	resultKindVoid = 0x0001
+	resultKindRows = 0x0002
+
+	rowsFlagGlobalTablesSpec = 0x0001
+	rowsFlagHasMorePages     = 0x0002
+	rowsFlagNoMetadata       = 0x0004
+
+	optionCustom = 0x0000
+	optionList   = 0x0020
+	optionMap    = 0x0021
+	optionSet    = 0x0022
+	optionUDT    = 0x0030
+	optionTuple  = 0x0031
+)
+
+// ErrAuthenticationFailed is returned when Cassandra refuses the credentials, or requires some when none are given
+var ErrAuthenticationFailed = errors.New("authentication failed")
+
+// Error is an ERROR message sent by Cassandra
+type Error struct {
+	Code    int32
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cassandra error 0x%04x: %s", e.Code, e.Message)
+}
+
+// Conn is a CQL connection. Requests are sent one at a time.
+type Conn struct {
+	conn   io.ReadWriter
+	stream int16
+}
+
+// NewConn wraps conn, which is expected to be connected to a Cassandra node and to have a deadline.
+func NewConn(conn io.ReadWriter) *Conn {
+	return &Conn{conn: conn}
+}
+
+// Startup initializes the connection, logging in with username and password if Cassandra requires authentication.
+func (c *Conn) Startup(username, password string) error {
+	body := &buffer{}
+	body.stringMap(map[string]string{"CQL_VERSION": "3.0.0"})
+	opcode, response, err := c.roundTrip(opStartup, body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	switch opcode {
+	case opReady:
+		return nil
+	case opAuthenticate:
+	default:
+		return fmt.Errorf("unexpected CQL opcode 0x%02x after STARTUP", opcode)
+	}
+
+	if username == "" {
+		return fmt.Errorf("%w: Cassandra requires credentials, but none were given", ErrAuthenticationFailed)
+	}
+
+	body = &buffer{}
+	body.bytes([]byte("\x00" + username + "\x00" + password))
+	opcode, response, err = c.roundTrip(opAuthResponse, body.Bytes())
+	var cqlErr *Error
+	if errors.As(err, &cqlErr) && cqlErr.Code == errorCodeBadCredentials {
+		return fmt.Errorf("%w: %s", ErrAuthenticationFailed, cqlErr.Message)
+	} else if err != nil {
+		return err
+	}
+
+	switch opcode {
+	case opAuthSuccess:
+		return nil
+	case opAuthChallenge:
+		// PasswordAuthenticator never challenges, any other authenticator is beyond our reach
+		return fmt.Errorf("unsupported Cassandra authenticator: got a challenge (%d bytes)", len(response))
+	default:
+		return fmt.Errorf("unexpected CQL opcode 0x%02x after AUTH_RESPONSE", opcode)
+	}
+}
+
+// Rows is the result of a query. Values are left encoded as Cassandra sends them, nil standing for null.
+type Rows struct {
+	Columns []string
+	Values  [][][]byte
+}
+
+// Column returns the values of the named column, or false if the result has no such column.
+func (r *Rows) Column(name string) ([][]byte, bool) {
+	for i, column := range r.Columns {
+		if column != name {
+			continue
+		}
+		values := make([][]byte, 0, len(r.Values))
+		for _, row := range r.Values {
+			values = append(values, row[i])
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// Query runs statement with consistency ONE. Results are not paged, so it is meant for small tables only.
+func (c *Conn) Query(statement string) (*Rows, error) {
+	body := &buffer{}
+	body.longString(statement)
+	body.short(consistencyOne)
+	body.WriteByte(0)
+	opcode, response, err := c.roundTrip(opQuery, body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if opcode != opResult {
+		return nil, fmt.Errorf("unexpected CQL opcode 0x%02x after QUERY", opcode)
+	}
+
+	r := &reader{response}
+	kind, err := r.int()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case resultKindVoid:
+		return &Rows{}, nil
+	case resultKindRows:
+		return parseRows(r)
+	default:
+		return nil, fmt.Errorf("unexpected CQL result kind 0x%04x", kind)
+	}
+}
+
+func parseRows(r *reader) (*Rows, error) {
+	flags, err := r.int()
+	if err != nil {
+		return nil, err
+	}
+	count, err := r.int()
+	if err != nil {
+		return nil, err
+	}
+	if flags&rowsFlagHasMorePages != 0 {
+		if _, err := r.bytes(); err != nil {
+			return nil, err
+		}
+	}
+	if flags&rowsFlagNoMetadata != 0 {
+		return nil, errors.New("CQL rows came without metadata")
+	}
+	if flags&rowsFlagGlobalTablesSpec != 0 {
+		if err := r.skipStrings(2); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := &Rows{}
+	for range count {
+		if flags&rowsFlagGlobalTablesSpec == 0 {
+			if err := r.skipStrings(2); err != nil {
+				return nil, err
+			}
+		}
+		name, err := r.string()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.skipOption(); err != nil {
+			return nil, err
+		}
+		rows.Columns = append(rows.Columns, name)
+	}
+
+	rowsCount, err := r.int()
+	if err != nil {
+		return nil, err
+	}
+	for range rowsCount {
+		row := make([][]byte, len(rows.Columns))
+		for i := range row {
+			if row[i], err = r.bytes(); err != nil {
+				return nil, err
+			}
+		}
+		rows.Values = append(rows.Values, row)
+	}
+	return rows, nil
+}
+
+// roundTrip sends a request and returns the opcode and the body of its response. ERROR responses are turned into an *Error.
+func (c *Conn) roundTrip(opcode byte, body []byte) (byte, []byte, error) {
+	c.stream = (c.stream + 1) & 0x7FFF
+
+	frame := &buffer{}
+	frame.WriteByte(protocolVersion)
+	frame.WriteByte(0)
+	frame.short(uint16(c.stream))
+	frame.WriteByte(opcode)
+	frame.int(int32(len(body)))
+	frame.Write(body)
+	if _, err := c.conn.Write(frame.Bytes()); err != nil {
+		return 0, nil, err
+	}
+
+	for {
+		header := make([]byte, headerSize)
+		if _, err := io.ReadFull(c.conn, header); err != nil {
+			return 0, nil, err
+		}
+		if header[0] != responseProtocolVersion {
+			return 0, nil, fmt.Errorf("unsupported CQL protocol version 0x%02x", header[0])
+		}
+		size := binary.BigEndian.Uint32(header[5:9])
+		if size > maxFrameSize {
+			return 0, nil, fmt.Errorf("CQL frame too large: %d bytes", size)
+		}
+		response := make([]byte, size)
+		if _, err := io.ReadFull(c.conn, response); err != nil {
+			return 0, nil, err
+		}
+
+		// Skip events and anything else not meant for us
+		if int16(binary.BigEndian.Uint16(header[2:4])) != c.stream {
+			continue
+		}
+
+		if header[4] == opError {
+			return 0, nil, parseError(response)
+		}
+		return header[4], response, nil
+	}
+}
+
+func parseError(body []byte) error {
+	r := &reader{body}
+	code, err := r.int()
+	if err != nil {
+		return err
+	}
+	message, err := r.string()
+	if err != nil {
+		return err
+	}
+	return &Error{Code: code, Message: message}
+}
+
+// buffer encodes the CQL notations
+type buffer struct {
+	bytes.Buffer
+}
+
+func (b *buffer) short(v uint16) {
+	_ = binary.Write(b, binary.BigEndian, v)
+}
+
+func (b *buffer) int(v int32) {
+	_ = binary.Write(b, binary.BigEndian, v)
+}
+
+func (b *buffer) string(s string) {
+	b.short(uint16(len(s)))
+	b.WriteString(s)
+}
+
+func (b *buffer) longString(s string) {
+	b.int(int32(len(s)))
+	b.WriteString(s)
+}
+
+func (b *buffer) bytes(v []byte) {
+	b.int(int32(len(v)))
+	b.Write(v)
+}
+
+func (b *buffer) stringMap(m map[string]string) {
+	b.short(uint16(len(m)))
+	for k, v := range m {
+		b.string(k)
+		b.string(v)
+	}
+}
+
+// reader decodes the CQL notations
+type reader struct {
+	b []byte
+}
+
+var errShortMessage = errors.New("malformed CQL message")
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b) {
+		return nil, errShortMessage
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *reader) short() (uint16, error) {
+	v, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(v), nil
+}
+
+func (r *reader) int() (int32, error) {
+	v, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(v)), nil
+}
+
+func (r *reader) string() (string, error) {
+	n, err := r.short()
+	if err != nil {
+		return "", err
+	}
+	v, err := r.next(int(n))
+	return string(v), err
+}
+
+// bytes returns nil for a null value
+func (r *reader) bytes() ([]byte, error) {
+	n, err := r.int()
+	if err != nil || n < 0 {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+func (r *reader) skipStrings(n int) error {
+	for range n {
+		if _, err := r.string(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipOption skips a type description, only the column names and values matter to us
+func (r *reader) skipOption() error {
+	id, err := r.short()
+	if err != nil {
+		return err
+	}
+
+	switch id {
+	case optionCustom:
+		return r.skipStrings(1)
+	case optionList, optionSet:
+		return r.skipOption()
+	case optionMap:
+		if err := r.skipOption(); err != nil {
+			return err
+		}
+		return r.skipOption()
+	case optionUDT:
+		if err := r.skipStrings(2); err != nil {
+			return err
+		}
+		n, err := r.short()
+		if err != nil {
+			return err
+		}
+		for range n {
+			if err := r.skipStrings(1); err != nil {
+				return err
+			}
+			if err := r.skipOption(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case optionTuple:
+		n, err := r.short()
+		if err != nil {
+			return err
+		}
+		for range n {
+			if err := r.skipOption(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
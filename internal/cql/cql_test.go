@@ -0,0 +1,218 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cql
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeNode answers a single client as a Cassandra node would, requiring the given credentials if any
+type fakeNode struct {
+	username, password string
+	startupError       *Error
+	// The results of the queries, by statement
+	tables map[string]*Rows
+}
+
+func (n fakeNode) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, headerSize)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[5:9]))
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+		stream := binary.BigEndian.Uint16(header[2:4])
+
+		switch header[4] {
+		case opStartup:
+			switch {
+			case n.startupError != nil:
+				n.reply(conn, stream, opError, n.errorBody(n.startupError.Code, n.startupError.Message))
+			case n.username != "":
+				authenticator := &buffer{}
+				authenticator.string("org.apache.cassandra.auth.PasswordAuthenticator")
+				n.reply(conn, stream, opAuthenticate, authenticator.Bytes())
+			default:
+				n.reply(conn, stream, opReady, nil)
+			}
+		case opAuthResponse:
+			if string(body[4:]) == "\x00"+n.username+"\x00"+n.password {
+				n.reply(conn, stream, opAuthSuccess, []byte{0xFF, 0xFF, 0xFF, 0xFF})
+			} else {
+				n.reply(conn, stream, opError, n.errorBody(errorCodeBadCredentials, "Provided username and/or password are incorrect"))
+			}
+		case opQuery:
+			r := &reader{body}
+			length, _ := r.int()
+			statement, _ := r.next(int(length))
+			if rows, ok := n.tables[string(statement)]; ok {
+				n.reply(conn, stream, opResult, n.rowsBody(rows))
+			} else if string(statement) == "USE astarte" {
+				n.reply(conn, stream, opResult, []byte{0x00, 0x00, 0x00, byte(resultKindVoid)})
+			} else {
+				n.reply(conn, stream, opError, n.errorBody(0x2200, "unconfigured table"))
+			}
+		}
+	}
+}
+
+func (n fakeNode) errorBody(code int32, message string) []byte {
+	b := &buffer{}
+	b.int(code)
+	b.string(message)
+	return b.Bytes()
+}
+
+// rowsBody describes each column as a map<text, text> along with its own table, and pages are mentioned for good measure
+func (n fakeNode) rowsBody(rows *Rows) []byte {
+	b := &buffer{}
+	b.int(resultKindRows)
+	b.int(rowsFlagHasMorePages)
+	b.int(int32(len(rows.Columns)))
+	b.bytes([]byte("paging state"))
+	for _, column := range rows.Columns {
+		b.string("system")
+		b.string("peers")
+		b.string(column)
+		b.short(optionMap)
+		b.short(0x000D)
+		b.short(0x000D)
+	}
+	b.int(int32(len(rows.Values)))
+	for _, row := range rows.Values {
+		for _, value := range row {
+			if value == nil {
+				b.int(-1)
+			} else {
+				b.bytes(value)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+func (n fakeNode) reply(conn net.Conn, stream uint16, opcode byte, body []byte) {
+	// Send an unrelated event first, which the client has to skip
+	event := &buffer{}
+	event.WriteByte(responseProtocolVersion)
+	event.WriteByte(0)
+	event.short(0xFFFF)
+	event.WriteByte(0x0C)
+	event.int(0)
+
+	frame := &buffer{}
+	frame.WriteByte(responseProtocolVersion)
+	frame.WriteByte(0)
+	frame.short(stream)
+	frame.WriteByte(opcode)
+	frame.int(int32(len(body)))
+	frame.Write(body)
+	_, _ = conn.Write(append(event.Bytes(), frame.Bytes()...))
+}
+
+var _ = Describe("CQL testing", func() {
+	connectTo := func(node fakeNode) *Conn {
+		client, server := net.Pipe()
+		DeferCleanup(client.Close)
+		go node.serve(server)
+		return NewConn(client)
+	}
+
+	Describe("Test Startup", func() {
+		It("should start up when Cassandra requires no authentication", func() {
+			Expect(connectTo(fakeNode{}).Startup("", "")).To(Succeed())
+			Expect(connectTo(fakeNode{}).Startup("astarte", "s3cr3t")).To(Succeed())
+		})
+
+		It("should log in with the right credentials", func() {
+			Expect(connectTo(fakeNode{username: "astarte", password: "s3cr3t"}).Startup("astarte", "s3cr3t")).To(Succeed())
+		})
+
+		It("should fail with the wrong credentials", func() {
+			err := connectTo(fakeNode{username: "astarte", password: "s3cr3t"}).Startup("astarte", "wrong")
+			Expect(err).To(MatchError(ErrAuthenticationFailed))
+			Expect(err.Error()).To(ContainSubstring("incorrect"))
+		})
+
+		It("should fail without credentials when Cassandra requires them", func() {
+			Expect(connectTo(fakeNode{username: "astarte", password: "s3cr3t"}).Startup("", "")).To(MatchError(ErrAuthenticationFailed))
+		})
+
+		It("should return the errors sent by Cassandra", func() {
+			err := connectTo(fakeNode{startupError: &Error{Code: 0x000A, Message: "Invalid or unsupported protocol version"}}).Startup("", "")
+			var cqlErr *Error
+			Expect(err).To(BeAssignableToTypeOf(cqlErr))
+			Expect(err.(*Error).Code).To(Equal(int32(0x000A)))
+		})
+
+		It("should fail when the connection drops", func() {
+			client, server := net.Pipe()
+			DeferCleanup(client.Close)
+			server.Close()
+			Expect(NewConn(client).Startup("", "")).ToNot(Succeed())
+		})
+	})
+
+	Describe("Test Query", func() {
+		peers := &Rows{
+			Columns: []string{"peer", "data_center"},
+			Values: [][][]byte{
+				{[]byte("10.0.0.2"), []byte("dc1")},
+				{[]byte("10.0.0.3"), nil},
+			},
+		}
+
+		It("should return the rows", func() {
+			conn := connectTo(fakeNode{tables: map[string]*Rows{"SELECT peer, data_center FROM system.peers": peers}})
+			Expect(conn.Startup("", "")).To(Succeed())
+			rows, err := conn.Query("SELECT peer, data_center FROM system.peers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rows).To(Equal(peers))
+
+			dataCenters, ok := rows.Column("data_center")
+			Expect(ok).To(BeTrue())
+			Expect(dataCenters).To(Equal([][]byte{[]byte("dc1"), nil}))
+			_, ok = rows.Column("rack")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should return no rows for statements which return none", func() {
+			conn := connectTo(fakeNode{})
+			rows, err := conn.Query("USE astarte")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rows.Columns).To(BeEmpty())
+		})
+
+		It("should return the errors sent by Cassandra", func() {
+			_, err := connectTo(fakeNode{}).Query("SELECT data_center FROM system.nowhere")
+			var cqlErr *Error
+			Expect(err).To(BeAssignableToTypeOf(cqlErr))
+			Expect(err.(*Error).Code).To(Equal(int32(0x2200)))
+		})
+	})
+})
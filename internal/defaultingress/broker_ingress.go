@@ -60,11 +60,11 @@ func EnsureBrokerIngress(cr *ingressv2alpha1.AstarteDefaultIngress, parent *apiv
 			return e
 		}
 		brokerService.Spec.Selector = map[string]string{"app": fmt.Sprintf("%s-vernemq", cr.Spec.Astarte)}
-		brokerService.Annotations = cr.Spec.Broker.ServiceAnnotations
+		brokerService.Annotations = getBrokerServiceAnnotations(cr)
 		brokerService.Spec.Ports = []v1.ServicePort{
 			{
-				Port:       pointy.Int32Value(parent.Spec.VerneMQ.Port, 8883),
-				TargetPort: intstr.FromInt(8883),
+				Port:       pointy.Int32Value(parent.Spec.VerneMQ.Port, apiv2alpha1.DefaultVerneMQPort),
+				TargetPort: getBrokerServiceTargetPort(cr),
 			},
 		}
 		brokerService.Spec.Type = cr.Spec.Broker.ServiceType
@@ -84,6 +84,40 @@ func EnsureBrokerIngress(cr *ingressv2alpha1.AstarteDefaultIngress, parent *apiv
 	return err
 }
 
+func getBrokerServiceAnnotations(cr *ingressv2alpha1.AstarteDefaultIngress) map[string]string {
+	proxyProtocol := cr.Spec.Broker.ProxyProtocol
+	if !proxyProtocol.IsEnabled() || cr.Spec.Broker.ServiceType == v1.ServiceTypeNodePort {
+		return cr.Spec.Broker.ServiceAnnotations
+	}
+
+	annotations := map[string]string{}
+	switch proxyProtocol.Provider {
+	case ingressv2alpha1.AWSLoadBalancerProvider:
+		annotations["service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"] = "*"
+	case ingressv2alpha1.DigitalOceanLoadBalancerProvider:
+		annotations["service.beta.kubernetes.io/do-loadbalancer-enable-proxy-protocol"] = "true"
+	case ingressv2alpha1.HetznerLoadBalancerProvider:
+		annotations["load-balancer.hetzner.cloud/uses-proxyprotocol"] = "true"
+	case ingressv2alpha1.ScalewayLoadBalancerProvider:
+		annotations["service.beta.kubernetes.io/scw-loadbalancer-proxy-protocol-v2"] = "*"
+	}
+
+	// user provided annotations always win
+	for k, v := range cr.Spec.Broker.ServiceAnnotations {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// getBrokerServiceTargetPort returns the VerneMQ port the broker load balancer forwards devices to: the tcp
+// listener expecting the PROXY protocol, when enabled, or the SSL listener
+func getBrokerServiceTargetPort(cr *ingressv2alpha1.AstarteDefaultIngress) intstr.IntOrString {
+	if cr.Spec.Broker.ProxyProtocol.IsEnabled() {
+		return intstr.FromInt32(apiv2alpha1.VerneMQProxyProtocolPort)
+	}
+	return intstr.FromInt(8883)
+}
+
 func getBrokerServiceName(cr *ingressv2alpha1.AstarteDefaultIngress) string {
 	return cr.Name + "-broker-service"
 }
@@ -0,0 +1,61 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultingress
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ingressv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v2alpha1"
+)
+
+var _ = Describe("Broker ingress", func() {
+	var adi *ingressv2alpha1.AstarteDefaultIngress
+
+	BeforeEach(func() {
+		adi = &ingressv2alpha1.AstarteDefaultIngress{}
+		adi.Spec.Broker.ServiceType = v1.ServiceTypeLoadBalancer
+	})
+
+	Describe("getBrokerServiceTargetPort", func() {
+		It("should target the SSL listener", func() {
+			Expect(getBrokerServiceTargetPort(adi)).To(Equal(intstr.FromInt(8883)))
+		})
+
+		It("should target the PROXY protocol listener when the PROXY protocol is enabled", func() {
+			adi.Spec.Broker.ProxyProtocol = &ingressv2alpha1.AstarteDefaultIngressBrokerProxyProtocolSpec{Enabled: true}
+			Expect(getBrokerServiceTargetPort(adi)).To(Equal(intstr.FromInt(1886)))
+		})
+	})
+
+	Describe("getBrokerServiceAnnotations", func() {
+		It("should add the provider annotations, letting the user ones win", func() {
+			adi.Spec.Broker.ProxyProtocol = &ingressv2alpha1.AstarteDefaultIngressBrokerProxyProtocolSpec{
+				Enabled: true, Provider: ingressv2alpha1.AWSLoadBalancerProvider,
+			}
+			adi.Spec.Broker.ServiceAnnotations = map[string]string{"example.com/annotation": "value"}
+			Expect(getBrokerServiceAnnotations(adi)).To(Equal(map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": "*",
+				"example.com/annotation": "value",
+			}))
+		})
+	})
+})
@@ -35,9 +35,17 @@ import (
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/connstring"
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
 )
 
+const (
+	rabbitMQSSLCAVolumeName         = "rabbitmq-ssl-ca"
+	rabbitMQSSLCAMountPath          = "/rabbitmq-ssl"
+	rabbitMQSSLClientCertVolumeName = "rabbitmq-ssl-client-cert"
+	rabbitMQSSLClientCertMountPath  = "/rabbitmq-ssl-client"
+)
+
 func EnsureBlock(cr *flowv2alpha1.Flow, block flowv2alpha1.ContainerBlockSpec, astarte *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme, log logr.Logger) error {
 	// In this, we'll have to orchestrate all workers, including bringing them up/down on demand
 	baseLabels := map[string]string{
@@ -167,13 +175,40 @@ func getFlowWorkerContainers(existingWorkerSecrets map[string]v1.Secret, baseLab
 		// Remove from the "existing" list
 		delete(existingWorkerSecrets, workerName)
 
-		workerContainers = append(workerContainers, ensureWorkerContainer(block, w))
+		workerContainers = append(workerContainers, ensureWorkerContainer(block, w, astarte))
 	}
 
 	return existingWorkerSecrets, workerContainers, nil
 }
 
-func ensureWorkerContainer(block flowv2alpha1.ContainerBlockSpec, worker flowv2alpha1.BlockWorker) v1.Container {
+func ensureWorkerContainer(block flowv2alpha1.ContainerBlockSpec, worker flowv2alpha1.BlockWorker, astarte *apiv2alpha1.Astarte) v1.Container {
+	volumeMounts := []v1.VolumeMount{
+		{
+			Name:      "block-config",
+			ReadOnly:  true,
+			MountPath: "/config/block",
+		},
+		{
+			Name:      fmt.Sprintf("worker-%s-config", worker.WorkerID),
+			ReadOnly:  true,
+			MountPath: "/config/worker",
+		},
+	}
+	if astarte.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name != "" {
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      rabbitMQSSLCAVolumeName,
+			ReadOnly:  true,
+			MountPath: rabbitMQSSLCAMountPath,
+		})
+	}
+	if astarte.Spec.RabbitMQ.Connection.SSLConfiguration.ClientCertSecret.Name != "" {
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      rabbitMQSSLClientCertVolumeName,
+			ReadOnly:  true,
+			MountPath: rabbitMQSSLClientCertMountPath,
+		})
+	}
+
 	// Set up a Deployment and reconcile.
 	return v1.Container{
 		// The worker ID, pretty simple
@@ -181,19 +216,8 @@ func ensureWorkerContainer(block flowv2alpha1.ContainerBlockSpec, worker flowv2a
 		Image:           block.Image,
 		Resources:       block.Resources,
 		ImagePullPolicy: v1.PullAlways,
-		VolumeMounts: []v1.VolumeMount{
-			{
-				Name:      "block-config",
-				ReadOnly:  true,
-				MountPath: "/config/block",
-			},
-			{
-				Name:      fmt.Sprintf("worker-%s-config", worker.WorkerID),
-				ReadOnly:  true,
-				MountPath: "/config/worker",
-			},
-		},
-		Env: block.Environment,
+		VolumeMounts:    volumeMounts,
+		Env:             block.Environment,
 		// Flow Containers are a good way to snitch malicious code into the Cluster, and potentially allowing
 		// Container breakout to the node. For this reason, do not trust this container and downscale its privileges
 		// to the bottom.
@@ -232,6 +256,26 @@ func generateVolumesFor(cr *flowv2alpha1.Flow, block flowv2alpha1.ContainerBlock
 		})
 	}
 
+	// Workers connecting to RabbitMQ through SSL need its CA and the client certificate, if any
+	if caSecretName := astarte.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name; caSecretName != "" {
+		ret = append(ret, v1.Volume{
+			Name: rabbitMQSSLCAVolumeName,
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+				SecretName: caSecretName,
+				Items:      []v1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+			}},
+		})
+	}
+	if clientCertSecretName := astarte.Spec.RabbitMQ.Connection.SSLConfiguration.ClientCertSecret.Name; clientCertSecretName != "" {
+		ret = append(ret, v1.Volume{
+			Name: rabbitMQSSLClientCertVolumeName,
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+				SecretName: clientCertSecretName,
+				Items:      []v1.KeyToPath{{Key: v1.TLSCertKey, Path: v1.TLSCertKey}, {Key: v1.TLSPrivateKeyKey, Path: v1.TLSPrivateKeyKey}},
+			}},
+		})
+	}
+
 	return ret
 }
 
@@ -286,18 +330,41 @@ func generateWorkerName(cr *flowv2alpha1.Flow, block flowv2alpha1.ContainerBlock
 }
 
 func generateDefaultRabbitMQConfiguration(astarte *apiv2alpha1.Astarte, c client.Client) (flowv2alpha1.RabbitMQConfig, error) {
-	_, _, username, password, err := misc.GetRabbitMQCredentialsFor(astarte, c)
+	conn, err := misc.GetRabbitMQConnectionFor(astarte, c)
 	if err != nil {
 		return flowv2alpha1.RabbitMQConfig{}, err
 	}
-	host, port := misc.GetRabbitMQHostnameAndPort(astarte)
-
-	return flowv2alpha1.RabbitMQConfig{
-		Host: host,
-		Port: port,
-		// TODO: Change when we start supporting SSL connections to RabbitMQ
-		SSL:      pointy.Bool(false),
-		Username: username,
-		Password: password,
-	}, nil
+
+	return getRabbitMQConfigFor(conn, astarte.Spec.RabbitMQ.Connection.SSLConfiguration), nil
+}
+
+// getRabbitMQConfigFor builds the RabbitMQ configuration of a Flow block out of a resolved connection
+func getRabbitMQConfigFor(conn connstring.AMQP, sslConfiguration apiv2alpha1.GenericSSLConfigurationSpec) flowv2alpha1.RabbitMQConfig {
+	ret := flowv2alpha1.RabbitMQConfig{
+		Host:     conn.Host,
+		Port:     conn.Port,
+		SSL:      pointy.Bool(conn.SSL),
+		Username: conn.Username,
+		Password: conn.Password,
+	}
+	if !conn.SSL {
+		return ret
+	}
+
+	// Mirror the SSL configuration of Astarte components. Certificates are mounted by generateVolumesFor.
+	if sslConfiguration.CustomCASecret.Name != "" {
+		ret.SSLCAFile = rabbitMQSSLCAMountPath + "/ca.crt"
+	}
+	if sslConfiguration.ClientCertSecret.Name != "" {
+		ret.SSLCertFile = rabbitMQSSLClientCertMountPath + "/" + v1.TLSCertKey
+		ret.SSLKeyFile = rabbitMQSSLClientCertMountPath + "/" + v1.TLSPrivateKeyKey
+	}
+	switch {
+	case sslConfiguration.CustomSNI != "":
+		ret.SSLCustomSNI = sslConfiguration.CustomSNI
+	case !pointy.BoolValue(sslConfiguration.SNI, true):
+		ret.SSLDisableSNI = pointy.Bool(true)
+	}
+
+	return ret
 }
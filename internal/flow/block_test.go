@@ -0,0 +1,94 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/connstring"
+)
+
+var _ = Describe("getRabbitMQConfigFor", func() {
+	var conn connstring.AMQP
+
+	BeforeEach(func() {
+		conn = connstring.AMQP{Host: "rabbitmq.example.com", Port: 5671, Username: "astarte", Password: "secret", SSL: true}
+	})
+
+	It("should leave the SSL options out when SSL is disabled", func() {
+		conn.SSL = false
+		sslConfiguration := apiv2alpha1.GenericSSLConfigurationSpec{
+			CustomCASecret: v1.LocalObjectReference{Name: "rabbitmq-ca"},
+			CustomSNI:      "rabbitmq.internal",
+		}
+
+		Expect(getRabbitMQConfigFor(conn, sslConfiguration)).To(Equal(flowv2alpha1.RabbitMQConfig{
+			Host:     "rabbitmq.example.com",
+			Port:     5671,
+			SSL:      pointy.Bool(false),
+			Username: "astarte",
+			Password: "secret",
+		}))
+	})
+
+	It("should enable SSL with the system CAs and SNI by default", func() {
+		Expect(getRabbitMQConfigFor(conn, apiv2alpha1.GenericSSLConfigurationSpec{Enable: true})).To(Equal(flowv2alpha1.RabbitMQConfig{
+			Host:     "rabbitmq.example.com",
+			Port:     5671,
+			SSL:      pointy.Bool(true),
+			Username: "astarte",
+			Password: "secret",
+		}))
+	})
+
+	It("should mount the custom CA and the client certificate", func() {
+		ret := getRabbitMQConfigFor(conn, apiv2alpha1.GenericSSLConfigurationSpec{
+			Enable:           true,
+			CustomCASecret:   v1.LocalObjectReference{Name: "rabbitmq-ca"},
+			ClientCertSecret: v1.LocalObjectReference{Name: "rabbitmq-client"},
+		})
+		Expect(ret.SSLCAFile).To(Equal(rabbitMQSSLCAMountPath + "/ca.crt"))
+		Expect(ret.SSLCertFile).To(Equal(rabbitMQSSLClientCertMountPath + "/tls.crt"))
+		Expect(ret.SSLKeyFile).To(Equal(rabbitMQSSLClientCertMountPath + "/tls.key"))
+	})
+
+	It("should use a custom SNI", func() {
+		ret := getRabbitMQConfigFor(conn, apiv2alpha1.GenericSSLConfigurationSpec{Enable: true, CustomSNI: "rabbitmq.internal"})
+		Expect(ret.SSLCustomSNI).To(Equal("rabbitmq.internal"))
+		Expect(ret.SSLDisableSNI).To(BeNil())
+	})
+
+	It("should disable SNI", func() {
+		ret := getRabbitMQConfigFor(conn, apiv2alpha1.GenericSSLConfigurationSpec{Enable: true, SNI: pointy.Bool(false)})
+		Expect(ret.SSLCustomSNI).To(BeEmpty())
+		Expect(ret.SSLDisableSNI).To(Equal(pointy.Bool(true)))
+	})
+
+	It("should honor the SSL configuration of an amqps connection string", func() {
+		// SSL comes from the connection string, the SSL configuration still tunes it
+		ret := getRabbitMQConfigFor(conn, apiv2alpha1.GenericSSLConfigurationSpec{CustomCASecret: v1.LocalObjectReference{Name: "rabbitmq-ca"}})
+		Expect(ret.SSL).To(Equal(pointy.Bool(true)))
+		Expect(ret.SSLCAFile).To(Equal(rabbitMQSSLCAMountPath + "/ca.crt"))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration rewrites the objects stored by the Operator's CRDs in their storage version, so that
+// older versions can be dropped from the CRDs.
+package migration
+
+import (
+	"context"
+	"slices"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v1beta1"
+	flowv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v1beta1"
+	ingressv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v1beta1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/scope"
+)
+
+var log = logf.Log.WithName("storage_version_migration")
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// storedResource is a CRD managed by the Operator, along with the kind of its storage version
+type storedResource struct {
+	CRD string
+	GVK schema.GroupVersionKind
+}
+
+var storedResources = []storedResource{
+	{CRD: "astartes.api.astarte-platform.org", GVK: apiv1beta1.GroupVersion.WithKind("Astarte")},
+	{CRD: "flows.flow.astarte-platform.org", GVK: flowv1beta1.GroupVersion.WithKind("Flow")},
+	{CRD: "astartedefaultingresses.ingress.astarte-platform.org", GVK: ingressv1beta1.GroupVersion.WithKind("AstarteDefaultIngress")},
+}
+
+// StorageVersionMigrator rewrites all Astarte, Flow and AstarteDefaultIngress objects in the storage version
+// of their CRD, and then leaves only the storage version in the CRD's status.storedVersions. It runs once,
+// whenever the Operator becomes the leader. Its permissions on CRDs are declared in the rbac package.
+type StorageVersionMigrator struct {
+	Client client.Client
+	// Scope restricts the objects being migrated. When restricted, objects outside of the scope might still be
+	// stored in older versions, hence status.storedVersions is left untouched.
+	Scope scope.OperatorScope
+}
+
+// Start implements manager.Runnable. Failures are logged and do not prevent the Operator from working, as the
+// migration is attempted again on the next start.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	for _, r := range storedResources {
+		if err := m.migrate(ctx, r); err != nil {
+			log.Error(err, "Could not migrate stored objects", "crd", r.CRD)
+		}
+	}
+	return nil
+}
+
+func (m *StorageVersionMigrator) migrate(ctx context.Context, r storedResource) error {
+	if !m.Scope.IsRestricted() {
+		// Skip the migration altogether if it already happened
+		storedVersions, err := m.getStoredVersions(ctx, r.CRD)
+		if err != nil {
+			return err
+		}
+		if slices.Equal(storedVersions, []string{r.GVK.Version}) {
+			return nil
+		}
+	}
+
+	migrated, err := m.rewriteObjects(ctx, r.GVK)
+	if err != nil {
+		return err
+	}
+	log.Info("Stored objects migrated", "crd", r.CRD, "version", r.GVK.Version, "objects", migrated)
+
+	if m.Scope.IsRestricted() {
+		log.Info("The Operator scope is restricted, status.storedVersions must be updated manually once all objects are migrated", "crd", r.CRD)
+		return nil
+	}
+	return m.setStoredVersions(ctx, r.CRD, []string{r.GVK.Version})
+}
+
+// rewriteObjects updates all objects of the given kind in scope without changing them, which makes the API server
+// store them again in the storage version. It returns the number of rewritten objects.
+func (m *StorageVersionMigrator) rewriteObjects(ctx context.Context, gvk schema.GroupVersionKind) (int, error) {
+	namespaces := m.Scope.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	migrated := 0
+	for _, namespace := range namespaces {
+		opts := []client.ListOption{client.InNamespace(namespace)}
+		if m.Scope.Selector != nil {
+			opts = append(opts, client.MatchingLabelsSelector{Selector: m.Scope.Selector})
+		}
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := m.Client.List(ctx, list, opts...); err != nil {
+			return migrated, err
+		}
+
+		for i := range list.Items {
+			// Objects which were concurrently updated or deleted need no migration
+			if err := m.Client.Update(ctx, &list.Items[i]); err != nil && !kerrors.IsConflict(err) && !kerrors.IsNotFound(err) {
+				return migrated, err
+			}
+			migrated++
+		}
+	}
+
+	return migrated, nil
+}
+
+func (m *StorageVersionMigrator) getStoredVersions(ctx context.Context, name string) ([]string, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Client.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return nil, err
+	}
+
+	storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	return storedVersions, err
+}
+
+func (m *StorageVersionMigrator) setStoredVersions(ctx context.Context, name string, storedVersions []string) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Client.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(crd.DeepCopy())
+	if err := unstructured.SetNestedStringSlice(crd.Object, storedVersions, "status", "storedVersions"); err != nil {
+		return err
+	}
+	return m.Client.Status().Patch(ctx, crd, patch)
+}
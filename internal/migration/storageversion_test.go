@@ -0,0 +1,102 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressv1beta1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v1beta1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/scope"
+)
+
+var _ = Describe("StorageVersionMigrator", func() {
+	const ingressCRD = "astartedefaultingresses.ingress.astarte-platform.org"
+
+	getStoredVersions := func(name string) []string {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: name}, crd)).To(Succeed())
+		storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+		Expect(err).NotTo(HaveOccurred())
+		return storedVersions
+	}
+
+	setStoredVersions := func(name string, storedVersions ...string) {
+		m := &StorageVersionMigrator{Client: k8sClient}
+		Expect(m.setStoredVersions(context.Background(), name, storedVersions)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		for _, namespace := range []string{"astarte-migration", "other-migration"} {
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			if err := k8sClient.Create(context.Background(), ns); err != nil && !kerrors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			adi := &ingressv1beta1.AstarteDefaultIngress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "example-adi"},
+				Spec:       ingressv1beta1.AstarteDefaultIngressSpec{Astarte: "example-astarte"},
+			}
+			if err := k8sClient.Create(context.Background(), adi); err != nil && !kerrors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+
+		for _, r := range storedResources {
+			setStoredVersions(r.CRD, "v2alpha1", "v1beta1")
+		}
+	})
+
+	It("should migrate all objects and drop older stored versions", func() {
+		m := &StorageVersionMigrator{Client: k8sClient, Scope: scope.OperatorScope{}}
+		Expect(m.Start(context.Background())).To(Succeed())
+
+		for _, r := range storedResources {
+			Expect(getStoredVersions(r.CRD)).To(Equal([]string{"v1beta1"}))
+		}
+	})
+
+	It("should count the objects it rewrites", func() {
+		m := &StorageVersionMigrator{Client: k8sClient, Scope: scope.OperatorScope{}}
+		migrated, err := m.rewriteObjects(context.Background(), ingressv1beta1.GroupVersion.WithKind("AstarteDefaultIngress"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrated).To(Equal(2))
+	})
+
+	It("should only migrate objects in scope when the scope is restricted", func() {
+		restricted, err := scope.Parse("astarte-migration", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		m := &StorageVersionMigrator{Client: k8sClient, Scope: restricted}
+		migrated, err := m.rewriteObjects(context.Background(), ingressv1beta1.GroupVersion.WithKind("AstarteDefaultIngress"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrated).To(Equal(1))
+
+		Expect(m.Start(context.Background())).To(Succeed())
+		Expect(getStoredVersions(ingressCRD)).To(Equal([]string{"v2alpha1", "v1beta1"}))
+	})
+})
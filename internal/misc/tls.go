@@ -0,0 +1,87 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package misc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+)
+
+// GetTLSConfigFor returns the TLS configuration to connect to host as Astarte does, given the SSL configuration
+// of a backing service. Its custom CA and client certificate are loaded from the Secrets in namespace.
+func GetTLSConfigFor(ssl apiv2alpha1.GenericSSLConfigurationSpec, host, namespace string, c client.Client) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+	if ssl.CustomSNI != "" {
+		config.ServerName = ssl.CustomSNI
+	}
+
+	if name := ssl.CustomCASecret.Name; name != "" {
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(secret.Data["ca.crt"]) {
+			return nil, fmt.Errorf("the CA Secret %s holds no valid certificate", name)
+		}
+		config.RootCAs = pool
+	}
+
+	if name := ssl.ClientCertSecret.Name; name != "" {
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			return nil, err
+		}
+		certificate, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("the client certificate Secret %s holds no valid key pair: %w", name, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if ssl.CustomSNI == "" && !pointy.BoolValue(ssl.SNI, true) {
+		// Go sends the server name through SNI whenever it verifies it: verify the certificate on our own instead
+		roots := config.RootCAs
+		config.ServerName = ""
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("the server presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, certificate := range state.PeerCertificates[1:] {
+				intermediates.AddCert(certificate)
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{DNSName: host, Roots: roots, Intermediates: intermediates})
+			return err
+		}
+	}
+
+	return config, nil
+}
@@ -21,6 +21,7 @@ package misc
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"go.openly.dev/pointy"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/connstring"
 )
 
 const (
@@ -44,6 +46,12 @@ const (
 	CassandraDefaultUserCredentialsUsernameKey = "username"
 	// CassandraDefaultUserCredentialsPasswordKey is the default Password key for Cassandra Secret
 	CassandraDefaultUserCredentialsPasswordKey = "password"
+	// ConnectionStringCredentialsUsernameKey is the Username key of the Secrets holding the credentials
+	// extracted from connection strings
+	ConnectionStringCredentialsUsernameKey = "username"
+	// ConnectionStringCredentialsPasswordKey is the Password key of the Secrets holding the credentials
+	// extracted from connection strings
+	ConnectionStringCredentialsPasswordKey = "password"
 )
 
 type allocationCoefficients struct {
@@ -169,7 +177,7 @@ func LogCreateOrUpdateOperationResult(log logr.Logger, result controllerutil.Ope
 
 // GetVerneMQBrokerURL returns the complete URL for VerneMQ (MQTT) for an Astarte resource
 func GetVerneMQBrokerURL(cr *apiv2alpha1.Astarte) string {
-	return fmt.Sprintf("mqtts://%s:%d", cr.Spec.VerneMQ.Host, pointy.Int32Value(cr.Spec.VerneMQ.Port, 8883))
+	return fmt.Sprintf("mqtts://%s:%d", cr.Spec.VerneMQ.Host, pointy.Int32Value(cr.Spec.VerneMQ.Port, apiv2alpha1.DefaultVerneMQPort))
 }
 
 // GetResourcesForAstarteComponent returns the allocated resources for a given Astarte component, taking into account both the
@@ -267,7 +275,7 @@ func getNumberOfDeployedAstarteComponentsAsFloat(cr *apiv2alpha1.Astarte) float6
 	if pointy.BoolValue(cr.Spec.Components.DataUpdaterPlant.Deploy, true) {
 		deployedComponents++
 	}
-	if pointy.BoolValue(cr.Spec.Components.Flow.Deploy, true) {
+	if pointy.BoolValue(cr.Spec.Components.Flow.Deploy, false) {
 		deployedComponents++
 	}
 	if pointy.BoolValue(cr.Spec.Components.Housekeeping.Deploy, true) {
@@ -303,7 +311,8 @@ func getLeftoverCoefficients(cr *apiv2alpha1.Astarte) allocationCoefficients {
 
 func checkComponentForLeftoverAllocations(clusteredResource apiv2alpha1.AstarteGenericClusteredResource,
 	component apiv2alpha1.AstarteComponent, aC allocationCoefficients) allocationCoefficients {
-	if !pointy.BoolValue(clusteredResource.Deploy, true) {
+	// Flow is the only component which is not deployed by default
+	if !pointy.BoolValue(clusteredResource.Deploy, component != apiv2alpha1.FlowComponent) {
 		aC.CPUCoefficient += defaultComponentAllocations[component].CPUCoefficient
 		aC.MemoryCoefficient += defaultComponentAllocations[component].MemoryCoefficient
 	}
@@ -353,13 +362,37 @@ func IsAstarteComponentDeployed(cr *apiv2alpha1.Astarte, component apiv2alpha1.A
 
 // GetRabbitMQHostnameAndPort returns the Cluster-accessible Hostname and AMQP port for RabbitMQ
 func GetRabbitMQHostnameAndPort(cr *apiv2alpha1.Astarte) (string, int32) {
-	return cr.Spec.RabbitMQ.Connection.Host, pointy.Int32Value(cr.Spec.RabbitMQ.Connection.Port, 5672)
+	return cr.Spec.RabbitMQ.Connection.Host, pointy.Int32Value(cr.Spec.RabbitMQ.Connection.Port, apiv2alpha1.DefaultRabbitMQPort)
 }
 
-// GetRabbitMQUserCredentialsSecret gets the secret holding RabbitMQ credentials in the form <secret name>, <username key>, <password key>
-func GetRabbitMQUserCredentialsSecret(cr *apiv2alpha1.Astarte) (string, string, string) {
-	// TODO: allow `connectionStringSecret` to be used too
+// GetRabbitMQVirtualHost returns the RabbitMQ virtual host used by Astarte, defaulting to "/"
+func GetRabbitMQVirtualHost(cr *apiv2alpha1.Astarte) string {
+	if cr.Spec.RabbitMQ.Connection != nil && cr.Spec.RabbitMQ.Connection.VirtualHost != "" {
+		return cr.Spec.RabbitMQ.Connection.VirtualHost
+	}
+	return "/"
+}
 
+// GetRabbitMQManagementURL returns the URL of the RabbitMQ management API, without trailing slashes
+func GetRabbitMQManagementURL(cr *apiv2alpha1.Astarte) string {
+	if cr.Spec.RabbitMQ.ManagementURL != "" {
+		return strings.TrimRight(cr.Spec.RabbitMQ.ManagementURL, "/")
+	}
+
+	host, _ := GetRabbitMQHostnameAndPort(cr)
+	scheme := "http"
+	if cr.Spec.RabbitMQ.Connection.SSLConfiguration.Enable {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:15672", scheme, host)
+}
+
+// GetRabbitMQUserCredentialsSecret gets the secret holding RabbitMQ credentials in the form <secret name>, <username key>, <password key>.
+// When a connection string is used, this is the Secret the Operator extracts the credentials into.
+func GetRabbitMQUserCredentialsSecret(cr *apiv2alpha1.Astarte) (string, string, string) {
+	if cr.Spec.RabbitMQ.Connection.ConnectionStringSecret != nil {
+		return GetRabbitMQConnectionStringCredentialsSecretName(cr), ConnectionStringCredentialsUsernameKey, ConnectionStringCredentialsPasswordKey
+	}
 	if cr.Spec.RabbitMQ.Connection.CredentialsSecret != nil {
 		return cr.Spec.RabbitMQ.Connection.CredentialsSecret.Name, cr.Spec.RabbitMQ.Connection.CredentialsSecret.UsernameKey, cr.Spec.RabbitMQ.Connection.CredentialsSecret.PasswordKey
 	}
@@ -370,23 +403,134 @@ func GetRabbitMQUserCredentialsSecret(cr *apiv2alpha1.Astarte) (string, string,
 // can be used for connecting to RabbitMQ from the Operator or an external agent, and it should not be used for
 // any other purpose.
 func GetRabbitMQCredentialsFor(cr *apiv2alpha1.Astarte, c client.Client) (string, int32, string, string, error) {
+	conn, err := GetRabbitMQConnectionFor(cr, c)
+	if err != nil {
+		return "", 0, "", "", err
+	}
+	return conn.Host, conn.Port, conn.Username, conn.Password, nil
+}
+
+// GetRabbitMQConnectionFor resolves the RabbitMQ connection of a given CR, either from its connection string or from
+// its host, port and credentials. SSL is enabled when the SSL configuration enables it or an amqps connection string
+// implies it. As GetRabbitMQCredentialsFor, it should not be used for anything but connecting to RabbitMQ.
+func GetRabbitMQConnectionFor(cr *apiv2alpha1.Astarte, c client.Client) (connstring.AMQP, error) {
+	sslEnabled := cr.Spec.RabbitMQ.Connection.SSLConfiguration.Enable
+	if cr.Spec.RabbitMQ.Connection.ConnectionStringSecret != nil {
+		conn, err := GetRabbitMQConnectionString(cr, c)
+		if err != nil {
+			return connstring.AMQP{}, err
+		}
+		conn.SSL = conn.SSL || sslEnabled
+		return conn, nil
+	}
+
 	host, port := GetRabbitMQHostnameAndPort(cr)
+	if cr.Spec.RabbitMQ.Connection.UsesClientCertificateOnly() {
+		// The client certificate authenticates us
+		return connstring.AMQP{Host: host, Port: port, SSL: sslEnabled}, nil
+	}
 	secretName, usernameKey, passwordKey := GetRabbitMQUserCredentialsSecret(cr)
 
 	// Fetch the Secret
 	secret := &v1.Secret{}
 	if err := c.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cr.Namespace}, secret); err != nil {
-		return "", 0, "", "", err
+		return connstring.AMQP{}, err
 	}
 
-	return host, port, string(secret.Data[usernameKey]), string(secret.Data[passwordKey]), nil
+	return connstring.AMQP{
+		Host:     host,
+		Port:     port,
+		Username: string(secret.Data[usernameKey]),
+		Password: string(secret.Data[passwordKey]),
+		SSL:      sslEnabled,
+	}, nil
 }
 
-// GetCassandraUserCredentialsSecret gets the secret holding Cassandra credentials in the form <secret name>, <username key>, <password key>
+// GetCassandraUserCredentialsSecret gets the secret holding Cassandra credentials in the form <secret name>, <username key>, <password key>.
+// When a connection string is used, this is the Secret the Operator extracts the credentials into.
 func GetCassandraUserCredentialsSecret(cr *apiv2alpha1.Astarte) (string, string, string) {
-	// TODO: allow `connectionStringSecret` to be used too
+	if cr.Spec.Cassandra.Connection.ConnectionStringSecret != nil {
+		return GetCassandraConnectionStringCredentialsSecretName(cr), ConnectionStringCredentialsUsernameKey, ConnectionStringCredentialsPasswordKey
+	}
 	if cr.Spec.Cassandra.Connection.CredentialsSecret != nil {
 		return cr.Spec.Cassandra.Connection.CredentialsSecret.Name, cr.Spec.Cassandra.Connection.CredentialsSecret.UsernameKey, cr.Spec.Cassandra.Connection.CredentialsSecret.PasswordKey
 	}
 	return cr.Name + "-cassandra-user-credentials", CassandraDefaultUserCredentialsUsernameKey, CassandraDefaultUserCredentialsPasswordKey
 }
+
+// GetCassandraCredentialsFor returns the Cassandra username and password for a given CR, empty when Astarte is not
+// given any. As GetRabbitMQCredentialsFor, it should be used for connecting to Cassandra from the Operator only.
+func GetCassandraCredentialsFor(cr *apiv2alpha1.Astarte, c client.Client) (string, string, error) {
+	connection := cr.Spec.Cassandra.Connection
+	switch {
+	case connection.ConnectionStringSecret != nil:
+		conn, err := GetCassandraConnectionString(cr, c)
+		if err != nil {
+			return "", "", err
+		}
+		return conn.Username, conn.Password, nil
+	case connection.CredentialsSecret != nil:
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: connection.CredentialsSecret.Name, Namespace: cr.Namespace}, secret); err != nil {
+			return "", "", err
+		}
+		return string(secret.Data[connection.CredentialsSecret.UsernameKey]), string(secret.Data[connection.CredentialsSecret.PasswordKey]), nil
+	}
+
+	return "", "", nil
+}
+
+// GetRabbitMQConnectionStringCredentialsSecretName returns the name of the Secret holding the credentials extracted
+// from the RabbitMQ connection string
+func GetRabbitMQConnectionStringCredentialsSecretName(cr *apiv2alpha1.Astarte) string {
+	return cr.Name + "-rabbitmq-connection-string-credentials"
+}
+
+// GetCassandraConnectionStringCredentialsSecretName returns the name of the Secret holding the credentials extracted
+// from the Cassandra connection string
+func GetCassandraConnectionStringCredentialsSecretName(cr *apiv2alpha1.Astarte) string {
+	return cr.Name + "-cassandra-connection-string-credentials"
+}
+
+// GetRabbitMQConnectionString reads and parses the RabbitMQ connection string of the given CR, which must have one
+func GetRabbitMQConnectionString(cr *apiv2alpha1.Astarte, c client.Client) (connstring.AMQP, error) {
+	ref := cr.Spec.RabbitMQ.Connection.ConnectionStringSecret
+	value, err := getSecretValue(ref.Name, ref.Key, cr.Namespace, c)
+	if err != nil {
+		return connstring.AMQP{}, err
+	}
+
+	conn, err := connstring.ParseAMQPURI(value)
+	if err != nil {
+		return connstring.AMQP{}, fmt.Errorf("the Secret %s holds an invalid RabbitMQ connection string: %w", ref.Name, err)
+	}
+	return conn, nil
+}
+
+// GetCassandraConnectionString reads and parses the Cassandra connection string of the given CR, which must have one
+func GetCassandraConnectionString(cr *apiv2alpha1.Astarte, c client.Client) (connstring.Cassandra, error) {
+	ref := cr.Spec.Cassandra.Connection.ConnectionStringSecret
+	value, err := getSecretValue(ref.Name, ref.Key, cr.Namespace, c)
+	if err != nil {
+		return connstring.Cassandra{}, err
+	}
+
+	conn, err := connstring.ParseCassandraContactPoints(value)
+	if err != nil {
+		return connstring.Cassandra{}, fmt.Errorf("the Secret %s holds an invalid Cassandra connection string: %w", ref.Name, err)
+	}
+	return conn, nil
+}
+
+func getSecretValue(name, key, namespace string, c client.Client) (string, error) {
+	secret := &v1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("the Secret %s has no %s key", name, key)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,289 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rabbitmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// The bits of AMQP 0-9-1 needed to log in, see https://www.rabbitmq.com/resources/specs/amqp0-9-1.pdf
+const (
+	amqpFrameMethod    = 1
+	amqpFrameHeartbeat = 8
+	amqpFrameEnd       = 0xCE
+	amqpMaxFrameSize   = 1 << 20
+
+	amqpClassConnection = 10
+
+	amqpMethodStart   = 10
+	amqpMethodStartOk = 11
+	amqpMethodTune    = 30
+	amqpMethodTuneOk  = 31
+	amqpMethodOpen    = 40
+	amqpMethodOpenOk  = 41
+	amqpMethodClose   = 50
+	amqpMethodCloseOk = 51
+
+	amqpReplySuccess  = 200
+	amqpAccessRefused = 403
+	amqpNotAllowed    = 530
+)
+
+// ErrAccessRefused is returned when RabbitMQ refuses the credentials, or the access to the virtual host
+var ErrAccessRefused = errors.New("access refused")
+
+// CheckAMQPLogin logs in to RabbitMQ through conn and opens the given virtual host, closing the AMQP connection
+// right away. An empty username logs in through the EXTERNAL mechanism, i.e. by means of the client certificate.
+// As the exchange might block, conn should have a deadline.
+func CheckAMQPLogin(conn io.ReadWriter, vhost, username, password string) error {
+	if _, err := conn.Write([]byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}); err != nil {
+		return err
+	}
+
+	args, err := readAMQPMethod(conn, amqpMethodStart)
+	if err != nil {
+		return err
+	}
+	mechanisms, err := parseAMQPConnectionStart(args)
+	if err != nil {
+		return err
+	}
+
+	mechanism, response := "PLAIN", "\x00"+username+"\x00"+password
+	if username == "" {
+		mechanism, response = "EXTERNAL", ""
+	}
+	if !slices.Contains(strings.Fields(mechanisms), mechanism) {
+		return fmt.Errorf("RabbitMQ does not support the %s authentication mechanism", mechanism)
+	}
+
+	startOk := newAMQPMethod(amqpMethodStartOk)
+	startOk.table(map[string]any{
+		"product": "Astarte Operator",
+		// Have RabbitMQ tell us why the login failed, rather than just closing the connection
+		"capabilities": map[string]any{"authentication_failure_close": true},
+	})
+	startOk.shortString(mechanism)
+	startOk.longString(response)
+	startOk.shortString("en_US")
+	if err := writeAMQPMethod(conn, startOk); err != nil {
+		return err
+	}
+
+	args, err = readAMQPMethod(conn, amqpMethodTune)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: RabbitMQ closed the connection while logging in", ErrAccessRefused)
+	} else if err != nil {
+		return err
+	}
+	if len(args) < 8 {
+		return errors.New("malformed AMQP Connection.Tune")
+	}
+
+	// Agree on whatever RabbitMQ proposes, but heartbeats: we're leaving soon
+	tuneOk := newAMQPMethod(amqpMethodTuneOk)
+	tuneOk.Write(args[0:6])
+	tuneOk.short(0)
+	if err := writeAMQPMethod(conn, tuneOk); err != nil {
+		return err
+	}
+
+	open := newAMQPMethod(amqpMethodOpen)
+	open.shortString(vhost)
+	open.shortString("")
+	open.WriteByte(0)
+	if err := writeAMQPMethod(conn, open); err != nil {
+		return err
+	}
+	if _, err := readAMQPMethod(conn, amqpMethodOpenOk); err != nil {
+		return err
+	}
+
+	// We're in. Say goodbye, without caring much about the answer.
+	closeMethod := newAMQPMethod(amqpMethodClose)
+	closeMethod.short(amqpReplySuccess)
+	closeMethod.shortString("")
+	closeMethod.short(0)
+	closeMethod.short(0)
+	if err := writeAMQPMethod(conn, closeMethod); err == nil {
+		_, _ = readAMQPMethod(conn, amqpMethodCloseOk)
+	}
+
+	return nil
+}
+
+// amqpBuffer encodes the arguments of an AMQP method
+type amqpBuffer struct {
+	bytes.Buffer
+}
+
+func newAMQPMethod(method uint16) *amqpBuffer {
+	b := &amqpBuffer{}
+	b.short(amqpClassConnection)
+	b.short(method)
+	return b
+}
+
+func (b *amqpBuffer) short(v uint16) {
+	_ = binary.Write(b, binary.BigEndian, v)
+}
+
+func (b *amqpBuffer) long(v uint32) {
+	_ = binary.Write(b, binary.BigEndian, v)
+}
+
+func (b *amqpBuffer) shortString(s string) {
+	b.WriteByte(byte(len(s)))
+	b.WriteString(s)
+}
+
+func (b *amqpBuffer) longString(s string) {
+	b.long(uint32(len(s)))
+	b.WriteString(s)
+}
+
+// table encodes a field table holding strings, booleans and nested tables only
+func (b *amqpBuffer) table(t map[string]any) {
+	fields := &amqpBuffer{}
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fields.shortString(k)
+		switch v := t[k].(type) {
+		case string:
+			fields.WriteByte('S')
+			fields.longString(v)
+		case bool:
+			fields.WriteByte('t')
+			if v {
+				fields.WriteByte(1)
+			} else {
+				fields.WriteByte(0)
+			}
+		case map[string]any:
+			fields.WriteByte('F')
+			fields.table(v)
+		}
+	}
+	b.long(uint32(fields.Len()))
+	b.Write(fields.Bytes())
+}
+
+func writeAMQPMethod(w io.Writer, method *amqpBuffer) error {
+	frame := &amqpBuffer{}
+	frame.WriteByte(amqpFrameMethod)
+	frame.short(0)
+	frame.long(uint32(method.Len()))
+	frame.Write(method.Bytes())
+	frame.WriteByte(amqpFrameEnd)
+	_, err := w.Write(frame.Bytes())
+	return err
+}
+
+// readAMQPMethod reads the next method on channel 0, returning its arguments if it is the expected one.
+// Connection.Close is turned into an error.
+func readAMQPMethod(r io.Reader, expected uint16) ([]byte, error) {
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		size := binary.BigEndian.Uint32(header[3:7])
+		if size > amqpMaxFrameSize {
+			return nil, fmt.Errorf("AMQP frame too large: %d bytes", size)
+		}
+		payload := make([]byte, size+1)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		if payload[size] != amqpFrameEnd {
+			return nil, errors.New("malformed AMQP frame")
+		}
+		payload = payload[:size]
+
+		switch header[0] {
+		case amqpFrameHeartbeat:
+			continue
+		case amqpFrameMethod:
+		default:
+			return nil, fmt.Errorf("unexpected AMQP frame type %d", header[0])
+		}
+
+		if len(payload) < 4 {
+			return nil, errors.New("malformed AMQP method")
+		}
+		class, method := binary.BigEndian.Uint16(payload[0:2]), binary.BigEndian.Uint16(payload[2:4])
+		switch {
+		case class == amqpClassConnection && method == expected:
+			return payload[4:], nil
+		case class == amqpClassConnection && method == amqpMethodClose:
+			return nil, parseAMQPConnectionClose(payload[4:])
+		default:
+			return nil, fmt.Errorf("unexpected AMQP method %d.%d", class, method)
+		}
+	}
+}
+
+// parseAMQPConnectionStart returns the authentication mechanisms supported by RabbitMQ
+func parseAMQPConnectionStart(args []byte) (string, error) {
+	r := bytes.NewReader(args)
+	// Skip the protocol version
+	if _, err := r.Seek(2, io.SeekCurrent); err != nil {
+		return "", err
+	}
+	// Skip the server properties, too
+	var propertiesSize uint32
+	if err := binary.Read(r, binary.BigEndian, &propertiesSize); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(int64(propertiesSize), io.SeekCurrent); err != nil {
+		return "", err
+	}
+
+	var mechanismsSize uint32
+	if err := binary.Read(r, binary.BigEndian, &mechanismsSize); err != nil {
+		return "", err
+	}
+	if int(mechanismsSize) > r.Len() {
+		return "", errors.New("malformed AMQP Connection.Start")
+	}
+	mechanisms := make([]byte, mechanismsSize)
+	_, _ = r.Read(mechanisms)
+	return string(mechanisms), nil
+}
+
+func parseAMQPConnectionClose(args []byte) error {
+	if len(args) < 3 || len(args) < 3+int(args[2]) {
+		return errors.New("RabbitMQ closed the connection")
+	}
+	code, text := binary.BigEndian.Uint16(args[0:2]), string(args[3:3+int(args[2])])
+	if code == amqpAccessRefused || code == amqpNotAllowed {
+		return fmt.Errorf("%w: %s", ErrAccessRefused, text)
+	}
+	return fmt.Errorf("RabbitMQ closed the connection: %d %s", code, text)
+}
@@ -0,0 +1,171 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rabbitmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeBroker answers a single client as RabbitMQ would, accepting the given credentials on the given virtual host
+type fakeBroker struct {
+	vhost, username, password string
+	mechanisms                string
+	// When true, refused logins just close the connection, as when the client misses authentication_failure_close
+	dropOnRefusal bool
+}
+
+func (b fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	if _, err := io.ReadFull(conn, make([]byte, 8)); err != nil {
+		return
+	}
+
+	start := newAMQPMethod(amqpMethodStart)
+	start.Write([]byte{0, 9})
+	start.table(map[string]any{"product": "RabbitMQ"})
+	start.longString(b.mechanisms)
+	start.longString("en_US")
+	_ = writeAMQPMethod(conn, start)
+
+	args, err := readAMQPMethod(conn, amqpMethodStartOk)
+	if err != nil {
+		return
+	}
+	r := bytes.NewReader(args)
+	var tableSize uint32
+	_ = binary.Read(r, binary.BigEndian, &tableSize)
+	_, _ = r.Seek(int64(tableSize), io.SeekCurrent)
+	mechanism := make([]byte, b.readByte(r))
+	_, _ = r.Read(mechanism)
+	var responseSize uint32
+	_ = binary.Read(r, binary.BigEndian, &responseSize)
+	response := make([]byte, responseSize)
+	_, _ = r.Read(response)
+
+	expected := "\x00" + b.username + "\x00" + b.password
+	if string(mechanism) == "EXTERNAL" {
+		expected = ""
+	}
+	if string(response) != expected {
+		if !b.dropOnRefusal {
+			b.close(conn, amqpAccessRefused, "ACCESS_REFUSED - Login was refused using authentication mechanism "+string(mechanism))
+		}
+		return
+	}
+
+	// A heartbeat the client has to skip
+	_, _ = conn.Write([]byte{amqpFrameHeartbeat, 0, 0, 0, 0, 0, 0, amqpFrameEnd})
+	tune := newAMQPMethod(amqpMethodTune)
+	tune.short(2047)
+	tune.long(131072)
+	tune.short(60)
+	_ = writeAMQPMethod(conn, tune)
+
+	if _, err := readAMQPMethod(conn, amqpMethodTuneOk); err != nil {
+		return
+	}
+	args, err = readAMQPMethod(conn, amqpMethodOpen)
+	if err != nil {
+		return
+	}
+	if vhost := string(args[1 : 1+args[0]]); vhost != b.vhost {
+		b.close(conn, amqpNotAllowed, "NOT_ALLOWED - vhost "+vhost+" not found")
+		return
+	}
+	openOk := newAMQPMethod(amqpMethodOpenOk)
+	openOk.shortString("")
+	_ = writeAMQPMethod(conn, openOk)
+
+	if _, err := readAMQPMethod(conn, amqpMethodClose); err == nil {
+		_ = writeAMQPMethod(conn, newAMQPMethod(amqpMethodCloseOk))
+	}
+}
+
+func (b fakeBroker) readByte(r *bytes.Reader) byte {
+	v, _ := r.ReadByte()
+	return v
+}
+
+func (b fakeBroker) close(conn net.Conn, code uint16, text string) {
+	closeMethod := newAMQPMethod(amqpMethodClose)
+	closeMethod.short(code)
+	closeMethod.shortString(text)
+	closeMethod.short(amqpClassConnection)
+	closeMethod.short(amqpMethodStartOk)
+	_ = writeAMQPMethod(conn, closeMethod)
+}
+
+var _ = Describe("AMQP login testing", func() {
+	broker := fakeBroker{vhost: "astarte", username: "astarte", password: "s3cr3t", mechanisms: "AMQPLAIN PLAIN"}
+
+	connectTo := func(b fakeBroker) net.Conn {
+		client, server := net.Pipe()
+		DeferCleanup(client.Close)
+		go b.serve(server)
+		return client
+	}
+
+	Describe("Test CheckAMQPLogin", func() {
+		It("should log in with the right credentials", func() {
+			Expect(CheckAMQPLogin(connectTo(broker), "astarte", "astarte", "s3cr3t")).To(Succeed())
+		})
+
+		It("should fail with the wrong credentials", func() {
+			err := CheckAMQPLogin(connectTo(broker), "astarte", "astarte", "wrong")
+			Expect(err).To(MatchError(ErrAccessRefused))
+			Expect(err.Error()).To(ContainSubstring("Login was refused"))
+		})
+
+		It("should fail when RabbitMQ drops the connection while logging in", func() {
+			dropping := broker
+			dropping.dropOnRefusal = true
+			Expect(CheckAMQPLogin(connectTo(dropping), "astarte", "astarte", "wrong")).To(MatchError(ErrAccessRefused))
+		})
+
+		It("should fail when the virtual host cannot be accessed", func() {
+			err := CheckAMQPLogin(connectTo(broker), "other", "astarte", "s3cr3t")
+			Expect(err).To(MatchError(ErrAccessRefused))
+			Expect(err.Error()).To(ContainSubstring("vhost other not found"))
+		})
+
+		It("should log in through the client certificate without credentials", func() {
+			external := broker
+			external.mechanisms = "EXTERNAL"
+			Expect(CheckAMQPLogin(connectTo(external), "astarte", "", "")).To(Succeed())
+			Expect(CheckAMQPLogin(connectTo(broker), "astarte", "", "")).To(MatchError(ContainSubstring("EXTERNAL")))
+		})
+
+		It("should fail when the peer does not speak AMQP", func() {
+			client, server := net.Pipe()
+			DeferCleanup(client.Close)
+			go func() {
+				_, _ = io.ReadFull(server, make([]byte, 8))
+				_, _ = server.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+				server.Close()
+			}()
+			Expect(CheckAMQPLogin(client, "astarte", "astarte", "s3cr3t")).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,276 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rabbitmq implements the bits of the RabbitMQ management API the Operator relies upon.
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+)
+
+const managementAPITimeout = 10 * time.Second
+
+// ManagementClient is a minimal client for the RabbitMQ management API
+type ManagementClient struct {
+	BaseURL    string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+// Queue is a RabbitMQ queue, as reported by the management API
+type Queue struct {
+	Name string `json:"name"`
+	// Messages is the number of ready and unacknowledged messages
+	Messages  int64 `json:"messages"`
+	Consumers int32 `json:"consumers"`
+}
+
+// User is a RabbitMQ user, as reported by the management API
+type User struct {
+	Name             string `json:"name"`
+	PasswordHash     string `json:"password_hash"`
+	HashingAlgorithm string `json:"hashing_algorithm"`
+	// Tags are a string in older RabbitMQ versions, and a list in newer ones: keep them as they are
+	Tags json.RawMessage `json:"tags,omitempty"`
+}
+
+// HasPassword returns whether password is the password of the user. Passwords hashed through an unknown
+// algorithm never match.
+func (u *User) HasPassword(password string) bool {
+	var h hash.Hash
+	switch u.HashingAlgorithm {
+	case "rabbit_password_hashing_sha256":
+		h = sha256.New()
+	case "rabbit_password_hashing_sha512":
+		h = sha512.New()
+	default:
+		return false
+	}
+
+	// The hash is base64(salt + hash(salt + password)), with a 4 bytes salt
+	decoded, err := base64.StdEncoding.DecodeString(u.PasswordHash)
+	if err != nil || len(decoded) <= 4 {
+		return false
+	}
+	h.Write(decoded[:4])
+	h.Write([]byte(password))
+	return subtle.ConstantTimeCompare(h.Sum(nil), decoded[4:]) == 1
+}
+
+// Permissions are the permissions of a user in a virtual host, as regular expressions over resource names
+type Permissions struct {
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+// Policy is a RabbitMQ policy, as reported by the management API
+type Policy struct {
+	Pattern    string                 `json:"pattern"`
+	ApplyTo    string                 `json:"apply-to"`
+	Priority   int32                  `json:"priority"`
+	Definition map[string]interface{} `json:"definition"`
+}
+
+// ErrNotFound is returned when the requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// NewManagementClientFor returns a ManagementClient for the RabbitMQ instance used by the given Astarte
+func NewManagementClientFor(cr *apiv2alpha1.Astarte, c client.Client) (*ManagementClient, error) {
+	_, _, username, password, err := misc.GetRabbitMQCredentialsFor(cr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return newManagementClient(cr, c, misc.GetRabbitMQManagementURL(cr), username, password)
+}
+
+// NewProvisioningClientFor returns a ManagementClient logged in as the RabbitMQ administrator given for provisioning
+func NewProvisioningClientFor(cr *apiv2alpha1.Astarte, c client.Client) (*ManagementClient, error) {
+	provisioning := cr.Spec.RabbitMQ.Provisioning
+	if provisioning == nil || provisioning.AdminCredentialsSecret == nil {
+		return nil, errors.New("RabbitMQ provisioning is not configured")
+	}
+
+	adminSecret := &v1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: provisioning.AdminCredentialsSecret.Name, Namespace: cr.Namespace}, adminSecret); err != nil {
+		return nil, err
+	}
+
+	baseURL := misc.GetRabbitMQManagementURL(cr)
+	if provisioning.ManagementURL != "" {
+		baseURL = strings.TrimRight(provisioning.ManagementURL, "/")
+	}
+	return newManagementClient(cr, c, baseURL, string(adminSecret.Data[provisioning.AdminCredentialsSecret.UsernameKey]),
+		string(adminSecret.Data[provisioning.AdminCredentialsSecret.PasswordKey]))
+}
+
+func newManagementClient(cr *apiv2alpha1.Astarte, c client.Client, baseURL, username, password string) (*ManagementClient, error) {
+	httpClient := &http.Client{Timeout: managementAPITimeout}
+	if caSecretName := cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name; caSecretName != "" {
+		caSecret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: caSecretName, Namespace: cr.Namespace}, caSecret); err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caSecret.Data["ca.crt"]) {
+			return nil, fmt.Errorf("the RabbitMQ CA Secret %s holds no valid certificate", caSecretName)
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	}
+
+	return &ManagementClient{
+		BaseURL:    baseURL,
+		Username:   username,
+		Password:   password,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// ListQueues returns all queues in the given virtual host
+func (m *ManagementClient) ListQueues(ctx context.Context, vhost string) ([]Queue, error) {
+	queues := []Queue{}
+	if err := m.get(ctx, "/api/queues/"+url.PathEscape(vhost)+"?columns=name,messages,consumers", &queues); err != nil {
+		return nil, err
+	}
+	return queues, nil
+}
+
+// GetVirtualHost returns ErrNotFound if the given virtual host does not exist
+func (m *ManagementClient) GetVirtualHost(ctx context.Context, vhost string) error {
+	return m.do(ctx, http.MethodGet, "/api/vhosts/"+url.PathEscape(vhost), nil, &map[string]interface{}{})
+}
+
+// PutVirtualHost creates the given virtual host, if needed
+func (m *ManagementClient) PutVirtualHost(ctx context.Context, vhost string) error {
+	return m.do(ctx, http.MethodPut, "/api/vhosts/"+url.PathEscape(vhost), map[string]interface{}{}, nil)
+}
+
+// GetUser returns the given user, or ErrNotFound
+func (m *ManagementClient) GetUser(ctx context.Context, name string) (*User, error) {
+	user := &User{}
+	if err := m.do(ctx, http.MethodGet, "/api/users/"+url.PathEscape(name), nil, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PutUser creates or updates the given user, setting its password and tags
+func (m *ManagementClient) PutUser(ctx context.Context, name, password string, tags json.RawMessage) error {
+	if len(tags) == 0 {
+		tags = json.RawMessage(`""`)
+	}
+	return m.do(ctx, http.MethodPut, "/api/users/"+url.PathEscape(name), map[string]interface{}{"password": password, "tags": tags}, nil)
+}
+
+// GetPermissions returns the permissions of the given user in the given virtual host, or ErrNotFound
+func (m *ManagementClient) GetPermissions(ctx context.Context, vhost, user string) (*Permissions, error) {
+	permissions := &Permissions{}
+	if err := m.do(ctx, http.MethodGet, "/api/permissions/"+url.PathEscape(vhost)+"/"+url.PathEscape(user), nil, permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// PutPermissions sets the permissions of the given user in the given virtual host
+func (m *ManagementClient) PutPermissions(ctx context.Context, vhost, user string, permissions Permissions) error {
+	return m.do(ctx, http.MethodPut, "/api/permissions/"+url.PathEscape(vhost)+"/"+url.PathEscape(user), permissions, nil)
+}
+
+// GetPolicy returns the given policy of the given virtual host, or ErrNotFound
+func (m *ManagementClient) GetPolicy(ctx context.Context, vhost, name string) (*Policy, error) {
+	policy := &Policy{}
+	if err := m.do(ctx, http.MethodGet, "/api/policies/"+url.PathEscape(vhost)+"/"+url.PathEscape(name), nil, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// PutPolicy creates or updates the given policy of the given virtual host
+func (m *ManagementClient) PutPolicy(ctx context.Context, vhost, name string, policy Policy) error {
+	return m.do(ctx, http.MethodPut, "/api/policies/"+url.PathEscape(vhost)+"/"+url.PathEscape(name), policy, nil)
+}
+
+func (m *ManagementClient) get(ctx context.Context, path string, out interface{}) error {
+	return m.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// do sends a request to the management API, encoding in as its body and decoding the response into out, when not nil
+func (m *ManagementClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.Username, m.Password)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("RabbitMQ management API returned %s: %s", resp.Status, errBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,154 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rabbitmq
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("RabbitMQ management API testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "rabbitmq-management-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+	var server *httptest.Server
+	var requestedPath string
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || username != "astarte" || password != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			requestedPath = r.URL.EscapedPath()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"name":"astarte_data_0","messages":12,"consumers":1},{"name":"other","messages":3,"consumers":0}]`))
+		}))
+	})
+
+	AfterAll(func() {
+		server.Close()
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.RabbitMQ.ManagementURL = server.URL + "/"
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test ListQueues", func() {
+		It("should list the queues of the virtual host with the Astarte credentials", func() {
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-connection-secret", Namespace: CustomAstarteNamespace},
+				Data:       map[string][]byte{"username": []byte("astarte"), "password": []byte("s3cr3t")},
+			}
+			Expect(k8sClient.Create(context.Background(), secret)).To(Succeed())
+
+			managementClient, err := NewManagementClientFor(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(managementClient.BaseURL).To(Equal(server.URL))
+
+			queues, err := managementClient.ListQueues(context.Background(), "/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requestedPath).To(Equal("/api/queues/%2F"))
+			Expect(queues).To(HaveLen(2))
+			Expect(queues[0]).To(Equal(Queue{Name: "astarte_data_0", Messages: 12, Consumers: 1}))
+		})
+
+		It("should fail when the credentials are wrong", func() {
+			managementClient := &ManagementClient{BaseURL: server.URL, Username: "astarte", Password: "wrong", HTTPClient: http.DefaultClient}
+			_, err := managementClient.ListQueues(context.Background(), "/")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail when the credentials Secret is missing", func() {
+			_, err := NewManagementClientFor(cr, k8sClient)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Test User.HasPassword", func() {
+		hashPassword := func(password string) string {
+			salt := []byte{0xCA, 0xFE, 0xBA, 0xBE}
+			sum := sha256.Sum256(append(salt, []byte(password)...))
+			return base64.StdEncoding.EncodeToString(append(salt, sum[:]...))
+		}
+
+		It("should verify passwords hashed as RabbitMQ does", func() {
+			user := &User{Name: "astarte", PasswordHash: hashPassword("s3cr3t"), HashingAlgorithm: "rabbit_password_hashing_sha256"}
+			Expect(user.HasPassword("s3cr3t")).To(BeTrue())
+			Expect(user.HasPassword("wrong")).To(BeFalse())
+		})
+
+		It("should never match unknown hashing algorithms or users without a password", func() {
+			user := &User{Name: "astarte", PasswordHash: hashPassword("s3cr3t"), HashingAlgorithm: "rabbit_password_hashing_md5"}
+			Expect(user.HasPassword("s3cr3t")).To(BeFalse())
+			user = &User{Name: "astarte", HashingAlgorithm: "rabbit_password_hashing_sha256"}
+			Expect(user.HasPassword("")).To(BeFalse())
+		})
+	})
+
+	Describe("Test NewProvisioningClientFor", func() {
+		It("should log in as the administrator, through the provisioning management URL", func() {
+			Expect(k8sClient.Create(context.Background(), &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-admin", Namespace: CustomAstarteNamespace},
+				Data:       map[string][]byte{"user": []byte("admin"), "pass": []byte("admin-pass")},
+			})).To(Succeed())
+			cr.Spec.RabbitMQ.Provisioning = &apiv2alpha1.AstarteRabbitMQProvisioningSpec{
+				AdminCredentialsSecret: &apiv2alpha1.LoginCredentialsSecret{Name: "rabbitmq-admin", UsernameKey: "user", PasswordKey: "pass"},
+				ManagementURL:          "http://rabbitmq-admin:15672/",
+			}
+
+			managementClient, err := NewProvisioningClientFor(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(managementClient.BaseURL).To(Equal("http://rabbitmq-admin:15672"))
+			Expect(managementClient.Username).To(Equal("admin"))
+			Expect(managementClient.Password).To(Equal("admin-pass"))
+		})
+
+		It("should fail when provisioning is not configured", func() {
+			_, err := NewProvisioningClientFor(cr, k8sClient)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -63,8 +63,8 @@ func EnsureAstarteDashboard(cr *apiv2alpha1.Astarte, dashboard apiv2alpha1.Astar
 			}
 		}
 
-		// That would be all for today.
-		return nil
+		// Drop the HPA as well, if we own one. That would be all for today.
+		return deleteHorizontalPodAutoscalerIfExists(deploymentName, cr, c)
 	}
 
 	// Good. Reconcile the ConfigMap.
@@ -120,8 +120,9 @@ func EnsureAstarteDashboard(cr *apiv2alpha1.Astarte, dashboard apiv2alpha1.Astar
 
 		// Assign the Spec.
 		deployment.ObjectMeta.Labels = labels
+		currentReplicas := deployment.Spec.Replicas
 		deployment.Spec = deploymentSpec
-		deployment.Spec.Replicas = getReplicaCountForResource(&dashboard.AstarteGenericClusteredResource, cr, c, reqLogger)
+		deployment.Spec.Replicas = getReplicaCountForResource(&dashboard.AstarteGenericClusteredResource, currentReplicas, cr, c, reqLogger)
 
 		return nil
 	})
@@ -130,7 +131,9 @@ func EnsureAstarteDashboard(cr *apiv2alpha1.Astarte, dashboard apiv2alpha1.Astar
 	}
 
 	misc.LogCreateOrUpdateOperationResult(log, result, cr, deployment)
-	return nil
+
+	// Last but not least, the HPA, if any
+	return ensureHorizontalPodAutoscaler(deploymentName, "Deployment", dashboard.AstarteGenericClusteredResource, cr, c, scheme)
 }
 
 func getAstarteDashboardPodSpec(cr *apiv2alpha1.Astarte, dashboard apiv2alpha1.AstarteDashboardSpec) v1.PodSpec {
@@ -159,13 +162,13 @@ func getAstarteDashboardPodSpec(cr *apiv2alpha1.Astarte, dashboard apiv2alpha1.A
 		// is a priorityClass specified in the Astarte CR?
 		switch dashboard.PriorityClass {
 		case highPriority:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		case midPriority:
-			ps.PriorityClassName = AstarteMidPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteMidPriorityName)
 		case lowPriority:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		default:
-			ps.PriorityClassName = GetDefaultAstartePriorityClassNameForComponent(component)
+			ps.PriorityClassName = getAstartePriorityClassName(cr, GetDefaultAstartePriorityClassNameForComponent(component))
 		}
 	}
 
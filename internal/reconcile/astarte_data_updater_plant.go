@@ -20,11 +20,15 @@ package reconcile
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"go.openly.dev/pointy"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,40 +38,64 @@ import (
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
 )
 
-// EnsureAstarteDataUpdaterPlant manages multiple deployments for Astarte Data Updater Plant based on scalability requirements
+const (
+	dataUpdaterPlantQueueRangeStartEnvVar = "DATA_UPDATER_PLANT_AMQP_DATA_QUEUE_RANGE_START"
+	dataUpdaterPlantQueueRangeEndEnvVar   = "DATA_UPDATER_PLANT_AMQP_DATA_QUEUE_RANGE_END"
+)
+
+// queueRange is an inclusive range of data queues. It is empty when start > end.
+type queueRange struct {
+	start int
+	end   int
+}
+
+func (r queueRange) isEmpty() bool {
+	return r.start > r.end
+}
+
+func (r queueRange) intersect(other queueRange) queueRange {
+	return queueRange{start: max(r.start, other.start), end: min(r.end, other.end)}
+}
+
+// dataUpdaterPlantShard is a Data Updater Plant shard, as observed in the cluster
+type dataUpdaterPlantShard struct {
+	index      int
+	deployment *appsv1.Deployment
+	// The data queues the shard consumes from. Meaningless when the shard is drained.
+	queues    queueRange
+	drained   bool
+	rolledOut bool
+}
+
+// EnsureAstarteDataUpdaterPlant manages multiple deployments for Astarte Data Updater Plant based on scalability requirements.
+// Every shard consumes from its own range of data queues. When the number of shards changes, queues are moved one shard
+// at a time, so that no data queue is ever consumed by more than one shard.
 func EnsureAstarteDataUpdaterPlant(cr *apiv2alpha1.Astarte, dup apiv2alpha1.AstarteDataUpdaterPlantSpec, c client.Client, scheme *runtime.Scheme) error {
-	replicas := pointy.Int32Value(dup.AstarteGenericClusteredResource.Replicas, 1)
+	shards := int(getDataUpdaterPlantShardCount(cr, dup))
 	component := apiv2alpha1.DataUpdaterPlant
 
-	// Let's list the existing deployments labeled DUP
-	currentDUPDeployments := &appsv1.DeploymentList{}
-	if err := c.List(context.TODO(), currentDUPDeployments, client.InNamespace(cr.Namespace),
-		client.MatchingLabels{"astarte-component": component.DashedString()}); err != nil {
+	// Let's see which shards we have
+	currentShards, err := getDataUpdaterPlantShards(cr, c)
+	if err != nil {
 		return err
 	}
 
-	if len(currentDUPDeployments.Items) > int(replicas) || !pointy.BoolValue(dup.AstarteGenericClusteredResource.Deploy, true) {
-		// In this case, we should schedule for immediate deletion all of the deployments and recreate them.
-		if err := c.DeleteAllOf(context.Background(), &appsv1.Deployment{}, client.InNamespace(cr.Namespace),
-			client.MatchingLabels{"astarte-component": component.DashedString()}); err != nil {
-			return err
-		}
-		// If we shouldn't deploy, just return now then.
-		if !pointy.BoolValue(dup.AstarteGenericClusteredResource.Deploy, true) {
-			return nil
+	// Shall we deploy?
+	if !pointy.BoolValue(dup.AstarteGenericClusteredResource.Deploy, true) {
+		for _, shard := range currentShards {
+			log.Info("Deleting previously existing Data Updater Plant shard, which is no longer needed", "Deployment.Name", shard.deployment.Name)
+			if err := c.Delete(context.TODO(), shard.deployment); err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
 		}
+		return nil
 	}
 
-	if replicas == 1 {
-		// We should treat it as a standard case
-		return EnsureAstarteGenericBackend(cr, dup.AstarteGenericClusteredResource, component, c, scheme)
-	}
-
-	// If we got here, we need to apply some custom logic to our deployments
-	// Reconcile the service first
+	// Reconcile the service and the RBAC shared by all shards first
+	baseName := cr.Name + "-" + component.DashedString()
 	serviceName := cr.Name + "-" + component.ServiceName()
 	labels := map[string]string{
-		"app":                   cr.Name + "-" + component.DashedString(),
+		"app":                   baseName,
 		"component":             "astarte",
 		"astarte-component":     component.DashedString(),
 		"astarte-instance-name": cr.Name,
@@ -76,10 +104,22 @@ func EnsureAstarteDataUpdaterPlant(cr *apiv2alpha1.Astarte, dup apiv2alpha1.Asta
 	if err := createOrUpdateService(cr, c, serviceName, scheme, matchLabels, labels); err != nil {
 		return err
 	}
+	if err := reconcileStandardRBACForClusteringForApp(baseName, GetAstarteClusteredServicePolicyRules(), cr, c, scheme); err != nil {
+		return err
+	}
+	// Shards are not autoscaled through an HPA: drop the one a single replica deployment might have had
+	if err := deleteHorizontalPodAutoscalerIfExists(baseName, cr, c); err != nil {
+		return err
+	}
+
+	done, err := reshardDataUpdaterPlant(currentShards, getDataUpdaterPlantTargetQueues(shards, cr), cr, dup, c, scheme)
+	if err != nil || !done {
+		return err
+	}
 
-	// Now proceed in creating a deployment for each DUP replica with its own set of queues
-	for i := 0; i < int(replicas); i++ {
-		if err := createIndexedDataUpdaterPlantDeployment(i, int(replicas), cr, dup, c, scheme); err != nil {
+	// Every shard is where it should be: make sure all of them are up to date
+	for i := 0; i < shards; i++ {
+		if err := createIndexedDataUpdaterPlantDeployment(i, shards, cr, dup, c, scheme); err != nil {
 			return err
 		}
 	}
@@ -87,10 +127,252 @@ func EnsureAstarteDataUpdaterPlant(cr *apiv2alpha1.Astarte, dup apiv2alpha1.Asta
 	return nil
 }
 
+// reshardDataUpdaterPlant moves the shards towards their target queue ranges, performing at most a single step each time
+// it is called. It returns true when all shards are consuming from their target queue ranges.
+func reshardDataUpdaterPlant(currentShards []dataUpdaterPlantShard, targetQueues []queueRange, cr *apiv2alpha1.Astarte,
+	dup apiv2alpha1.AstarteDataUpdaterPlantSpec, c client.Client, scheme *runtime.Scheme) (bool, error) {
+	if !isDataUpdaterPlantReshardingNeeded(currentShards, targetQueues) {
+		return true, nil
+	}
+
+	// Nobody is consuming yet, so there's nothing to be careful about: the caller will create all shards at once
+	if len(currentShards) == 0 {
+		return true, nil
+	}
+
+	// Move a single shard at a time, and only when the previous move is complete
+	for _, shard := range currentShards {
+		if !shard.rolledOut {
+			log.Info("Waiting for Data Updater Plant shard to roll out before resharding", "Deployment.Name", shard.deployment.Name)
+			return false, nil
+		}
+	}
+
+	// Every step is computed over all data queues from scratch, as the target might have changed halfway through
+	owners := getDataUpdaterPlantQueueOwners(currentShards)
+	shardsByIndex := map[int]dataUpdaterPlantShard{}
+	for _, shard := range currentShards {
+		shardsByIndex[shard.index] = shard
+	}
+
+	// Queues without a consumer are handed over first: grow the first shard whose target queues are either
+	// its own already or not consumed by anybody.
+	for i, target := range targetQueues {
+		shard, exists := shardsByIndex[i]
+		if exists && !shard.drained && shard.queues == target {
+			continue
+		}
+		if exists && !shard.drained && shard.queues.intersect(target) != shard.queues {
+			// The shard would stop consuming queues nobody else took yet: it has to shrink first
+			continue
+		}
+		if !isQueueRangeFreeFor(target, i, owners) {
+			continue
+		}
+		log.Info("Growing Data Updater Plant shard", "Shard.Index", i, "Queues.Start", target.start, "Queues.End", target.end)
+		return false, reconcileDataUpdaterPlantShardDeployment(i, len(targetQueues), target, 1, cr, dup, c, scheme)
+	}
+
+	// Otherwise, shrink a shard to the queues it will keep, draining it if it keeps nothing.
+	for _, shard := range currentShards {
+		if shard.drained {
+			continue
+		}
+
+		kept := queueRange{start: 0, end: -1}
+		if shard.index < len(targetQueues) {
+			kept = shard.queues.intersect(targetQueues[shard.index])
+		}
+		if kept == shard.queues {
+			continue
+		}
+
+		if kept.isEmpty() {
+			log.Info("Draining Data Updater Plant shard", "Deployment.Name", shard.deployment.Name)
+			return false, reconcileDataUpdaterPlantShardDeployment(shard.index, len(targetQueues), shard.queues, 0, cr, dup, c, scheme)
+		}
+		log.Info("Shrinking Data Updater Plant shard", "Deployment.Name", shard.deployment.Name,
+			"Queues.Start", kept.start, "Queues.End", kept.end)
+		return false, reconcileDataUpdaterPlantShardDeployment(shard.index, len(targetQueues), kept, 1, cr, dup, c, scheme)
+	}
+
+	// Last but not least, get rid of the shards we don't need anymore. They have been drained already.
+	for _, shard := range currentShards {
+		if shard.index < len(targetQueues) {
+			continue
+		}
+		log.Info("Deleting drained Data Updater Plant shard", "Deployment.Name", shard.deployment.Name)
+		if err := c.Delete(context.TODO(), shard.deployment); err != nil && !kerrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// getDataUpdaterPlantQueueOwners returns the index of the shard consuming from each data queue. Queues nobody
+// is consuming from are not in the map.
+func getDataUpdaterPlantQueueOwners(currentShards []dataUpdaterPlantShard) map[int]int {
+	owners := map[int]int{}
+	for _, shard := range currentShards {
+		if shard.drained {
+			continue
+		}
+		for q := shard.queues.start; q <= shard.queues.end; q++ {
+			owners[q] = shard.index
+		}
+	}
+	return owners
+}
+
+// isQueueRangeFreeFor tells whether the given shard can consume from all queues in r without sharing any of them
+func isQueueRangeFreeFor(r queueRange, shardIndex int, owners map[int]int) bool {
+	for q := r.start; q <= r.end; q++ {
+		if owner, ok := owners[q]; ok && owner != shardIndex {
+			return false
+		}
+	}
+	return true
+}
+
+func isDataUpdaterPlantReshardingNeeded(currentShards []dataUpdaterPlantShard, targetQueues []queueRange) bool {
+	if len(currentShards) != len(targetQueues) {
+		return true
+	}
+	for _, shard := range currentShards {
+		if shard.index >= len(targetQueues) || shard.drained || shard.queues != targetQueues[shard.index] {
+			return true
+		}
+	}
+	return false
+}
+
+// getDataUpdaterPlantShards returns the Data Updater Plant shards of the given Astarte, sorted by index
+func getDataUpdaterPlantShards(cr *apiv2alpha1.Astarte, c client.Client) ([]dataUpdaterPlantShard, error) {
+	component := apiv2alpha1.DataUpdaterPlant
+	baseName := cr.Name + "-" + component.DashedString()
+
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(context.TODO(), deployments, client.InNamespace(cr.Namespace),
+		client.MatchingLabels{"astarte-component": component.DashedString()}); err != nil {
+		return nil, err
+	}
+
+	shards := []dataUpdaterPlantShard{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		// Other Astarte instances might live in the same namespace
+		if !metav1.IsControlledBy(deployment, cr) {
+			continue
+		}
+
+		index := 0
+		if deployment.Name != baseName {
+			suffix, found := strings.CutPrefix(deployment.Name, baseName+"-")
+			parsed, err := strconv.Atoi(suffix)
+			if !found || err != nil {
+				continue
+			}
+			index = parsed
+		}
+
+		queues, ok := getDataUpdaterPlantQueueRangeFromPodSpec(deployment.Spec.Template.Spec)
+		if !ok {
+			return nil, fmt.Errorf("could not determine the data queues consumed by Data Updater Plant Deployment %s", deployment.Name)
+		}
+
+		replicas := pointy.Int32Value(deployment.Spec.Replicas, 1)
+		shards = append(shards, dataUpdaterPlantShard{
+			index:      index,
+			deployment: deployment,
+			queues:     queues,
+			drained:    replicas == 0,
+			rolledOut: deployment.Status.ObservedGeneration >= deployment.Generation &&
+				deployment.Status.Replicas == replicas && deployment.Status.UpdatedReplicas == replicas &&
+				deployment.Status.ReadyReplicas == replicas,
+		})
+	}
+
+	sort.Slice(shards, func(i, j int) bool { return shards[i].index < shards[j].index })
+	return shards, nil
+}
+
+// GetDataUpdaterPlantShardsStatus reports the data queues each Data Updater Plant shard is consuming from
+func GetDataUpdaterPlantShardsStatus(cr *apiv2alpha1.Astarte, c client.Client) ([]apiv2alpha1.AstarteDataUpdaterPlantShardStatus, error) {
+	shards, err := getDataUpdaterPlantShards(cr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []apiv2alpha1.AstarteDataUpdaterPlantShardStatus{}
+	for _, shard := range shards {
+		shardStatus := apiv2alpha1.AstarteDataUpdaterPlantShardStatus{
+			Name:  shard.deployment.Name,
+			Ready: shard.rolledOut && !shard.drained,
+		}
+		if !shard.drained {
+			shardStatus.QueueRangeStart = pointy.Int32(int32(shard.queues.start))
+			shardStatus.QueueRangeEnd = pointy.Int32(int32(shard.queues.end))
+		}
+		ret = append(ret, shardStatus)
+	}
+	return ret, nil
+}
+
+// getDataUpdaterPlantTargetQueues returns the data queues each shard should consume from once resharding is over
+func getDataUpdaterPlantTargetQueues(replicas int, cr *apiv2alpha1.Astarte) []queueRange {
+	targetQueues := make([]queueRange, replicas)
+	for i := range targetQueues {
+		targetQueues[i] = getDataUpdaterPlantTargetQueueRange(i, replicas, cr)
+	}
+	return targetQueues
+}
+
+// getDataUpdaterPlantTargetQueueRange returns the data queues the given shard should consume from once resharding is over
+func getDataUpdaterPlantTargetQueueRange(replicaIndex, replicas int, cr *apiv2alpha1.Astarte) queueRange {
+	// getAstarteDataUpdaterPlantQueuesEnvVars is the one source of truth for how queues are split
+	queues, _ := getDataUpdaterPlantQueueRangeFromEnvVars(getAstarteDataUpdaterPlantQueuesEnvVars(replicaIndex, replicas, cr))
+	return queues
+}
+
+func getDataUpdaterPlantQueueRangeFromPodSpec(podSpec v1.PodSpec) (queueRange, bool) {
+	component := apiv2alpha1.DataUpdaterPlant
+	for _, container := range podSpec.Containers {
+		if container.Name == component.DashedString() {
+			return getDataUpdaterPlantQueueRangeFromEnvVars(container.Env)
+		}
+	}
+	return queueRange{}, false
+}
+
+func getDataUpdaterPlantQueueRangeFromEnvVars(envVars []v1.EnvVar) (queueRange, bool) {
+	start, end := -1, -1
+	for _, envVar := range envVars {
+		var err error
+		switch {
+		case envVar.Name == dataUpdaterPlantQueueRangeStartEnvVar && start < 0:
+			start, err = strconv.Atoi(envVar.Value)
+		case envVar.Name == dataUpdaterPlantQueueRangeEndEnvVar && end < 0:
+			end, err = strconv.Atoi(envVar.Value)
+		}
+		if err != nil {
+			return queueRange{}, false
+		}
+	}
+	return queueRange{start: start, end: end}, start >= 0 && end >= 0
+}
+
 func createIndexedDataUpdaterPlantDeployment(replicaIndex, replicas int, cr *apiv2alpha1.Astarte, dup apiv2alpha1.AstarteDataUpdaterPlantSpec, c client.Client, scheme *runtime.Scheme) error {
+	return reconcileDataUpdaterPlantShardDeployment(replicaIndex, replicas, getDataUpdaterPlantTargetQueueRange(replicaIndex, replicas, cr),
+		1, cr, dup, c, scheme)
+}
+
+func reconcileDataUpdaterPlantShardDeployment(replicaIndex, replicas int, queues queueRange, deploymentReplicas int32,
+	cr *apiv2alpha1.Astarte, dup apiv2alpha1.AstarteDataUpdaterPlantSpec, c client.Client, scheme *runtime.Scheme) error {
 	component := apiv2alpha1.DataUpdaterPlant
 
-	deploymentName := cr.Name + "-" + component.DashedString()
+	baseName := cr.Name + "-" + component.DashedString()
+	deploymentName := baseName
 	if replicaIndex > 0 {
 		deploymentName = deploymentName + "-" + strconv.Itoa(replicaIndex)
 	}
@@ -109,6 +391,11 @@ func createIndexedDataUpdaterPlantDeployment(replicaIndex, replicas int, cr *api
 		return err
 	}
 
+	podSpec := getAstarteGenericBackendPodSpec(deploymentName, replicaIndex, replicas, cr, dup.AstarteGenericClusteredResource, component)
+	// All shards share the same Service Account
+	podSpec.ServiceAccountName = baseName
+	setDataUpdaterPlantQueueRange(&podSpec, queues)
+
 	deploymentSpec := appsv1.DeploymentSpec{
 		Selector: &metav1.LabelSelector{
 			MatchLabels: matchLabels,
@@ -118,7 +405,7 @@ func createIndexedDataUpdaterPlantDeployment(replicaIndex, replicas int, cr *api
 			ObjectMeta: metav1.ObjectMeta{
 				Labels: computePodLabels(dup.AstarteGenericClusteredResource, labels),
 			},
-			Spec: getAstarteGenericBackendPodSpec(deploymentName, replicaIndex, replicas, cr, dup.AstarteGenericClusteredResource, component),
+			Spec: podSpec,
 		},
 	}
 
@@ -132,8 +419,8 @@ func createIndexedDataUpdaterPlantDeployment(replicaIndex, replicas int, cr *api
 		// Assign the Spec.
 		deployment.ObjectMeta.Labels = labels
 		deployment.Spec = deploymentSpec
-		// Always force to 1
-		deployment.Spec.Replicas = pointy.Int32(1)
+		// Each shard has a single replica, unless it is drained
+		deployment.Spec.Replicas = pointy.Int32(deploymentReplicas)
 
 		return nil
 	})
@@ -144,3 +431,24 @@ func createIndexedDataUpdaterPlantDeployment(replicaIndex, replicas int, cr *api
 	misc.LogCreateOrUpdateOperationResult(log, result, cr, deployment)
 	return nil
 }
+
+func setDataUpdaterPlantQueueRange(podSpec *v1.PodSpec, queues queueRange) {
+	component := apiv2alpha1.DataUpdaterPlant
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != component.DashedString() {
+			continue
+		}
+		startSet, endSet := false, false
+		for j := range podSpec.Containers[i].Env {
+			envVar := &podSpec.Containers[i].Env[j]
+			switch {
+			case envVar.Name == dataUpdaterPlantQueueRangeStartEnvVar && !startSet:
+				envVar.Value = strconv.Itoa(queues.start)
+				startSet = true
+			case envVar.Name == dataUpdaterPlantQueueRangeEndEnvVar && !endSet:
+				envVar.Value = strconv.Itoa(queues.end)
+				endSet = true
+			}
+		}
+	}
+}
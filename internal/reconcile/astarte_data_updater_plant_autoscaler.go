@@ -0,0 +1,221 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.openly.dev/pointy"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/rabbitmq"
+)
+
+const (
+	defaultDataQueuesPrefix                            = "astarte_data_"
+	defaultDUPTargetBacklogPerShard              int64 = 1000
+	defaultDUPScaleDownThresholdPercentage       int32 = 50
+	defaultDUPScaleUpCooldownSeconds             int32 = 60
+	defaultDUPScaleDownCooldownSeconds           int32 = 300
+	defaultDUPQueueAutoscalerPollIntervalSeconds int32 = 30
+)
+
+// dataQueuesObservation is what the queue autoscaler knows about the data queues
+type dataQueuesObservation struct {
+	backlog                int64
+	consumers              int32
+	queuesWithoutConsumers int32
+}
+
+// EnsureDataUpdaterPlantQueueAutoscaling observes the backlog of the data queues and decides how many
+// Data Updater Plant shards should be running. The decision is persisted in the Astarte status right away,
+// so that it survives across reconciliations.
+func EnsureDataUpdaterPlantQueueAutoscaling(cr *apiv2alpha1.Astarte, c client.Client) error {
+	if !isDataUpdaterPlantQueueAutoscalerEnabled(cr) {
+		if cr.Status.DataUpdaterPlant == nil {
+			return nil
+		}
+		return patchDataUpdaterPlantStatus(cr, nil, c)
+	}
+
+	newStatus := apiv2alpha1.AstarteDataUpdaterPlantStatus{}
+	if cr.Status.DataUpdaterPlant != nil {
+		newStatus = *cr.Status.DataUpdaterPlant.DeepCopy()
+	}
+	// This takes care of bounds changing under our feet, too
+	newStatus.Shards = getDataUpdaterPlantShardCount(cr, cr.Spec.Components.DataUpdaterPlant)
+
+	now := time.Now()
+	observation, err := observeDataQueues(cr, c)
+	if err != nil {
+		// Don't block the reconciliation: stick to the last decision, and try again at the next poll.
+		log.Error(err, "Could not observe the Data Updater Plant data queues, not scaling")
+	} else {
+		newStatus.Backlog = observation.backlog
+		newStatus.Consumers = observation.consumers
+		newStatus.QueuesWithoutConsumers = observation.queuesWithoutConsumers
+		newStatus.LastObservationTime = &metav1.Time{Time: now}
+
+		if shards := computeDataUpdaterPlantShards(cr.Spec.Components.DataUpdaterPlant.QueueAutoscaler, newStatus, now); shards != newStatus.Shards {
+			log.Info("Scaling Data Updater Plant", "Shards.Old", newStatus.Shards, "Shards.New", shards, "Backlog", newStatus.Backlog)
+			newStatus.Shards = shards
+			newStatus.LastScaleTime = &metav1.Time{Time: now}
+		}
+	}
+
+	if equality.Semantic.DeepEqual(cr.Status.DataUpdaterPlant, &newStatus) {
+		return nil
+	}
+	return patchDataUpdaterPlantStatus(cr, &newStatus, c)
+}
+
+// getDataUpdaterPlantShardCount returns the number of Data Updater Plant shards which should be running
+func getDataUpdaterPlantShardCount(cr *apiv2alpha1.Astarte, dup apiv2alpha1.AstarteDataUpdaterPlantSpec) int32 {
+	replicas := pointy.Int32Value(dup.Replicas, apiv2alpha1.DefaultReplicas)
+	if !cr.Spec.Features.Autoscaling || !dup.QueueAutoscaler.IsEnabled() {
+		return replicas
+	}
+
+	if cr.Status.DataUpdaterPlant != nil && cr.Status.DataUpdaterPlant.Shards > 0 {
+		replicas = cr.Status.DataUpdaterPlant.Shards
+	}
+	minShards, maxShards := getDataUpdaterPlantShardBounds(dup.QueueAutoscaler)
+	return clampInt32(replicas, minShards, maxShards)
+}
+
+// GetDataUpdaterPlantQueueAutoscalerPollInterval returns how often the data queues should be polled, or 0 if
+// the queue autoscaler is not enabled.
+func GetDataUpdaterPlantQueueAutoscalerPollInterval(cr *apiv2alpha1.Astarte) time.Duration {
+	if !isDataUpdaterPlantQueueAutoscalerEnabled(cr) {
+		return 0
+	}
+	seconds := pointy.Int32Value(cr.Spec.Components.DataUpdaterPlant.QueueAutoscaler.PollIntervalSeconds, defaultDUPQueueAutoscalerPollIntervalSeconds)
+	return time.Duration(seconds) * time.Second
+}
+
+func isDataUpdaterPlantQueueAutoscalerEnabled(cr *apiv2alpha1.Astarte) bool {
+	return cr.Spec.Features.Autoscaling && cr.Spec.Components.DataUpdaterPlant.QueueAutoscaler.IsEnabled()
+}
+
+// computeDataUpdaterPlantShards decides the number of shards given the latest observation. Scaling up jumps
+// straight to what the backlog requires, while scaling down happens one shard at a time, and only when the
+// backlog is well below what the remaining shards can handle.
+func computeDataUpdaterPlantShards(autoscaler *apiv2alpha1.AstarteDataUpdaterPlantQueueAutoscalerSpec,
+	status apiv2alpha1.AstarteDataUpdaterPlantStatus, now time.Time) int32 {
+	current := status.Shards
+
+	// Some data queues are not being consumed (e.g. shards are starting up): the backlog is not meaningful yet
+	if status.QueuesWithoutConsumers > 0 {
+		return current
+	}
+
+	minShards, maxShards := getDataUpdaterPlantShardBounds(autoscaler)
+	targetBacklog := pointy.Int64Value(autoscaler.TargetBacklogPerShard, defaultDUPTargetBacklogPerShard)
+	desired := clampInt32(int32((status.Backlog+targetBacklog-1)/targetBacklog), minShards, maxShards)
+
+	switch {
+	case desired > current:
+		cooldown := pointy.Int32Value(autoscaler.ScaleUpCooldownSeconds, defaultDUPScaleUpCooldownSeconds)
+		if hasCooledDown(status.LastScaleTime, cooldown, now) {
+			return desired
+		}
+	case desired < current:
+		threshold := pointy.Int32Value(autoscaler.ScaleDownThresholdPercentage, defaultDUPScaleDownThresholdPercentage)
+		cooldown := pointy.Int32Value(autoscaler.ScaleDownCooldownSeconds, defaultDUPScaleDownCooldownSeconds)
+		if status.Backlog*100 <= targetBacklog*int64(current-1)*int64(threshold) && hasCooledDown(status.LastScaleTime, cooldown, now) {
+			return current - 1
+		}
+	}
+
+	return current
+}
+
+func observeDataQueues(cr *apiv2alpha1.Astarte, c client.Client) (dataQueuesObservation, error) {
+	managementClient, err := rabbitmq.NewManagementClientFor(cr, c)
+	if err != nil {
+		return dataQueuesObservation{}, err
+	}
+
+	queues, err := managementClient.ListQueues(context.TODO(), misc.GetRabbitMQVirtualHost(cr))
+	if err != nil {
+		return dataQueuesObservation{}, err
+	}
+
+	dataQueueNames := getDataQueueNames(cr)
+	observation := dataQueuesObservation{}
+	found := 0
+	for _, q := range queues {
+		if !dataQueueNames[q.Name] {
+			continue
+		}
+		found++
+		observation.backlog += q.Messages
+		observation.consumers += q.Consumers
+		if q.Consumers == 0 {
+			observation.queuesWithoutConsumers++
+		}
+	}
+	// Queues which were not declared yet have no consumers, by definition
+	observation.queuesWithoutConsumers += int32(len(dataQueueNames) - found)
+
+	return observation, nil
+}
+
+func getDataQueueNames(cr *apiv2alpha1.Astarte) map[string]bool {
+	prefix := cr.Spec.RabbitMQ.DataQueuesPrefix
+	if prefix == "" {
+		prefix = defaultDataQueuesPrefix
+	}
+
+	names := map[string]bool{}
+	for i := 0; i < getDataQueueCount(cr); i++ {
+		names[prefix+strconv.Itoa(i)] = true
+	}
+	return names
+}
+
+func patchDataUpdaterPlantStatus(cr *apiv2alpha1.Astarte, status *apiv2alpha1.AstarteDataUpdaterPlantStatus, c client.Client) error {
+	patch := client.MergeFrom(cr.DeepCopy())
+	cr.Status.DataUpdaterPlant = status
+	return c.Status().Patch(context.TODO(), cr, patch)
+}
+
+func getDataUpdaterPlantShardBounds(autoscaler *apiv2alpha1.AstarteDataUpdaterPlantQueueAutoscalerSpec) (int32, int32) {
+	minShards := pointy.Int32Value(autoscaler.MinShards, 1)
+	maxShards := autoscaler.MaxShards
+	// The webhook prevents this, but better safe than sorry
+	if maxShards < minShards {
+		maxShards = minShards
+	}
+	return minShards, maxShards
+}
+
+func hasCooledDown(lastScaleTime *metav1.Time, cooldownSeconds int32, now time.Time) bool {
+	return lastScaleTime == nil || now.Sub(lastScaleTime.Time) >= time.Duration(cooldownSeconds)*time.Second
+}
+
+func clampInt32(value, lower, upper int32) int32 {
+	return max(lower, min(value, upper))
+}
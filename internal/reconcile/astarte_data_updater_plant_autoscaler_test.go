@@ -0,0 +1,179 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/rabbitmq"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Data Updater Plant queue autoscaler testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "dup-autoscaler-test"
+		DataQueueCount         = 8
+	)
+
+	var cr *apiv2alpha1.Astarte
+	var server *httptest.Server
+	// What the RabbitMQ management API stand-in reports for each data queue
+	var messagesPerQueue int64
+	var consumersPerQueue int32
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			queues := []rabbitmq.Queue{{Name: "not_a_data_queue", Messages: 1000000}}
+			for i := 0; i < DataQueueCount; i++ {
+				queues = append(queues, rabbitmq.Queue{Name: "astarte_data_" + strconv.Itoa(i), Messages: messagesPerQueue, Consumers: consumersPerQueue})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(queues)
+		}))
+	})
+
+	AfterAll(func() {
+		server.Close()
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.RabbitMQ.ManagementURL = server.URL
+		cr.Spec.Features.Autoscaling = true
+		cr.Spec.Components.DataUpdaterPlant.DataQueueCount = pointy.Int(DataQueueCount)
+		cr.Spec.Components.DataUpdaterPlant.QueueAutoscaler = &apiv2alpha1.AstarteDataUpdaterPlantQueueAutoscalerSpec{
+			Enable:                true,
+			MaxShards:             4,
+			TargetBacklogPerShard: pointy.Int64(100),
+		}
+		integrationutils.DeployAstarte(k8sClient, cr)
+
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-connection-secret", Namespace: CustomAstarteNamespace},
+			Data:       map[string][]byte{"username": []byte("astarte"), "password": []byte("astarte")},
+		}
+		Expect(k8sClient.Create(context.Background(), secret)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test EnsureDataUpdaterPlantQueueAutoscaling", func() {
+		It("should scale up according to the backlog and report it in status", func() {
+			messagesPerQueue = 40
+			consumersPerQueue = 1
+
+			Expect(EnsureDataUpdaterPlantQueueAutoscaling(cr, k8sClient)).To(Succeed())
+
+			Expect(cr.Status.DataUpdaterPlant).ToNot(BeNil())
+			Expect(cr.Status.DataUpdaterPlant.Backlog).To(Equal(int64(320)))
+			Expect(cr.Status.DataUpdaterPlant.Consumers).To(Equal(int32(DataQueueCount)))
+			Expect(cr.Status.DataUpdaterPlant.QueuesWithoutConsumers).To(BeZero())
+			Expect(cr.Status.DataUpdaterPlant.Shards).To(Equal(int32(4)))
+			Expect(cr.Status.DataUpdaterPlant.LastScaleTime).ToNot(BeNil())
+			Expect(getDataUpdaterPlantShardCount(cr, cr.Spec.Components.DataUpdaterPlant)).To(Equal(int32(4)))
+		})
+
+		It("should not scale while data queues are not being consumed", func() {
+			messagesPerQueue = 1000
+			consumersPerQueue = 0
+
+			Expect(EnsureDataUpdaterPlantQueueAutoscaling(cr, k8sClient)).To(Succeed())
+
+			Expect(cr.Status.DataUpdaterPlant.Shards).To(Equal(int32(1)))
+			Expect(cr.Status.DataUpdaterPlant.QueuesWithoutConsumers).To(Equal(int32(DataQueueCount)))
+		})
+
+		It("should keep the last decision when the management API is unreachable", func() {
+			cr.Spec.RabbitMQ.ManagementURL = "http://127.0.0.1:1"
+			cr.Status.DataUpdaterPlant = &apiv2alpha1.AstarteDataUpdaterPlantStatus{Shards: 3}
+
+			Expect(EnsureDataUpdaterPlantQueueAutoscaling(cr, k8sClient)).To(Succeed())
+			Expect(cr.Status.DataUpdaterPlant.Shards).To(Equal(int32(3)))
+		})
+
+		It("should clear the status when the autoscaler is disabled", func() {
+			messagesPerQueue = 0
+			consumersPerQueue = 1
+			Expect(EnsureDataUpdaterPlantQueueAutoscaling(cr, k8sClient)).To(Succeed())
+			Expect(cr.Status.DataUpdaterPlant).ToNot(BeNil())
+
+			cr.Spec.Components.DataUpdaterPlant.QueueAutoscaler.Enable = false
+			Expect(EnsureDataUpdaterPlantQueueAutoscaling(cr, k8sClient)).To(Succeed())
+			Expect(cr.Status.DataUpdaterPlant).To(BeNil())
+		})
+	})
+
+	Describe("Test computeDataUpdaterPlantShards", func() {
+		var autoscaler *apiv2alpha1.AstarteDataUpdaterPlantQueueAutoscalerSpec
+		now := time.Now()
+
+		BeforeEach(func() {
+			autoscaler = &apiv2alpha1.AstarteDataUpdaterPlantQueueAutoscalerSpec{
+				Enable:                true,
+				MinShards:             pointy.Int32(2),
+				MaxShards:             6,
+				TargetBacklogPerShard: pointy.Int64(100),
+			}
+		})
+
+		It("should stay within bounds", func() {
+			Expect(computeDataUpdaterPlantShards(autoscaler, apiv2alpha1.AstarteDataUpdaterPlantStatus{Shards: 2, Backlog: 10000}, now)).To(Equal(int32(6)))
+			Expect(computeDataUpdaterPlantShards(autoscaler, apiv2alpha1.AstarteDataUpdaterPlantStatus{Shards: 2, Backlog: 0}, now)).To(Equal(int32(2)))
+		})
+
+		It("should honor the scale up cooldown", func() {
+			status := apiv2alpha1.AstarteDataUpdaterPlantStatus{Shards: 2, Backlog: 500, LastScaleTime: &metav1.Time{Time: now.Add(-30 * time.Second)}}
+			Expect(computeDataUpdaterPlantShards(autoscaler, status, now)).To(Equal(int32(2)))
+
+			status.LastScaleTime = &metav1.Time{Time: now.Add(-2 * time.Minute)}
+			Expect(computeDataUpdaterPlantShards(autoscaler, status, now)).To(Equal(int32(5)))
+		})
+
+		It("should scale down one shard at a time, with hysteresis and cooldown", func() {
+			// 4 shards, 250 messages: 3 shards would be enough, but the backlog is above 50% of what they handle
+			status := apiv2alpha1.AstarteDataUpdaterPlantStatus{Shards: 4, Backlog: 250}
+			Expect(computeDataUpdaterPlantShards(autoscaler, status, now)).To(Equal(int32(4)))
+
+			status.Backlog = 10
+			Expect(computeDataUpdaterPlantShards(autoscaler, status, now)).To(Equal(int32(3)))
+
+			status.LastScaleTime = &metav1.Time{Time: now.Add(-time.Minute)}
+			Expect(computeDataUpdaterPlantShards(autoscaler, status, now)).To(Equal(int32(4)))
+		})
+	})
+})
@@ -92,7 +92,7 @@ var _ = Describe("Misc utils testing", Ordered, Serial, func() {
 
 		It("should create the right number of DUP deployments", func() {
 			// To test this we create 2 DUP deployments, then update the Astarte CR to have only 1 DUP replica and check
-			// that only 1 is left
+			// that only 1 is left once resharding is over
 			cr1 := cr.DeepCopy()
 			cr1.ResourceVersion = ""
 			cr1.Name = "two-replicas-dup"
@@ -108,7 +108,7 @@ var _ = Describe("Misc utils testing", Ordered, Serial, func() {
 
 			Expect(EnsureAstarteDataUpdaterPlant(cr1, cr1.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
 			Expect(k8sClient.List(context.Background(), dups, client.InNamespace(cr1.Namespace),
-				client.MatchingLabels{"astarte-component": "data-updater-plant"})).To(Succeed())
+				client.MatchingLabels{"astarte-component": "data-updater-plant", "astarte-instance-name": cr1.Name})).To(Succeed())
 			Expect(dups.Items).To(HaveLen(2))
 
 			// Update the CR to have only 1 DUP replica
@@ -118,12 +118,20 @@ var _ = Describe("Misc utils testing", Ordered, Serial, func() {
 				return k8sClient.Get(context.Background(), types.NamespacedName{Name: cr1.Name, Namespace: cr1.Namespace}, cr1)
 			}, Timeout, Interval).Should(Succeed())
 
-			// We should have only 1 deployment now
+			// Shards are moved one step at a time, and only once the previous step rolled out
 			Expect(EnsureAstarteDataUpdaterPlant(cr1, cr1.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
-
 			Expect(k8sClient.List(context.Background(), dups, client.InNamespace(cr1.Namespace),
-				client.MatchingLabels{"astarte-component": "data-updater-plant"})).To(Succeed())
-			Expect(dups.Items).To(HaveLen(1))
+				client.MatchingLabels{"astarte-component": "data-updater-plant", "astarte-instance-name": cr1.Name})).To(Succeed())
+			Expect(dups.Items).To(HaveLen(2))
+
+			// We should have only 1 deployment in the end
+			Eventually(func() int {
+				markDataUpdaterPlantShardsRolledOut(cr1)
+				Expect(EnsureAstarteDataUpdaterPlant(cr1, cr1.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+				Expect(k8sClient.List(context.Background(), dups, client.InNamespace(cr1.Namespace),
+					client.MatchingLabels{"astarte-component": "data-updater-plant", "astarte-instance-name": cr1.Name})).To(Succeed())
+				return len(dups.Items)
+			}, Timeout, Interval).Should(Equal(1))
 
 			// Cleanup
 			Expect(k8sClient.Delete(context.Background(), cr1)).To(Succeed())
@@ -132,6 +140,112 @@ var _ = Describe("Misc utils testing", Ordered, Serial, func() {
 				return apierrors.IsNotFound(err)
 			}, Timeout, Interval).Should(BeTrue())
 		})
+
+		It("should never have two shards consuming from the same data queue while resharding", func() {
+			cr.Spec.Components.DataUpdaterPlant.Deploy = pointy.Bool(true)
+			cr.Spec.Components.DataUpdaterPlant.DataQueueCount = pointy.Int(12)
+
+			for _, shards := range []int32{3, 2, 4, 1} {
+				cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(shards)
+
+				for step := 0; ; step++ {
+					Expect(step).To(BeNumerically("<", 20), "resharding did not converge")
+					markDataUpdaterPlantShardsRolledOut(cr)
+					Expect(EnsureAstarteDataUpdaterPlant(cr, cr.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+
+					currentShards, err := getDataUpdaterPlantShards(cr, k8sClient)
+					Expect(err).ToNot(HaveOccurred())
+					consumers := getDataUpdaterPlantQueueConsumers(currentShards, 12)
+					for q, n := range consumers {
+						Expect(n).To(BeNumerically("<=", 1), "data queue %d has %d consumers", q, n)
+					}
+
+					if len(currentShards) == int(shards) && !isDataUpdaterPlantReshardingNeeded(currentShards, getDataUpdaterPlantTargetQueues(int(shards), cr)) {
+						// Once done, every data queue has exactly one consumer
+						Expect(consumers).To(HaveEach(1))
+						break
+					}
+				}
+			}
+		})
+
+		It("should hand released data queues over to their new shard when shrinking and then growing", func() {
+			cr.Spec.Components.DataUpdaterPlant.Deploy = pointy.Bool(true)
+			cr.Spec.Components.DataUpdaterPlant.DataQueueCount = pointy.Int(12)
+			cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(4)
+			Expect(EnsureAstarteDataUpdaterPlant(cr, cr.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+
+			step := func() []int {
+				markDataUpdaterPlantShardsRolledOut(cr)
+				Expect(EnsureAstarteDataUpdaterPlant(cr, cr.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+				currentShards, err := getDataUpdaterPlantShards(cr, k8sClient)
+				Expect(err).ToNot(HaveOccurred())
+				consumers := getDataUpdaterPlantQueueConsumers(currentShards, 12)
+				for q, n := range consumers {
+					Expect(n).To(BeNumerically("<=", 1), "data queue %d has %d consumers", q, n)
+				}
+				return consumers
+			}
+
+			// Going down to 2 shards drains the second one first...
+			cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(2)
+			consumers := step()
+			Expect(consumers[3:6]).To(HaveEach(0))
+			// ...whose queues are taken over by the first one straight away, before any other shard is drained
+			consumers = step()
+			Expect(consumers[:9]).To(HaveEach(1))
+			consumers = step()
+			Expect(consumers[6:9]).To(HaveEach(0))
+
+			// Growing again halfway through leaves no data queue behind
+			cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(4)
+			Eventually(func() []int {
+				consumers := step()
+				currentShards, err := getDataUpdaterPlantShards(cr, k8sClient)
+				Expect(err).ToNot(HaveOccurred())
+				if isDataUpdaterPlantReshardingNeeded(currentShards, getDataUpdaterPlantTargetQueues(4, cr)) {
+					return nil
+				}
+				return consumers
+			}, Timeout, Interval).Should(HaveEach(1))
+		})
+	})
+
+	Describe("Test GetDataUpdaterPlantShardsStatus", func() {
+		It("should report the data queues consumed by each shard", func() {
+			cr.Spec.Components.DataUpdaterPlant.Deploy = pointy.Bool(true)
+			cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(2)
+			cr.Spec.Components.DataUpdaterPlant.DataQueueCount = pointy.Int(10)
+
+			Expect(EnsureAstarteDataUpdaterPlant(cr, cr.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+
+			shards, err := GetDataUpdaterPlantShardsStatus(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shards).To(HaveLen(2))
+			Expect(shards[0].Name).To(Equal(CustomAstarteName + "-data-updater-plant"))
+			Expect(*shards[0].QueueRangeStart).To(Equal(int32(0)))
+			Expect(*shards[0].QueueRangeEnd).To(Equal(int32(4)))
+			Expect(shards[1].Name).To(Equal(CustomAstarteName + "-data-updater-plant-1"))
+			Expect(*shards[1].QueueRangeStart).To(Equal(int32(5)))
+			Expect(*shards[1].QueueRangeEnd).To(Equal(int32(9)))
+			Expect(shards[0].Ready).To(BeFalse())
+
+			markDataUpdaterPlantShardsRolledOut(cr)
+			shards, err = GetDataUpdaterPlantShardsStatus(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shards[0].Ready).To(BeTrue())
+			Expect(shards[1].Ready).To(BeTrue())
+
+			// Going down to a single shard drains the second one first
+			cr.Spec.Components.DataUpdaterPlant.Replicas = pointy.Int32(1)
+			Expect(EnsureAstarteDataUpdaterPlant(cr, cr.Spec.Components.DataUpdaterPlant, k8sClient, scheme.Scheme)).To(Succeed())
+			shards, err = GetDataUpdaterPlantShardsStatus(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shards).To(HaveLen(2))
+			Expect(shards[1].QueueRangeStart).To(BeNil())
+			Expect(shards[1].QueueRangeEnd).To(BeNil())
+			Expect(shards[1].Ready).To(BeFalse())
+		})
 	})
 
 	Describe("Test createIndexedDataUpdaterPlantDeployment", func() {
@@ -174,3 +288,34 @@ var _ = Describe("Misc utils testing", Ordered, Serial, func() {
 		})
 	})
 })
+
+// markDataUpdaterPlantShardsRolledOut does what the Deployment controller would do, as envtest does not run it
+func markDataUpdaterPlantShardsRolledOut(cr *apiv2alpha1.Astarte) {
+	dups := &appsv1.DeploymentList{}
+	Expect(k8sClient.List(context.Background(), dups, client.InNamespace(cr.Namespace),
+		client.MatchingLabels{"astarte-component": "data-updater-plant", "astarte-instance-name": cr.Name})).To(Succeed())
+	for i := range dups.Items {
+		d := &dups.Items[i]
+		replicas := pointy.Int32Value(d.Spec.Replicas, 1)
+		d.Status.ObservedGeneration = d.Generation
+		d.Status.Replicas = replicas
+		d.Status.UpdatedReplicas = replicas
+		d.Status.ReadyReplicas = replicas
+		d.Status.AvailableReplicas = replicas
+		Expect(k8sClient.Status().Update(context.Background(), d)).To(Succeed())
+	}
+}
+
+// getDataUpdaterPlantQueueConsumers returns how many shards are consuming from each of the first queueCount data queues
+func getDataUpdaterPlantQueueConsumers(currentShards []dataUpdaterPlantShard, queueCount int) []int {
+	consumers := make([]int, queueCount)
+	for _, shard := range currentShards {
+		if shard.drained {
+			continue
+		}
+		for q := shard.queues.start; q <= shard.queues.end; q++ {
+			consumers[q]++
+		}
+	}
+	return consumers
+}
@@ -55,8 +55,8 @@ func EnsureAstarteGenericAPIComponent(cr *apiv2alpha1.Astarte, api apiv2alpha1.A
 
 	// Ok. Shall we deploy?
 	if !checkShouldDeploy(reqLogger, deploymentName, cr, api, component, c) {
-		// That would be all for today.
-		return nil
+		// Drop the HPA as well, if we own one. That would be all for today.
+		return deleteHorizontalPodAutoscalerIfExists(deploymentName, cr, c)
 	}
 
 	// First of all, check if we need to regenerate the cookie.
@@ -104,8 +104,9 @@ func EnsureAstarteGenericAPIComponent(cr *apiv2alpha1.Astarte, api apiv2alpha1.A
 
 		// Assign the Spec.
 		deployment.ObjectMeta.Labels = labels
+		currentReplicas := deployment.Spec.Replicas
 		deployment.Spec = deploymentSpec
-		deployment.Spec.Replicas = getReplicaCountForResource(&api.AstarteGenericClusteredResource, cr, c, reqLogger)
+		deployment.Spec.Replicas = getReplicaCountForResource(&api.AstarteGenericClusteredResource, currentReplicas, cr, c, reqLogger)
 
 		return nil
 	})
@@ -114,7 +115,9 @@ func EnsureAstarteGenericAPIComponent(cr *apiv2alpha1.Astarte, api apiv2alpha1.A
 	}
 
 	misc.LogCreateOrUpdateOperationResult(log, result, cr, deployment)
-	return nil
+
+	// Last but not least, the HPA, if any
+	return ensureHorizontalPodAutoscaler(deploymentName, "Deployment", api.AstarteGenericClusteredResource, cr, c, scheme)
 }
 
 func checkShouldDeploy(reqLogger logr.Logger, deploymentName string, cr *apiv2alpha1.Astarte, api apiv2alpha1.AstarteGenericAPIComponentSpec,
@@ -185,13 +188,13 @@ func getAstarteGenericAPIPodSpec(deploymentName string, cr *apiv2alpha1.Astarte,
 		// is a priorityClass specified in the Astarte CR?
 		switch api.PriorityClass {
 		case highPriority:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		case midPriority:
-			ps.PriorityClassName = AstarteMidPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteMidPriorityName)
 		case lowPriority:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		default:
-			ps.PriorityClassName = GetDefaultAstartePriorityClassNameForComponent(component)
+			ps.PriorityClassName = getAstartePriorityClassName(cr, GetDefaultAstartePriorityClassNameForComponent(component))
 		}
 	}
 
@@ -395,13 +398,9 @@ func getAstarteHousekeepingEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
 }
 
 func getAstartePairingEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
-	ret := []v1.EnvVar{}
+	ret := getCFSSLURLEnvVars("PAIRING_CFSSL_URL", cr)
 
 	ret = append(ret,
-		v1.EnvVar{
-			Name:  "PAIRING_CFSSL_URL",
-			Value: getCFSSLURL(cr),
-		},
 		v1.EnvVar{
 			Name:  "PAIRING_BROKER_URL",
 			Value: misc.GetVerneMQBrokerURL(cr),
@@ -65,8 +65,8 @@ func EnsureAstarteGenericBackend(cr *apiv2alpha1.Astarte, backend apiv2alpha1.As
 			}
 		}
 
-		// That would be all for today.
-		return nil
+		// Drop the HPA as well, if we own one. That would be all for today.
+		return deleteHorizontalPodAutoscalerIfExists(deploymentName, cr, c)
 	}
 
 	// Ensure we reconcile with the RBAC Roles, if needed.
@@ -106,8 +106,9 @@ func EnsureAstarteGenericBackend(cr *apiv2alpha1.Astarte, backend apiv2alpha1.As
 
 		// Assign the Spec.
 		deployment.ObjectMeta.Labels = labels
+		currentReplicas := deployment.Spec.Replicas
 		deployment.Spec = deploymentSpec
-		deployment.Spec.Replicas = getReplicaCountForResource(&backend, cr, c, reqLogger)
+		deployment.Spec.Replicas = getReplicaCountForResource(&backend, currentReplicas, cr, c, reqLogger)
 
 		return nil
 	})
@@ -116,7 +117,9 @@ func EnsureAstarteGenericBackend(cr *apiv2alpha1.Astarte, backend apiv2alpha1.As
 	}
 
 	misc.LogCreateOrUpdateOperationResult(log, result, cr, deployment)
-	return nil
+
+	// Last but not least, the HPA, if any
+	return ensureHorizontalPodAutoscaler(deploymentName, "Deployment", backend, cr, c, scheme)
 }
 
 func getAstarteGenericBackendPodSpec(deploymentName string, replicaIndex, replicas int, cr *apiv2alpha1.Astarte, backend apiv2alpha1.AstarteGenericClusteredResource,
@@ -152,13 +155,13 @@ func getAstarteGenericBackendPodSpec(deploymentName string, replicaIndex, replic
 		// is a priorityClass specified in the Astarte CR?
 		switch backend.PriorityClass {
 		case highPriority:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		case midPriority:
-			ps.PriorityClassName = AstarteMidPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteMidPriorityName)
 		case lowPriority:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		default:
-			ps.PriorityClassName = GetDefaultAstartePriorityClassNameForComponent(component)
+			ps.PriorityClassName = getAstartePriorityClassName(cr, GetDefaultAstartePriorityClassNameForComponent(component))
 		}
 	}
 
@@ -260,7 +263,7 @@ func getAstarteDataUpdaterPlantBackendEnvVars(replicaIndex, replicas int, cr *ap
 		ret = append(ret,
 			v1.EnvVar{
 				Name:  "DATA_UPDATER_PLANT_AMQP_CONSUMER_PREFETCH_COUNT",
-				Value: strconv.Itoa(pointy.IntValue(cr.Spec.Components.DataUpdaterPlant.PrefetchCount, 300)),
+				Value: strconv.Itoa(pointy.IntValue(cr.Spec.Components.DataUpdaterPlant.PrefetchCount, apiv2alpha1.DefaultDataUpdaterPlantPrefetchCount)),
 			})
 	}
 
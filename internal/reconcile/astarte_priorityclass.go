@@ -20,11 +20,18 @@ package reconcile
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
+	"go.openly.dev/pointy"
 	v1 "k8s.io/api/core/v1"
 	scheduling "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -49,72 +56,208 @@ var defaultComponentPriorityClass = map[apiv2alpha1.AstarteComponent]string{
 	apiv2alpha1.Dashboard:        AstarteLowPriorityName,
 }
 
+// AstartePriorityClassUsersAnnotation lists the Astarte instances using a PriorityClass. The Operator manages only
+// the PriorityClasses carrying it.
+const AstartePriorityClassUsersAnnotation = "api.astarte-platform.org/used-by"
+
+var astartePriorityClassDefaultNames = []string{AstarteHighPriorityName, AstarteMidPriorityName, AstarteLowPriorityName}
+
+var astartePriorityClassDescriptions = map[string]string{
+	AstarteHighPriorityName: "Astarte high-priority pods (e.g. RabbitMQ, VerneMQ, Astarte Data Updater Plant) should be in this priority class.",
+	AstarteMidPriorityName:  "Astarte mid-priority pods should be in this priority class.",
+	AstarteLowPriorityName:  "Astarte low-priority pods should be in this priority class.",
+}
+
+var astartePriorityClassDefaultValues = map[string]int{
+	AstarteHighPriorityName: apiv2alpha1.DefaultAstarteHighPriority,
+	AstarteMidPriorityName:  apiv2alpha1.DefaultAstarteMidPriority,
+	AstarteLowPriorityName:  apiv2alpha1.DefaultAstarteLowPriority,
+}
+
+// EnsureAstartePriorityClasses reconciles the PriorityClasses used by instance, and releases the ones it does not use
+// anymore. PriorityClasses are cluster-scoped and can be shared by several instances: each of them is listed in the
+// AstartePriorityClassUsersAnnotation of the PriorityClasses it uses, and the last one leaving deletes them.
 func EnsureAstartePriorityClasses(instance *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	inUse := []string{}
+
 	// Shall we use priorityClasses?
 	if instance.Spec.Features.AstartePodPriorities.IsEnabled() {
+		for _, defaultName := range astartePriorityClassDefaultNames {
+			name := getAstartePriorityClassName(instance, defaultName)
+			value := int32(getAstartePriorityClassValue(instance, defaultName))
+			if err := ensureAstartePriorityClass(name, value, astartePriorityClassDescriptions[defaultName], instance, c); err != nil {
+				return err
+			}
+			inUse = append(inUse, name)
+		}
+	}
+
+	// Values changed, or priorities got disabled: pod templates are moving away from the other PriorityClasses.
+	// Running pods keep their priority anyway.
+	return releaseAstartePriorityClasses(instance, inUse, c)
+}
 
-		// we don't want to preempt other pods
-		preemptNever := v1.PreemptNever
+func ensureAstartePriorityClass(name string, value int32, description string, instance *apiv2alpha1.Astarte, c client.Client) error {
+	user := getAstartePriorityClassUser(instance)
+	// we don't want to preempt other pods
+	preemptNever := v1.PreemptNever
 
-		priorityClassHigh := &scheduling.PriorityClass{
-			ObjectMeta: metav1.ObjectMeta{Name: AstarteHighPriorityName},
+	priorityClass := &scheduling.PriorityClass{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name}, priorityClass); kerrors.IsNotFound(err) {
+		priorityClass = &scheduling.PriorityClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{AstartePriorityClassUsersAnnotation: user},
+			},
+			Value:            value,
+			GlobalDefault:    false,
+			PreemptionPolicy: &preemptNever,
+			Description:      description,
 		}
-
-		// Reconcile the high priorityClass
-		if result, err := controllerutil.CreateOrUpdate(context.TODO(), c, priorityClassHigh, func() error {
-			priorityClassHigh.GlobalDefault = false
-			priorityClassHigh.PreemptionPolicy = &preemptNever
-			// default value makes sure this pointer is not nil
-			priorityClassHigh.Value = int32(*instance.Spec.Features.AstartePodPriorities.AstarteHighPriority)
-			priorityClassHigh.Description = "Astarte high-priority pods (e.g. RabbitMQ, VerneMQ, Astarte Data Updater Plant) should be in this priority class."
-			return nil
-		}); err != nil {
+		if err := c.Create(context.TODO(), priorityClass); err != nil {
 			return err
-		} else {
-			misc.LogCreateOrUpdateOperationResult(log, result, instance, priorityClassHigh)
 		}
+		misc.LogCreateOrUpdateOperationResult(log, controllerutil.OperationResultCreated, instance, priorityClass)
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-		// Mid priorityClass
-		priorityClassMid := &scheduling.PriorityClass{
-			ObjectMeta: metav1.ObjectMeta{Name: AstarteMidPriorityName},
-		}
+	usedBy, managed := priorityClass.GetAnnotations()[AstartePriorityClassUsersAnnotation]
+	if !managed && !isLegacyAstartePriorityClass(priorityClass) {
+		return fmt.Errorf("PriorityClass %s exists and is not managed by the Astarte Operator", name)
+	}
+	// The value is immutable, which is why another value gets a PriorityClass of its own
+	if priorityClass.Value != value {
+		return fmt.Errorf("PriorityClass %s has value %d instead of %d", name, priorityClass.Value, value)
+	}
 
-		// Reconcile the priorityClass
-		if result, err := controllerutil.CreateOrUpdate(context.TODO(), c, priorityClassMid, func() error {
-			priorityClassMid.GlobalDefault = false
-			priorityClassMid.PreemptionPolicy = &preemptNever
-			// default value makes sure this pointer is not nil
-			priorityClassMid.Value = int32(*instance.Spec.Features.AstartePodPriorities.AstarteMidPriority)
-			priorityClassMid.Description = "Astarte mid-priority pods should be in this priority class."
-			return nil
-		}); err != nil {
-			return err
-		} else {
-			misc.LogCreateOrUpdateOperationResult(log, result, instance, priorityClassMid)
+	users := getAstartePriorityClassUsers(usedBy)
+	if !slices.Contains(users, user) {
+		users = append(users, user)
+		slices.Sort(users)
+	}
+
+	updated := priorityClass.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[AstartePriorityClassUsersAnnotation] = strings.Join(users, ",")
+	updated.GlobalDefault = false
+	updated.Description = description
+	if equality.Semantic.DeepEqual(priorityClass, updated) {
+		misc.LogCreateOrUpdateOperationResult(log, controllerutil.OperationResultNone, instance, priorityClass)
+		return nil
+	}
+
+	// updated carries the resourceVersion we read: concurrent changes make this fail, and we'll retry
+	if err := c.Update(context.TODO(), updated); err != nil {
+		return err
+	}
+	misc.LogCreateOrUpdateOperationResult(log, controllerutil.OperationResultUpdated, instance, updated)
+	return nil
+}
+
+// ReleaseAstartePriorityClasses removes instance from the users of its PriorityClasses, and deletes the ones no one
+// uses anymore
+func ReleaseAstartePriorityClasses(instance *apiv2alpha1.Astarte, c client.Client) error {
+	return releaseAstartePriorityClasses(instance, nil, c)
+}
+
+// releaseAstartePriorityClasses removes instance from the users of the PriorityClasses not in inUse, and deletes the
+// ones no one uses anymore
+func releaseAstartePriorityClasses(instance *apiv2alpha1.Astarte, inUse []string, c client.Client) error {
+	priorityClasses := &scheduling.PriorityClassList{}
+	if err := c.List(context.TODO(), priorityClasses); err != nil {
+		return err
+	}
+
+	user := getAstartePriorityClassUser(instance)
+	for i := range priorityClasses.Items {
+		priorityClass := &priorityClasses.Items[i]
+		usedBy, managed := priorityClass.GetAnnotations()[AstartePriorityClassUsersAnnotation]
+		users := getAstartePriorityClassUsers(usedBy)
+		if !managed || slices.Contains(inUse, priorityClass.Name) || !slices.Contains(users, user) {
+			continue
 		}
 
-		// Low priorityClass
-		priorityClassLow := &scheduling.PriorityClass{
-			ObjectMeta: metav1.ObjectMeta{Name: AstarteLowPriorityName},
+		users = slices.DeleteFunc(users, func(u string) bool { return u == user })
+		if len(users) == 0 {
+			log.Info("Deleting PriorityClass no Astarte instance uses anymore", "PriorityClass", priorityClass.Name)
+			// Don't delete it if someone started using it in the meantime
+			rv := priorityClass.GetResourceVersion()
+			if err := c.Delete(context.TODO(), priorityClass, client.Preconditions{ResourceVersion: &rv}); err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
+			continue
 		}
 
-		// Reconcile the priorityClass
-		if result, err := controllerutil.CreateOrUpdate(context.TODO(), c, priorityClassLow, func() error {
-			priorityClassLow.GlobalDefault = false
-			priorityClassLow.PreemptionPolicy = &preemptNever
-			// default value makes sure this pointer is not nil
-			priorityClassLow.Value = int32(*instance.Spec.Features.AstartePodPriorities.AstarteLowPriority)
-			priorityClassLow.Description = "Astarte low-priority pods should be in this priority class."
-			return nil
-		}); err != nil {
+		// priorityClass carries the resourceVersion we read: concurrent changes make this fail, and we'll retry
+		priorityClass.Annotations[AstartePriorityClassUsersAnnotation] = strings.Join(users, ",")
+		if err := c.Update(context.TODO(), priorityClass); err != nil {
 			return err
-		} else {
-			misc.LogCreateOrUpdateOperationResult(log, result, instance, priorityClassLow)
 		}
 	}
+
 	return nil
 }
 
+// isLegacyAstartePriorityClass tells whether priorityClass was created by an Operator predating
+// AstartePriorityClassUsersAnnotation, which used to manage the default PriorityClasses only
+func isLegacyAstartePriorityClass(priorityClass *scheduling.PriorityClass) bool {
+	description, ok := astartePriorityClassDescriptions[priorityClass.Name]
+	return ok && priorityClass.Description == description
+}
+
+func getAstartePriorityClassUser(cr *apiv2alpha1.Astarte) string {
+	return cr.Namespace + "/" + cr.Name
+}
+
+func getAstartePriorityClassUsers(usedBy string) []string {
+	return slices.DeleteFunc(strings.Split(usedBy, ","), func(user string) bool { return user == "" })
+}
+
+// getAstartePriorityClassName returns the name of the PriorityClass cr uses in place of one of the default ones.
+// The value of a PriorityClass cannot change, hence values other than the default one get PriorityClasses of their own.
+func getAstartePriorityClassName(cr *apiv2alpha1.Astarte, defaultName string) string {
+	name := defaultName
+	if priorities := cr.Spec.Features.AstartePodPriorities; priorities != nil {
+		override := ""
+		switch defaultName {
+		case AstarteHighPriorityName:
+			override = priorities.AstarteHighPriorityClassName
+		case AstarteMidPriorityName:
+			override = priorities.AstarteMidPriorityClassName
+		case AstarteLowPriorityName:
+			override = priorities.AstarteLowPriorityClassName
+		}
+		if override != "" {
+			name = override
+		}
+	}
+
+	if value := getAstartePriorityClassValue(cr, defaultName); value != astartePriorityClassDefaultValues[defaultName] {
+		return fmt.Sprintf("%s-%d", name, value)
+	}
+	return name
+}
+
+func getAstartePriorityClassValue(cr *apiv2alpha1.Astarte, defaultName string) int {
+	priorities := cr.Spec.Features.AstartePodPriorities
+	if priorities == nil {
+		return astartePriorityClassDefaultValues[defaultName]
+	}
+
+	switch defaultName {
+	case AstarteHighPriorityName:
+		return pointy.IntValue(priorities.AstarteHighPriority, apiv2alpha1.DefaultAstarteHighPriority)
+	case AstarteMidPriorityName:
+		return pointy.IntValue(priorities.AstarteMidPriority, apiv2alpha1.DefaultAstarteMidPriority)
+	default:
+		return pointy.IntValue(priorities.AstarteLowPriority, apiv2alpha1.DefaultAstarteLowPriority)
+	}
+}
+
 func GetDefaultAstartePriorityClassNameForComponent(component apiv2alpha1.AstarteComponent) string {
 	return defaultComponentPriorityClass[component]
 }
@@ -20,6 +20,8 @@ package reconcile
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
@@ -30,6 +32,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -112,10 +115,13 @@ var _ = Describe("Astarte PriorityClass reconcile tests", Ordered, Serial, func(
 			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
 
 			// Check all PriorityClasses exist with expected values
-			testPriorityClass := func(name string, expected int32) {
+			testPriorityClass := func(defaultName string, expected int32) {
 				pc := &schedulingv1.PriorityClass{}
+				name := getAstartePriorityClassName(cr, defaultName)
+				Expect(name).To(Equal(fmt.Sprintf("%s-%d", defaultName, expected)))
 				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
 				Expect(pc.Value).To(Equal(expected))
+				Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation, CustomAstarteNamespace+"/"+CustomAstarteName))
 				Expect(pc.GlobalDefault).To(BeFalse())
 				Expect(pc.PreemptionPolicy).ToNot(BeNil())
 				Expect(*pc.PreemptionPolicy).To(Equal(v1.PreemptNever))
@@ -142,9 +148,9 @@ var _ = Describe("Astarte PriorityClass reconcile tests", Ordered, Serial, func(
 			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
 
 			// Helper function to test priority class properties
-			testPriorityClassProperties := func(name string, expectedValue int32, expectedDescriptionSubstring string) {
+			testPriorityClassProperties := func(defaultName string, expectedValue int32, expectedDescriptionSubstring string) {
 				pc := &schedulingv1.PriorityClass{}
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: getAstartePriorityClassName(cr, defaultName)}, pc)).To(Succeed())
 				Expect(pc.Value).To(Equal(expectedValue))
 				Expect(pc.Description).To(ContainSubstring(expectedDescriptionSubstring))
 				Expect(pc.GlobalDefault).To(BeFalse())
@@ -167,8 +173,9 @@ var _ = Describe("Astarte PriorityClass reconcile tests", Ordered, Serial, func(
 				{AstarteMidPriorityName, int32(150), "Astarte mid-priority pods"},
 				{AstarteLowPriorityName, int32(15), "Astarte low-priority pods"},
 			} {
+				name := getAstartePriorityClassName(cr, testCase.name)
 				pc := &schedulingv1.PriorityClass{}
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: testCase.name}, pc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
 
 				originalValue := pc.Value
 				originalDescription := pc.Description
@@ -179,7 +186,7 @@ var _ = Describe("Astarte PriorityClass reconcile tests", Ordered, Serial, func(
 
 				// Verify the changes were applied
 				modifiedPc := &schedulingv1.PriorityClass{}
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: testCase.name}, modifiedPc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, modifiedPc)).To(Succeed())
 				Expect(modifiedPc.Description).To(Equal("modified description"))
 
 				// Re-run reconciliation - should restore all fields to expected values
@@ -187,24 +194,166 @@ var _ = Describe("Astarte PriorityClass reconcile tests", Ordered, Serial, func(
 
 				// Verify reconciliation restored the correct values
 				restoredPc := &schedulingv1.PriorityClass{}
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: testCase.name}, restoredPc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, restoredPc)).To(Succeed())
 				Expect(restoredPc.Value).To(Equal(originalValue))
 				Expect(restoredPc.Description).To(Equal(originalDescription))
 				Expect(restoredPc.GlobalDefault).To(BeFalse())
 				Expect(*restoredPc.PreemptionPolicy).To(Equal(v1.PreemptNever))
 
 				// Try to change non-mutable field (Value) - should trigger an error on update
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName}, pc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
 				pc.Value = originalValue + 100
 				Expect(k8sClient.Update(context.Background(), pc)).ToNot(Succeed())
 				// Re-run ensure - should not change anything as Value cannot be changed
 				Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
-				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: testCase.name}, pc)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc)).To(Succeed())
 				Expect(pc.Value).To(Equal(originalValue)) // Value should remain unchanged
 			}
 		})
 	})
 
+	Describe("Shared PriorityClasses", func() {
+		AfterEach(func() {
+			priorityClasses := &schedulingv1.PriorityClassList{}
+			Expect(k8sClient.List(context.Background(), priorityClasses)).To(Succeed())
+			for i := range priorityClasses.Items {
+				// Leave the built-in ones alone
+				if !strings.HasPrefix(priorityClasses.Items[i].Name, "system-") {
+					_ = k8sClient.Delete(context.Background(), &priorityClasses.Items[i])
+				}
+			}
+		})
+
+		It("should move to another PriorityClass when the value changes", func() {
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{Enable: true}
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(getAstartePriorityClassName(cr, AstarteHighPriorityName)).To(Equal(AstarteHighPriorityName))
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName}, pc)).To(Succeed())
+			Expect(pc.Value).To(Equal(int32(apiv2alpha1.DefaultAstarteHighPriority)))
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation, CustomAstarteNamespace+"/"+CustomAstarteName))
+
+			cr.Spec.Features.AstartePodPriorities.AstarteHighPriority = pointy.Int(3000)
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			// A new PriorityClass takes the place of the old one, which no one uses anymore
+			Expect(getAstartePriorityClassName(cr, AstarteHighPriorityName)).To(Equal(AstarteHighPriorityName + "-3000"))
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName + "-3000"}, pc)).To(Succeed())
+			Expect(pc.Value).To(Equal(int32(3000)))
+			Expect(apierrors.IsNotFound(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName}, pc))).To(BeTrue())
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteMidPriorityName}, pc)).To(Succeed())
+		})
+
+		It("should keep the PriorityClasses other instances use", func() {
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{Enable: true}
+			other := cr.DeepCopy()
+			other.SetName("other-astarte")
+			Expect(EnsureAstartePriorityClasses(other, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation,
+				CustomAstarteNamespace+"/"+CustomAstarteName+","+CustomAstarteNamespace+"/other-astarte"))
+
+			// Disabling priorities releases the PriorityClasses
+			cr.Spec.Features.AstartePodPriorities.Enable = false
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteHighPriorityName}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation, CustomAstarteNamespace+"/other-astarte"))
+
+			// And the last instance leaving removes them
+			Expect(ReleaseAstartePriorityClasses(other, k8sClient)).To(Succeed())
+			for _, name := range []string{AstarteHighPriorityName, AstarteMidPriorityName, AstarteLowPriorityName} {
+				Expect(apierrors.IsNotFound(k8sClient.Get(context.Background(), types.NamespacedName{Name: name}, pc))).To(BeTrue())
+			}
+		})
+
+		It("should create the PriorityClasses named by the instance", func() {
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{
+				Enable:                      true,
+				AstarteMidPriorityClassName: "example-mid-priority",
+				AstarteLowPriority:          pointy.Int(5),
+				AstarteLowPriorityClassName: "example-low-priority",
+			}
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "example-low-priority-5"}, pc)).To(Succeed())
+			Expect(pc.Value).To(Equal(int32(5)))
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "example-mid-priority"}, pc)).To(Succeed())
+			Expect(pc.Value).To(Equal(int32(apiv2alpha1.DefaultAstarteMidPriority)))
+			Expect(getAstartePriorityClassName(cr, AstarteLowPriorityName)).To(Equal("example-low-priority-5"))
+			Expect(getAstartePriorityClassName(cr, AstarteMidPriorityName)).To(Equal("example-mid-priority"))
+			Expect(getAstartePriorityClassName(cr, AstarteHighPriorityName)).To(Equal(AstarteHighPriorityName))
+		})
+
+		It("should not touch PriorityClasses it does not manage", func() {
+			preemptNever := v1.PreemptNever
+			Expect(k8sClient.Create(context.Background(), &schedulingv1.PriorityClass{
+				ObjectMeta:       metav1.ObjectMeta{Name: "example-low-priority"},
+				Value:            apiv2alpha1.DefaultAstarteLowPriority,
+				PreemptionPolicy: &preemptNever,
+			})).To(Succeed())
+
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{
+				Enable:                      true,
+				AstarteLowPriorityClassName: "example-low-priority",
+			}
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).ToNot(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "example-low-priority"}, pc)).To(Succeed())
+			Expect(pc.Annotations).ToNot(HaveKey(AstartePriorityClassUsersAnnotation))
+		})
+
+		It("should adopt the PriorityClasses created by previous releases", func() {
+			preemptNever := v1.PreemptNever
+			Expect(k8sClient.Create(context.Background(), &schedulingv1.PriorityClass{
+				ObjectMeta:       metav1.ObjectMeta{Name: AstarteLowPriorityName},
+				Value:            apiv2alpha1.DefaultAstarteLowPriority,
+				PreemptionPolicy: &preemptNever,
+				Description:      astartePriorityClassDescriptions[AstarteLowPriorityName],
+			})).To(Succeed())
+
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{Enable: true}
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: AstarteLowPriorityName}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation, CustomAstarteNamespace+"/"+CustomAstarteName))
+		})
+
+		It("should keep the users managed by other Operators", func() {
+			preemptNever := v1.PreemptNever
+			Expect(k8sClient.Create(context.Background(), &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "example-low-priority",
+					Annotations: map[string]string{AstartePriorityClassUsersAnnotation: "another-team/astarte"},
+				},
+				Value:            apiv2alpha1.DefaultAstarteLowPriority,
+				PreemptionPolicy: &preemptNever,
+			})).To(Succeed())
+
+			cr.Spec.Features.AstartePodPriorities = &apiv2alpha1.AstartePodPrioritiesSpec{
+				Enable:                      true,
+				AstarteLowPriorityClassName: "example-low-priority",
+			}
+			Expect(EnsureAstartePriorityClasses(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			pc := &schedulingv1.PriorityClass{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "example-low-priority"}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation,
+				"another-team/astarte,"+CustomAstarteNamespace+"/"+CustomAstarteName))
+
+			// And the PriorityClass survives the instance
+			Expect(ReleaseAstartePriorityClasses(cr, k8sClient)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "example-low-priority"}, pc)).To(Succeed())
+			Expect(pc.Annotations).To(HaveKeyWithValue(AstartePriorityClassUsersAnnotation, "another-team/astarte"))
+		})
+	})
+
 	Describe("GetDefaultAstartePriorityClassNameForComponent", func() {
 		It("should return expected mapping for known components", func() {
 			cases := map[apiv2alpha1.AstarteComponent]string{
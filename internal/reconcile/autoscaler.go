@@ -0,0 +1,142 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"go.openly.dev/pointy"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+)
+
+const defaultTargetCPUUtilizationPercentage int32 = 80
+
+// ensureHorizontalPodAutoscaler reconciles the HorizontalPodAutoscaler managed by the Operator for the given
+// Deployment or StatefulSet. The HorizontalPodAutoscaler shares its name with its target, and is deleted
+// whenever it is not requested anymore.
+func ensureHorizontalPodAutoscaler(targetName, targetKind string, resource apiv2alpha1.AstarteGenericClusteredResource,
+	cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	if !isOperatorManagedHPAEnabled(resource, cr) {
+		return deleteHorizontalPodAutoscalerIfExists(targetName, cr, c)
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: targetName, Namespace: cr.Namespace}}
+	result, err := controllerutil.CreateOrUpdate(context.TODO(), c, hpa, func() error {
+		// Don't hijack HorizontalPodAutoscalers created by someone else
+		if hpa.ResourceVersion != "" && !metav1.IsControlledBy(hpa, cr) {
+			return fmt.Errorf("HorizontalPodAutoscaler %s already exists and is not managed by the Operator", targetName)
+		}
+		if err := controllerutil.SetControllerReference(cr, hpa, scheme); err != nil {
+			return err
+		}
+
+		hpa.ObjectMeta.Labels = map[string]string{"component": "astarte", "app": targetName}
+		hpa.Spec = autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       targetKind,
+				Name:       targetName,
+			},
+			MinReplicas: pointy.Int32(getHPAMinReplicas(resource)),
+			MaxReplicas: *resource.Autoscale.MaxReplicas,
+			Metrics:     getHPAMetrics(resource.Autoscale),
+			Behavior:    resource.Autoscale.Behavior,
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.LogCreateOrUpdateOperationResult(log, result, cr, hpa)
+	return nil
+}
+
+func deleteHorizontalPodAutoscalerIfExists(name string, cr *apiv2alpha1.Astarte, c client.Client) error {
+	theHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, theHPA); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Never touch HorizontalPodAutoscalers we don't own
+	if !metav1.IsControlledBy(theHPA, cr) {
+		return nil
+	}
+
+	log.Info("Deleting previously existing HorizontalPodAutoscaler, which is no longer needed", "HPA.Name", name)
+	if err := c.Delete(context.TODO(), theHPA); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func isOperatorManagedHPAEnabled(resource apiv2alpha1.AstarteGenericClusteredResource, cr *apiv2alpha1.Astarte) bool {
+	return cr.Spec.Features.Autoscaling && resource.Autoscale.IsOperatorManaged()
+}
+
+func getHPAMinReplicas(resource apiv2alpha1.AstarteGenericClusteredResource) int32 {
+	if resource.Autoscale != nil && resource.Autoscale.MinReplicas != nil {
+		return *resource.Autoscale.MinReplicas
+	}
+	return pointy.Int32Value(resource.Replicas, apiv2alpha1.DefaultReplicas)
+}
+
+func getHPAMetrics(autoscaler *apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec) []autoscalingv2.MetricSpec {
+	targetCPU := autoscaler.TargetCPUUtilizationPercentage
+	if targetCPU == nil && autoscaler.TargetMemoryUtilizationPercentage == nil {
+		targetCPU = pointy.Int32(defaultTargetCPUUtilizationPercentage)
+	}
+
+	metrics := []autoscalingv2.MetricSpec{}
+	if targetCPU != nil {
+		metrics = append(metrics, getHPAResourceUtilizationMetric(v1.ResourceCPU, *targetCPU))
+	}
+	if autoscaler.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, getHPAResourceUtilizationMetric(v1.ResourceMemory, *autoscaler.TargetMemoryUtilizationPercentage))
+	}
+
+	return metrics
+}
+
+func getHPAResourceUtilizationMetric(resourceName v1.ResourceName, target int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: resourceName,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: pointy.Int32(target),
+			},
+		},
+	}
+}
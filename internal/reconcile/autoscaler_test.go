@@ -0,0 +1,164 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Autoscaler testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "autoscaler-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test ensureHorizontalPodAutoscaler", func() {
+		It("should create the HPA when requested and delete it when not needed anymore", func() {
+			cr.Spec.Features.Autoscaling = true
+			cr.Spec.Components.Dashboard.Deploy = pointy.Bool(true)
+			cr.Spec.Components.Dashboard.Autoscale = &apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec{
+				MinReplicas:                       pointy.Int32(2),
+				MaxReplicas:                       pointy.Int32(5),
+				TargetMemoryUtilizationPercentage: pointy.Int32(70),
+			}
+			Expect(k8sClient.Update(context.Background(), cr)).To(Succeed())
+
+			Expect(EnsureAstarteDashboard(cr, cr.Spec.Components.Dashboard, k8sClient, scheme.Scheme)).To(Succeed())
+
+			deploymentName := CustomAstarteName + "-dashboard"
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: deploymentName, Namespace: CustomAstarteNamespace}, hpa)
+			}, Timeout, Interval).Should(Succeed())
+			Expect(metav1.IsControlledBy(hpa, cr)).To(BeTrue())
+			Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
+			Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(deploymentName))
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(v1.ResourceMemory))
+
+			// The Deployment starts from the lower bound
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: deploymentName, Namespace: CustomAstarteNamespace}, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+
+			// Disabling autoscaling removes the HPA
+			cr.Spec.Features.Autoscaling = false
+			Expect(EnsureAstarteDashboard(cr, cr.Spec.Components.Dashboard, k8sClient, scheme.Scheme)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: deploymentName, Namespace: CustomAstarteNamespace}, hpa)
+			}, Timeout, Interval).ShouldNot(Succeed())
+		})
+
+		It("should not touch HPAs it does not own", func() {
+			cr.Spec.Features.Autoscaling = true
+			resource := apiv2alpha1.AstarteGenericClusteredResource{
+				Autoscale: &apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec{MaxReplicas: pointy.Int32(3)},
+			}
+
+			foreignHPA := &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "foreign-hpa", Namespace: CustomAstarteNamespace},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "foreign-hpa"},
+					MaxReplicas:    3,
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), foreignHPA)).To(Succeed())
+
+			Expect(ensureHorizontalPodAutoscaler("foreign-hpa", "Deployment", resource, cr, k8sClient, scheme.Scheme)).ToNot(Succeed())
+
+			cr.Spec.Features.Autoscaling = false
+			Expect(ensureHorizontalPodAutoscaler("foreign-hpa", "Deployment", resource, cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "foreign-hpa", Namespace: CustomAstarteNamespace}, foreignHPA)).To(Succeed())
+
+			Expect(k8sClient.Delete(context.Background(), foreignHPA)).To(Succeed())
+		})
+	})
+
+	Describe("Test getHPAMetrics", func() {
+		It("should default to a CPU target", func() {
+			metrics := getHPAMetrics(&apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec{MaxReplicas: pointy.Int32(3)})
+			Expect(metrics).To(HaveLen(1))
+			Expect(metrics[0].Resource.Name).To(Equal(v1.ResourceCPU))
+			Expect(*metrics[0].Resource.Target.AverageUtilization).To(Equal(defaultTargetCPUUtilizationPercentage))
+		})
+
+		It("should honor both CPU and memory targets", func() {
+			metrics := getHPAMetrics(&apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec{
+				MaxReplicas:                       pointy.Int32(3),
+				TargetCPUUtilizationPercentage:    pointy.Int32(60),
+				TargetMemoryUtilizationPercentage: pointy.Int32(75),
+			})
+			Expect(metrics).To(HaveLen(2))
+			Expect(*metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(60)))
+			Expect(*metrics[1].Resource.Target.AverageUtilization).To(Equal(int32(75)))
+		})
+	})
+
+	Describe("Test getReplicaCountForResource with an Operator managed HPA", func() {
+		It("should leave the current replica count alone while the HPA is active", func() {
+			cr.Spec.Features.Autoscaling = true
+			resource := &apiv2alpha1.AstarteGenericClusteredResource{
+				Replicas:  pointy.Int32(1),
+				Autoscale: &apiv2alpha1.AstarteGenericClusteredResourceAutoscalerSpec{MaxReplicas: pointy.Int32(4)},
+			}
+
+			Expect(*getReplicaCountForResource(resource, pointy.Int32(3), cr, k8sClient, log)).To(Equal(int32(3)))
+			Expect(*getReplicaCountForResource(resource, nil, cr, k8sClient, log)).To(Equal(int32(1)))
+
+			cr.Spec.Features.Autoscaling = false
+			Expect(*getReplicaCountForResource(resource, pointy.Int32(3), cr, k8sClient, log)).To(Equal(int32(1)))
+		})
+	})
+})
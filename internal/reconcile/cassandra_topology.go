@@ -0,0 +1,250 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.openly.dev/pointy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/cql"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+)
+
+// The reasons of the CassandraReplicationSatisfiable condition
+const (
+	// CassandraReplicationReasonSatisfiable means the cluster has enough nodes in the right datacenters
+	CassandraReplicationReasonSatisfiable = "ReplicationSatisfiable"
+	// CassandraReplicationReasonUnsatisfiable means the replication strategy or factor cannot be met by the cluster
+	CassandraReplicationReasonUnsatisfiable = "ReplicationUnsatisfiable"
+	// CassandraReplicationReasonTopologyUnknown means the topology of the cluster could not be read
+	CassandraReplicationReasonTopologyUnknown = "TopologyUnknown"
+)
+
+// cassandraTopologyCheckInterval is how often the topology is read again, as long as the Astarte spec does not change
+const cassandraTopologyCheckInterval = 5 * time.Minute
+
+// cassandraTopologyCheck records when the topology of an Astarte instance was last read, and for which generation
+type cassandraTopologyCheck struct {
+	generation int64
+	time       time.Time
+}
+
+// lastCassandraTopologyChecks maps the UID of each Astarte instance to its last cassandraTopologyCheck. Reading the
+// topology might mean waiting for each configured node to time out, so it must not happen on every reconciliation.
+var lastCassandraTopologyChecks sync.Map
+
+// IsCassandraTopologyCheckDue returns whether the topology of the Cassandra cluster should be read again, i.e. when the
+// Astarte spec changed or the last check is older than cassandraTopologyCheckInterval.
+func IsCassandraTopologyCheckDue(cr *apiv2alpha1.Astarte) bool {
+	value, ok := lastCassandraTopologyChecks.Load(cr.UID)
+	if !ok {
+		return true
+	}
+	last := value.(cassandraTopologyCheck)
+	return last.generation != cr.Generation || time.Since(last.time) >= cassandraTopologyCheckInterval
+}
+
+// ForgetCassandraTopologyCheck drops what is known about the last topology check of the given Astarte instance
+func ForgetCassandraTopologyCheck(uid types.UID) {
+	lastCassandraTopologyChecks.Delete(uid)
+}
+
+// CheckCassandraTopology reads the topology of the Cassandra cluster and checks whether it can satisfy the
+// replication of the Astarte system keyspace. It returns the CassandraReplicationSatisfiable condition and the
+// observed topology, which is nil when it could not be read.
+func CheckCassandraTopology(cr *apiv2alpha1.Astarte, c client.Client) (metav1.Condition, *apiv2alpha1.AstarteCassandraTopologyStatus) {
+	condition := metav1.Condition{Type: apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable}
+	lastCassandraTopologyChecks.Store(cr.UID, cassandraTopologyCheck{generation: cr.Generation, time: time.Now()})
+
+	topology, err := ObserveCassandraTopology(cr, c)
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = CassandraReplicationReasonTopologyUnknown
+		condition.Message = err.Error()
+		return condition, nil
+	}
+
+	if problems := CheckCassandraReplication(cr.Spec.Cassandra.AstarteSystemKeyspace, topology); len(problems) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = CassandraReplicationReasonUnsatisfiable
+		condition.Message = strings.Join(problems, "; ")
+		return condition, topology
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = CassandraReplicationReasonSatisfiable
+	condition.Message = fmt.Sprintf("%s can be satisfied by %s", describeReplication(cr.Spec.Cassandra.AstarteSystemKeyspace), describeTopology(topology))
+	return condition, topology
+}
+
+// ObserveCassandraTopology asks the configured Cassandra nodes in turn which datacenters the cluster is made of, and
+// how many nodes each of them has, until one of them answers.
+func ObserveCassandraTopology(cr *apiv2alpha1.Astarte, c client.Client) (*apiv2alpha1.AstarteCassandraTopologyStatus, error) {
+	connection := cr.Spec.Cassandra.Connection
+	if connection == nil || len(connection.Nodes) == 0 {
+		return nil, errors.New("no Cassandra nodes are configured")
+	}
+
+	username, password, err := misc.GetCassandraCredentialsFor(cr, c)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the Cassandra credentials: %w", err)
+	}
+
+	failures := []string{}
+	for _, node := range connection.Nodes {
+		topology, err := observeCassandraTopologyFrom(node, username, password, cr, c)
+		if err == nil {
+			return topology, nil
+		}
+		failures = append(failures, err.Error())
+	}
+	return nil, fmt.Errorf("could not read the Cassandra topology from any node: %s", strings.Join(failures, "; "))
+}
+
+func observeCassandraTopologyFrom(node apiv2alpha1.HostAndPort, username, password string, cr *apiv2alpha1.Astarte,
+	c client.Client) (*apiv2alpha1.AstarteCassandraTopologyStatus, error) {
+	port := pointy.Int32Value(node.Port, defaultCassandraPort)
+	address := joinHostPort(node.Host, port)
+	conn, err := dialBackingService(node.Host, port, cr.Spec.Cassandra.Connection.SSLConfiguration, cr.Spec.Cassandra.TopologyCheck.GetTimeout(), cr, c)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	cqlConn := cql.NewConn(conn)
+	if err := cqlConn.Startup(username, password); err != nil {
+		return nil, fmt.Errorf("could not log in to %s: %w", address, err)
+	}
+	local, err := cqlConn.Query("SELECT data_center FROM system.local")
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %w", address, err)
+	}
+	peers, err := cqlConn.Query("SELECT data_center FROM system.peers")
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %w", address, err)
+	}
+
+	topology, err := countCassandraDataCenters(local, peers)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected answer from %s: %w", address, err)
+	}
+	return topology, nil
+}
+
+// countCassandraDataCenters counts the nodes of each datacenter, given the data_center columns of system.local
+// and system.peers
+func countCassandraDataCenters(local, peers *cql.Rows) (*apiv2alpha1.AstarteCassandraTopologyStatus, error) {
+	nodes := map[string]int32{}
+	for _, rows := range []*cql.Rows{local, peers} {
+		dataCenters, ok := rows.Column("data_center")
+		if !ok {
+			return nil, errors.New("no data_center column")
+		}
+		for _, dataCenter := range dataCenters {
+			// Peers which are still joining may not have announced their datacenter yet
+			if dataCenter != nil {
+				nodes[string(dataCenter)]++
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("system.local reports no datacenter")
+	}
+
+	topology := &apiv2alpha1.AstarteCassandraTopologyStatus{}
+	for name, count := range nodes {
+		topology.DataCenters = append(topology.DataCenters, apiv2alpha1.AstarteCassandraDataCenterStatus{Name: name, Nodes: count})
+		topology.Nodes += count
+	}
+	sort.Slice(topology.DataCenters, func(i, j int) bool {
+		return topology.DataCenters[i].Name < topology.DataCenters[j].Name
+	})
+	return topology, nil
+}
+
+// CheckCassandraReplication returns the reasons why topology cannot satisfy the replication of the Astarte system
+// keyspace, if any
+func CheckCassandraReplication(ask apiv2alpha1.AstarteSystemKeyspaceSpec, topology *apiv2alpha1.AstarteCassandraTopologyStatus) []string {
+	problems := []string{}
+
+	if isSimpleReplicationStrategy(ask) {
+		if int32(ask.ReplicationFactor) > topology.Nodes {
+			problems = append(problems, fmt.Sprintf("the replication factor is %d, but the cluster has %d nodes",
+				ask.ReplicationFactor, topology.Nodes))
+		}
+		if len(topology.DataCenters) > 1 {
+			problems = append(problems, fmt.Sprintf("SimpleStrategy does not account for the %d datacenters of the cluster, use NetworkTopologyStrategy instead",
+				len(topology.DataCenters)))
+		}
+		return problems
+	}
+
+	nodes := map[string]int32{}
+	for _, dataCenter := range topology.DataCenters {
+		nodes[dataCenter.Name] = dataCenter.Nodes
+	}
+	replication := getDataCenterReplication(ask)
+	dataCenters := make([]string, 0, len(replication))
+	for dataCenter := range replication {
+		dataCenters = append(dataCenters, dataCenter)
+	}
+	sort.Strings(dataCenters)
+
+	for _, dataCenter := range dataCenters {
+		count, ok := nodes[dataCenter]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("datacenter %s does not exist in the cluster", dataCenter))
+		case int32(replication[dataCenter]) > count:
+			problems = append(problems, fmt.Sprintf("datacenter %s has a replication factor of %d, but %d nodes",
+				dataCenter, replication[dataCenter], count))
+		}
+	}
+	return problems
+}
+
+// isSimpleReplicationStrategy returns whether the keyspace uses SimpleStrategy, which is also the default
+func isSimpleReplicationStrategy(ask apiv2alpha1.AstarteSystemKeyspaceSpec) bool {
+	return ask.ReplicationStrategy == "" || ask.ReplicationStrategy == "SimpleStrategy"
+}
+
+func describeReplication(ask apiv2alpha1.AstarteSystemKeyspaceSpec) string {
+	if isSimpleReplicationStrategy(ask) {
+		return fmt.Sprintf("SimpleStrategy with a replication factor of %d", ask.ReplicationFactor)
+	}
+	return fmt.Sprintf("NetworkTopologyStrategy with %s", ask.DataCenterReplication)
+}
+
+func describeTopology(topology *apiv2alpha1.AstarteCassandraTopologyStatus) string {
+	dataCenters := []string{}
+	for _, dataCenter := range topology.DataCenters {
+		dataCenters = append(dataCenters, fmt.Sprintf("%s:%d", dataCenter.Name, dataCenter.Nodes))
+	}
+	return fmt.Sprintf("%d nodes (%s)", topology.Nodes, strings.Join(dataCenters, ","))
+}
@@ -0,0 +1,136 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"time"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/cql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Cassandra topology testing", func() {
+	dataCenterRows := func(dataCenters ...string) *cql.Rows {
+		rows := &cql.Rows{Columns: []string{"data_center"}}
+		for _, dataCenter := range dataCenters {
+			rows.Values = append(rows.Values, [][]byte{[]byte(dataCenter)})
+		}
+		return rows
+	}
+
+	topology := &apiv2alpha1.AstarteCassandraTopologyStatus{
+		Nodes: 5,
+		DataCenters: []apiv2alpha1.AstarteCassandraDataCenterStatus{
+			{Name: "dc1", Nodes: 3},
+			{Name: "dc2", Nodes: 2},
+		},
+	}
+
+	Describe("Test countCassandraDataCenters", func() {
+		It("should count the nodes of each datacenter", func() {
+			peers := dataCenterRows("dc2", "dc1", "dc2", "dc1")
+			peers.Values = append(peers.Values, [][]byte{nil})
+			Expect(countCassandraDataCenters(dataCenterRows("dc1"), peers)).To(Equal(topology))
+		})
+
+		It("should fail without a data_center column", func() {
+			_, err := countCassandraDataCenters(dataCenterRows("dc1"), &cql.Rows{Columns: []string{"peer"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Test CheckCassandraReplication", func() {
+		It("should accept a replication the cluster can satisfy", func() {
+			Expect(CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{
+				ReplicationStrategy:   "NetworkTopologyStrategy",
+				DataCenterReplication: "dc1:3,dc2:1",
+			}, topology)).To(BeEmpty())
+			Expect(CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{
+				ReplicationStrategy: "SimpleStrategy",
+				ReplicationFactor:   3,
+			}, &apiv2alpha1.AstarteCassandraTopologyStatus{Nodes: 3, DataCenters: topology.DataCenters[:1]})).To(BeEmpty())
+		})
+
+		It("should flag missing datacenters and datacenters with too few nodes", func() {
+			problems := CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{
+				ReplicationStrategy:   "NetworkTopologyStrategy",
+				DataCenterReplication: "dc3:1,dc2:3,dc1:3",
+			}, topology)
+			Expect(problems).To(Equal([]string{
+				"datacenter dc2 has a replication factor of 3, but 2 nodes",
+				"datacenter dc3 does not exist in the cluster",
+			}))
+		})
+
+		It("should flag SimpleStrategy with too few nodes, or multiple datacenters", func() {
+			problems := CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{
+				ReplicationStrategy: "SimpleStrategy",
+				ReplicationFactor:   7,
+			}, topology)
+			Expect(problems).To(HaveLen(2))
+			Expect(problems[0]).To(Equal("the replication factor is 7, but the cluster has 5 nodes"))
+			Expect(problems[1]).To(ContainSubstring("NetworkTopologyStrategy"))
+		})
+
+		It("should treat an empty strategy as SimpleStrategy", func() {
+			Expect(CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{ReplicationFactor: 7}, topology)).To(Equal(
+				CheckCassandraReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{ReplicationStrategy: "SimpleStrategy", ReplicationFactor: 7}, topology)))
+			Expect(describeReplication(apiv2alpha1.AstarteSystemKeyspaceSpec{ReplicationFactor: 1})).To(HavePrefix("SimpleStrategy"))
+		})
+	})
+
+	Describe("Test CheckCassandraTopology", func() {
+		It("should report an unknown topology when no nodes are configured", func() {
+			cr := &apiv2alpha1.Astarte{}
+			cr.Spec.Cassandra.Connection = &apiv2alpha1.AstarteCassandraConnectionSpec{}
+			condition, observed := CheckCassandraTopology(cr, nil)
+			Expect(condition.Type).To(Equal(apiv2alpha1.AstarteConditionCassandraReplicationSatisfiable))
+			Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(condition.Reason).To(Equal(CassandraReplicationReasonTopologyUnknown))
+			Expect(observed).To(BeNil())
+		})
+	})
+
+	Describe("Test IsCassandraTopologyCheckDue", func() {
+		It("should check again only once the spec changes, or the last check is old", func() {
+			cr := &apiv2alpha1.Astarte{}
+			cr.UID = "cassandra-topology-check-test"
+			cr.Generation = 1
+			cr.Spec.Cassandra.Connection = &apiv2alpha1.AstarteCassandraConnectionSpec{}
+			DeferCleanup(ForgetCassandraTopologyCheck, cr.UID)
+			Expect(IsCassandraTopologyCheckDue(cr)).To(BeTrue())
+
+			CheckCassandraTopology(cr, nil)
+			Expect(IsCassandraTopologyCheckDue(cr)).To(BeFalse())
+
+			cr.Generation = 2
+			Expect(IsCassandraTopologyCheckDue(cr)).To(BeTrue())
+
+			lastCassandraTopologyChecks.Store(cr.UID, cassandraTopologyCheck{generation: 2, time: time.Now().Add(-cassandraTopologyCheckInterval)})
+			Expect(IsCassandraTopologyCheckDue(cr)).To(BeTrue())
+
+			CheckCassandraTopology(cr, nil)
+			ForgetCassandraTopologyCheck(cr.UID)
+			Expect(IsCassandraTopologyCheckDue(cr)).To(BeTrue())
+		})
+	})
+})
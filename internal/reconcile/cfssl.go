@@ -23,7 +23,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"sort"
 
 	cfsslcsr "github.com/cloudflare/cfssl/csr"
@@ -55,17 +54,7 @@ func EnsureCFSSL(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Schem
 		// Before returning - check if we shall clean up the Deployment.
 		// It is the only thing actually requiring resources, the rest will be cleaned up eventually when the
 		// Astarte resource is deleted.
-		theDeployment := &appsv1.Deployment{}
-		err := c.Get(context.TODO(), types.NamespacedName{Name: deploymentName, Namespace: cr.Namespace}, theDeployment)
-		if err == nil {
-			log.Info("Deleting previously existing CFSSL Deployment, which is no longer needed")
-			if err = c.Delete(context.TODO(), theDeployment); err != nil {
-				return err
-			}
-		}
-
-		// That would be all for today.
-		return nil
+		return deleteCFSSLDeploymentIfExists(cr, c)
 	}
 
 	// Add common sidecars
@@ -83,12 +72,12 @@ func EnsureCFSSL(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Schem
 		return err
 	}
 
-	caSecretName := cr.Name + "-devices-ca"
-	if cr.Spec.CFSSL.CASecret.Name != "" {
-		// Don't even try creating it
-		caSecretName = cr.Spec.CFSSL.CASecret.Name
-	} else if err := ensureCFSSLCASecret(caSecretName, cr, c, scheme); err != nil {
-		return err
+	caSecretName := GetDeviceCASecretName(cr)
+	// Don't even try creating it when a custom secret is given
+	if cr.Spec.CFSSL.CASecret.Name == "" {
+		if err := ensureCFSSLCASecret(caSecretName, cr, c, scheme); err != nil {
+			return err
+		}
 	}
 
 	// Ensure the proxy secret for TLS authentication
@@ -131,6 +120,19 @@ func EnsureCFSSL(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Schem
 	return nil
 }
 
+func deleteCFSSLDeploymentIfExists(cr *apiv2alpha1.Astarte, c client.Client) error {
+	theDeployment := &appsv1.Deployment{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-cfssl", Namespace: cr.Namespace}, theDeployment)
+	if err == nil {
+		log.Info("Deleting previously existing CFSSL Deployment, which is no longer needed")
+		return c.Delete(context.TODO(), theDeployment)
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
 func ensureCFSSLCommonSidecars(resourceName string, labels map[string]string, cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
 	// Good. Now, reconcile the service first of all.
 	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: cr.Namespace}}
@@ -262,13 +264,13 @@ func getCFSSLPodSpec(deploymentName, secretName string, cr *apiv2alpha1.Astarte)
 		// is a priorityClass specified in the Astarte CR?
 		switch cr.Spec.CFSSL.PriorityClass {
 		case highPriority:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		case midPriority:
-			ps.PriorityClassName = AstarteMidPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteMidPriorityName)
 		case lowPriority:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		default:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		}
 	}
 
@@ -408,8 +410,17 @@ func getCFSSLURL(cr *apiv2alpha1.Astarte) string {
 		return cr.Spec.CFSSL.URL
 	}
 
-	// We're on defaults then. Give the standard hostname + port for our service
-	return fmt.Sprintf("http://%s-cfssl.%s.svc.cluster.local", cr.Name, cr.Namespace)
+	// We're on defaults then. Let the selected backend tell where it can be reached
+	return GetDeviceCABackend(cr).URL(cr)
+}
+
+// getCFSSLURLEnvVars returns the environment variable named name holding the CFSSL URL, preceded by the ones it references
+func getCFSSLURLEnvVars(name string, cr *apiv2alpha1.Astarte) []v1.EnvVar {
+	ret := []v1.EnvVar{}
+	if cr.Spec.CFSSL.URL == "" {
+		ret = append(ret, GetDeviceCABackend(cr).URLEnvVars(cr)...)
+	}
+	return append(ret, v1.EnvVar{Name: name, Value: getCFSSLURL(cr)})
 }
 
 func ensureCFSSLCAProxySecret(secretName, proxySecretName string, cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
@@ -0,0 +1,87 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+
+	"go.openly.dev/pointy"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+)
+
+// EnsureConnectionStrings resolves the RabbitMQ and Cassandra connection strings of the given Astarte, if any.
+// The credentials are extracted into dedicated Secrets, so that they never end up in the Pod specs, while the
+// connection details are filled in the in-memory spec for the rest of the reconciliation.
+func EnsureConnectionStrings(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	reqLogger := log.WithValues("Request.Namespace", cr.Namespace, "Request.Name", cr.Name)
+
+	if connection := cr.Spec.RabbitMQ.Connection; connection != nil && connection.ConnectionStringSecret != nil {
+		conn, err := misc.GetRabbitMQConnectionString(cr, c)
+		if err != nil {
+			return err
+		}
+
+		if err := ensureConnectionStringCredentials(misc.GetRabbitMQConnectionStringCredentialsSecretName(cr),
+			conn.Username, conn.Password, cr, c, scheme); err != nil {
+			return fmt.Errorf("could not store the RabbitMQ connection string credentials: %w", err)
+		}
+
+		connection.Host = conn.Host
+		connection.Port = pointy.Int32(conn.Port)
+		connection.VirtualHost = conn.VirtualHost
+		if conn.SSL {
+			connection.SSLConfiguration.Enable = true
+		}
+		reqLogger.V(1).Info("RabbitMQ connection string resolved", "Host", conn.Host, "Port", conn.Port)
+	}
+
+	if connection := cr.Spec.Cassandra.Connection; connection != nil && connection.ConnectionStringSecret != nil {
+		conn, err := misc.GetCassandraConnectionString(cr, c)
+		if err != nil {
+			return err
+		}
+
+		if err := ensureConnectionStringCredentials(misc.GetCassandraConnectionStringCredentialsSecretName(cr),
+			conn.Username, conn.Password, cr, c, scheme); err != nil {
+			return fmt.Errorf("could not store the Cassandra connection string credentials: %w", err)
+		}
+
+		connection.Nodes = make([]apiv2alpha1.HostAndPort, 0, len(conn.Nodes))
+		for _, node := range conn.Nodes {
+			connection.Nodes = append(connection.Nodes, apiv2alpha1.HostAndPort{Host: node.Host, Port: pointy.Int32(node.Port)})
+		}
+		reqLogger.V(1).Info("Cassandra connection string resolved", "Nodes", len(conn.Nodes))
+	}
+
+	return nil
+}
+
+func ensureConnectionStringCredentials(secretName, username, password string, cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	data := map[string]string{
+		misc.ConnectionStringCredentialsUsernameKey: username,
+		misc.ConnectionStringCredentialsPasswordKey: password,
+	}
+
+	_, err := misc.ReconcileSecretString(secretName, data, cr, c, scheme, log)
+	return err
+}
@@ -0,0 +1,220 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cfssl/config"
+	"go.openly.dev/pointy"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+)
+
+// DeviceCABackend is implemented by every backend able to sign device certificates on behalf of Astarte.
+// All backends expose a CFSSL-compatible HTTP API, which is what Pairing and VerneMQ expect to talk to.
+type DeviceCABackend interface {
+	// Ensure reconciles everything the backend needs to serve signing requests for the given Astarte.
+	Ensure(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error
+	// URL returns the base URL of the CFSSL-compatible API served by the backend. It may reference the
+	// environment variables returned by URLEnvVars, which must then precede it.
+	URL(cr *apiv2alpha1.Astarte) string
+	// URLEnvVars returns the environment variables referenced by the URL, if any.
+	URLEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar
+	// IsHealthy reports whether the backend is currently able to serve signing requests.
+	IsHealthy(cr *apiv2alpha1.Astarte, c client.Client) bool
+}
+
+// DeviceCASignerTokenKey is the key of the Secret holding the token an Astarte presents to the Operator's CA signer.
+const DeviceCASignerTokenKey = "token"
+
+// deviceCASignerTokenEnvVar is the environment variable the token is exposed as, to be expanded in the signer URL.
+const deviceCASignerTokenEnvVar = "DEVICE_CA_SIGNER_TOKEN"
+
+// operatorCASignerURL is the base URL the Operator's CA signer is reachable at from within the cluster.
+var operatorCASignerURL string
+
+// SetOperatorCASignerURL sets the base URL the Operator's CA signer is reachable at. It must be set
+// before reconciling any Astarte using the "operator" device CA backend.
+func SetOperatorCASignerURL(url string) {
+	operatorCASignerURL = strings.TrimSuffix(url, "/")
+}
+
+// GetDeviceCABackend returns the DeviceCABackend selected in the Astarte resource.
+func GetDeviceCABackend(cr *apiv2alpha1.Astarte) DeviceCABackend {
+	if cr.Spec.CFSSL.Backend == apiv2alpha1.DeviceCABackendOperator {
+		return operatorCABackend{}
+	}
+	return cfsslCABackend{}
+}
+
+// EnsureDeviceCA reconciles the device CA backend selected in the Astarte resource.
+func EnsureDeviceCA(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	return GetDeviceCABackend(cr).Ensure(cr, c, scheme)
+}
+
+// GetDeviceCASecretName returns the name of the TLS Secret holding the devices CA certificate and key.
+func GetDeviceCASecretName(cr *apiv2alpha1.Astarte) string {
+	if cr.Spec.CFSSL.CASecret.Name != "" {
+		return cr.Spec.CFSSL.CASecret.Name
+	}
+	return cr.Name + "-devices-ca"
+}
+
+// GetDeviceCASignerTokenSecretName returns the name of the Secret holding the token an Astarte presents to the
+// Operator's CA signer.
+func GetDeviceCASignerTokenSecretName(cr *apiv2alpha1.Astarte) string {
+	return cr.Name + "-device-ca-signer-token"
+}
+
+// GetDeviceCASignerToken returns the token binding the requests to the Operator's CA signer to the given Astarte.
+// It is derived from the devices CA key, so that it can only be computed by whoever holds the CA, and it stays the
+// same for as long as the CA does.
+func GetDeviceCASignerToken(cr *apiv2alpha1.Astarte, caKeyPEM []byte) string {
+	mac := hmac.New(sha256.New, caKeyPEM)
+	mac.Write([]byte(cr.Namespace + "/" + cr.Name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetDeviceCASigningPolicy returns the signing policy to be used when signing device certificates,
+// honoring the same overrides CFSSL does.
+func GetDeviceCASigningPolicy(cr *apiv2alpha1.Astarte) (*config.Signing, error) {
+	cfsslConfig, err := getCFSSLConfigMapData(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := config.LoadConfig([]byte(cfsslConfig["ca_root_config.json"]))
+	if err != nil {
+		return nil, err
+	}
+	if policy.Signing == nil {
+		return nil, errors.New("no signing policy found in the CA root configuration")
+	}
+
+	return policy.Signing, nil
+}
+
+// cfsslCABackend signs device certificates through an Operator-managed CFSSL Deployment.
+type cfsslCABackend struct{}
+
+func (cfsslCABackend) Ensure(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	return EnsureCFSSL(cr, c, scheme)
+}
+
+func (cfsslCABackend) URL(cr *apiv2alpha1.Astarte) string {
+	return fmt.Sprintf("http://%s-cfssl.%s.svc.cluster.local", cr.Name, cr.Namespace)
+}
+
+func (cfsslCABackend) URLEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
+	return nil
+}
+
+func (cfsslCABackend) IsHealthy(cr *apiv2alpha1.Astarte, c client.Client) bool {
+	if !pointy.BoolValue(cr.Spec.CFSSL.Deploy, true) {
+		return true
+	}
+
+	cfsslDeployment := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name + "-cfssl"}, cfsslDeployment); err != nil {
+		// It might be a temporary error as the Deployment is being created.
+		log.V(1).Info("Could not Get Astarte CFSSL Deployment to compute health.")
+		return false
+	}
+
+	return cfsslDeployment.Status.ReadyReplicas > 0
+}
+
+// operatorCABackend signs device certificates through the CFSSL-compatible API served by the Operator itself.
+// The Operator only takes care of the CA Secret: no workload is deployed in the Astarte namespace.
+type operatorCABackend struct{}
+
+func (operatorCABackend) Ensure(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	if operatorCASignerURL == "" {
+		return errors.New("the operator device CA backend was requested, but the Operator's CA signer is not enabled")
+	}
+
+	// Make sure no CFSSL Deployment is left behind, in case we're switching backend.
+	if err := deleteCFSSLDeploymentIfExists(cr, c); err != nil {
+		return err
+	}
+
+	caSecretName := GetDeviceCASecretName(cr)
+	if cr.Spec.CFSSL.CASecret.Name == "" {
+		if err := ensureCFSSLCASecret(caSecretName, cr, c, scheme); err != nil {
+			return err
+		}
+	}
+
+	// Ensure the proxy secret for TLS authentication, as CFSSL would
+	if err := ensureCFSSLCAProxySecret(caSecretName, cr.Name+"-cfssl-ca", cr, c, scheme); err != nil {
+		return err
+	}
+
+	return ensureDeviceCASignerTokenSecret(caSecretName, cr, c, scheme)
+}
+
+func (operatorCABackend) URL(cr *apiv2alpha1.Astarte) string {
+	// Requests are routed by the signer according to the Astarte they refer to, and authenticated by the token
+	return fmt.Sprintf("%s/%s/%s/$(%s)", operatorCASignerURL, cr.Namespace, cr.Name, deviceCASignerTokenEnvVar)
+}
+
+func (operatorCABackend) URLEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
+	// Keep the token out of the Pod specs
+	return []v1.EnvVar{{
+		Name: deviceCASignerTokenEnvVar,
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: GetDeviceCASignerTokenSecretName(cr)},
+			Key:                  DeviceCASignerTokenKey,
+		}},
+	}}
+}
+
+func (operatorCABackend) IsHealthy(cr *apiv2alpha1.Astarte, c client.Client) bool {
+	// The signer runs within the Operator, so we're healthy as long as the CA is in place.
+	s := &v1.Secret{}
+	return c.Get(context.TODO(), types.NamespacedName{Namespace: cr.Namespace, Name: GetDeviceCASecretName(cr)}, s) == nil
+}
+
+func ensureDeviceCASignerTokenSecret(caSecretName string, cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	caSecret := &v1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: caSecretName, Namespace: cr.Namespace}, caSecret); err != nil {
+		return err
+	}
+	if len(caSecret.Data[v1.TLSPrivateKeyKey]) == 0 {
+		return fmt.Errorf("the devices CA Secret %s has no %s", caSecretName, v1.TLSPrivateKeyKey)
+	}
+
+	token := GetDeviceCASignerToken(cr, caSecret.Data[v1.TLSPrivateKeyKey])
+	_, err := misc.ReconcileSecretString(GetDeviceCASignerTokenSecretName(cr), map[string]string{DeviceCASignerTokenKey: token},
+		cr, c, scheme, log.WithValues("Request.Namespace", cr.Namespace, "Request.Name", cr.Name))
+	return err
+}
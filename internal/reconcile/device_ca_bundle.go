@@ -0,0 +1,229 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cfssl/helpers"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/scope"
+)
+
+const (
+	// DeviceCABundleSourceNameLabel labels mirrored devices CA bundles with the name of their Astarte
+	DeviceCABundleSourceNameLabel = "astarte-devices-ca-bundle-source-name"
+	// DeviceCABundleSourceNamespaceLabel labels mirrored devices CA bundles with the namespace of their Astarte
+	DeviceCABundleSourceNamespaceLabel = "astarte-devices-ca-bundle-source-namespace"
+)
+
+// EnsureDeviceCABundle publishes the devices CA certificate chain and the broker URL into a ConfigMap,
+// mirroring it into the selected namespaces, if requested.
+func EnsureDeviceCABundle(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	configMapName := GetDeviceCABundleConfigMapName(cr)
+
+	if cr.Spec.CFSSL.PublishCABundle == nil || !cr.Spec.CFSSL.PublishCABundle.Enable {
+		// Clean up whatever we might have published before
+		theConfigMap := &v1.ConfigMap{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: cr.Namespace}, theConfigMap); err == nil {
+			if metav1.IsControlledBy(theConfigMap, cr) {
+				if err := c.Delete(context.TODO(), theConfigMap); err != nil {
+					return err
+				}
+			}
+		} else if !kerrors.IsNotFound(err) {
+			return err
+		}
+		return DeleteDeviceCABundleMirrors(cr.Name, cr.Namespace, nil, c)
+	}
+
+	caChain, err := getDeviceCAChain(cr, c)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		"ca.crt":     string(caChain),
+		"broker_url": misc.GetVerneMQBrokerURL(cr),
+	}
+	if _, err := misc.ReconcileConfigMap(configMapName, data, cr, c, scheme, log); err != nil {
+		return err
+	}
+
+	// Now, take care of the mirrors
+	selectedNamespaces := map[string]bool{}
+	if cr.Spec.CFSSL.PublishCABundle.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cr.Spec.CFSSL.PublishCABundle.NamespaceSelector)
+		if err != nil {
+			return err
+		}
+
+		namespaces := &v1.NamespaceList{}
+		if err := c.List(context.TODO(), namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces.Items {
+			// The bundle in the Astarte namespace is already there, and namespaces out of our scope are
+			// not ours to write into
+			if ns.Name == cr.Namespace || ns.DeletionTimestamp != nil || !scope.Get().ContainsNamespace(ns.Name) {
+				continue
+			}
+			if err := reconcileDeviceCABundleMirror(configMapName, ns.Name, data, cr, c); err != nil {
+				return err
+			}
+			selectedNamespaces[ns.Name] = true
+		}
+	}
+
+	return DeleteDeviceCABundleMirrors(cr.Name, cr.Namespace, selectedNamespaces, c)
+}
+
+// DeleteDeviceCABundleMirrors deletes all mirrors of the devices CA bundle of the given Astarte, except
+// for the ones living in the namespaces which should be kept.
+func DeleteDeviceCABundleMirrors(name, namespace string, keep map[string]bool, c client.Client) error {
+	mirrors, err := listDeviceCABundleMirrors(name, namespace, c)
+	if err != nil {
+		return err
+	}
+
+	for _, mirror := range mirrors {
+		if keep[mirror.Namespace] {
+			continue
+		}
+		mirrorCopy := mirror
+		log.Info("Deleting devices CA bundle mirror", "Namespace", mirror.Namespace, "Name", mirror.Name)
+		if err := c.Delete(context.TODO(), &mirrorCopy); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listDeviceCABundleMirrors lists the mirrors of the devices CA bundle of the given Astarte. When the Operator watches
+// some namespaces only, it is not allowed to look anywhere else.
+func listDeviceCABundleMirrors(name, namespace string, c client.Client) ([]v1.ConfigMap, error) {
+	matchingLabels := client.MatchingLabels{
+		DeviceCABundleSourceNameLabel:      name,
+		DeviceCABundleSourceNamespaceLabel: namespace,
+	}
+
+	namespaces := scope.Get().Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	mirrors := []v1.ConfigMap{}
+	for _, ns := range namespaces {
+		list := &v1.ConfigMapList{}
+		if err := c.List(context.TODO(), list, matchingLabels, client.InNamespace(ns)); err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, list.Items...)
+	}
+	return mirrors, nil
+}
+
+// GetDeviceCABundleConfigMapName returns the name of the ConfigMap the devices CA bundle is published into.
+func GetDeviceCABundleConfigMapName(cr *apiv2alpha1.Astarte) string {
+	if cr.Spec.CFSSL.PublishCABundle != nil && cr.Spec.CFSSL.PublishCABundle.ConfigMapName != "" {
+		return cr.Spec.CFSSL.PublishCABundle.ConfigMapName
+	}
+	return cr.Name + "-devices-ca-bundle"
+}
+
+// GetDeviceCAFingerprintAndExpiry returns the SHA-256 fingerprint of the devices CA certificate, in the same
+// format used by openssl, together with its expiry.
+func GetDeviceCAFingerprintAndExpiry(cr *apiv2alpha1.Astarte, c client.Client) (string, time.Time, error) {
+	caSecret := &v1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: GetDeviceCASecretName(cr), Namespace: cr.Namespace}, caSecret); err != nil {
+		return "", time.Time{}, err
+	}
+
+	caCert, err := helpers.ParseCertificatePEM(caSecret.Data[v1.TLSCertKey])
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	sum := sha256.Sum256(caCert.Raw)
+	hexBytes := make([]string, 0, len(sum))
+	for _, b := range sum {
+		hexBytes = append(hexBytes, fmt.Sprintf("%02X", b))
+	}
+
+	return strings.Join(hexBytes, ":"), caCert.NotAfter, nil
+}
+
+func getDeviceCAChain(cr *apiv2alpha1.Astarte, c client.Client) ([]byte, error) {
+	caSecret := &v1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: GetDeviceCASecretName(cr), Namespace: cr.Namespace}, caSecret); err != nil {
+		return nil, err
+	}
+
+	// When the CA is an intermediate, the rest of the chain might be in ca.crt. Never publish the key.
+	chain := bytes.TrimSpace(caSecret.Data[v1.TLSCertKey])
+	if parent := bytes.TrimSpace(caSecret.Data["ca.crt"]); len(parent) > 0 && !bytes.Equal(parent, chain) {
+		chain = append(append(chain, '\n'), parent...)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("the devices CA Secret %s has no certificate", caSecret.Name)
+	}
+
+	return append(chain, '\n'), nil
+}
+
+func reconcileDeviceCABundleMirror(name, namespace string, data map[string]string, cr *apiv2alpha1.Astarte, c client.Client) error {
+	// Owner references can't cross namespaces, so mirrors are tracked through labels instead
+	mirror := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	result, err := controllerutil.CreateOrUpdate(context.TODO(), c, mirror, func() error {
+		// Don't hijack ConfigMaps we don't own
+		if mirror.ResourceVersion != "" && (mirror.Labels[DeviceCABundleSourceNameLabel] != cr.Name ||
+			mirror.Labels[DeviceCABundleSourceNamespaceLabel] != cr.Namespace) {
+			return fmt.Errorf("ConfigMap %s/%s already exists and is not managed by Astarte %s/%s", namespace, name, cr.Namespace, cr.Name)
+		}
+		if mirror.Labels == nil {
+			mirror.Labels = map[string]string{}
+		}
+		mirror.Labels[DeviceCABundleSourceNameLabel] = cr.Name
+		mirror.Labels[DeviceCABundleSourceNamespaceLabel] = cr.Namespace
+		mirror.Data = data
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.LogCreateOrUpdateOperationResult(log, result, cr, mirror)
+	return nil
+}
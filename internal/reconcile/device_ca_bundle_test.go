@@ -0,0 +1,136 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"strings"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/scope"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Device CA bundle testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "device-ca-bundle-test"
+		MirrorNamespace        = "device-ca-bundle-mirror"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		mirrorNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   MirrorNamespace,
+			Labels: map[string]string{"astarte-ca": "true"},
+		}}
+		Expect(k8sClient.Create(context.Background(), mirrorNamespace)).To(Succeed())
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, MirrorNamespace)
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test EnsureDeviceCABundle", func() {
+		It("should publish the bundle and mirror it into the selected namespaces", func() {
+			cr.Spec.CFSSL.PublishCABundle = &apiv2alpha1.AstarteDeviceCABundleSpec{
+				Enable:            true,
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"astarte-ca": "true"}},
+			}
+			Expect(k8sClient.Update(context.Background(), cr)).To(Succeed())
+
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(EnsureDeviceCABundle(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			bundleName := CustomAstarteName + "-devices-ca-bundle"
+			for _, ns := range []string{CustomAstarteNamespace, MirrorNamespace} {
+				bundle := &v1.ConfigMap{}
+				Eventually(func() error {
+					return k8sClient.Get(context.Background(), types.NamespacedName{Name: bundleName, Namespace: ns}, bundle)
+				}, Timeout, Interval).Should(Succeed())
+				Expect(bundle.Data["ca.crt"]).To(HavePrefix("-----BEGIN CERTIFICATE-----"))
+				Expect(bundle.Data["ca.crt"]).ToNot(ContainSubstring("PRIVATE KEY"))
+				Expect(bundle.Data["broker_url"]).To(Equal("mqtts://broker.astarte-example.com:8883"))
+			}
+
+			// Disabling publishing removes the mirrors as well
+			cr.Spec.CFSSL.PublishCABundle.Enable = false
+			Expect(EnsureDeviceCABundle(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: bundleName, Namespace: MirrorNamespace}, &v1.ConfigMap{})
+			}, Timeout, Interval).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("Test EnsureDeviceCABundle with a restricted scope", func() {
+		AfterEach(func() {
+			scope.Set(scope.OperatorScope{})
+		})
+
+		It("should not mirror the bundle out of the watched namespaces", func() {
+			scope.Set(scope.OperatorScope{Namespaces: []string{CustomAstarteNamespace}})
+			cr.Spec.CFSSL.PublishCABundle = &apiv2alpha1.AstarteDeviceCABundleSpec{
+				Enable:            true,
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"astarte-ca": "true"}},
+			}
+
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(EnsureDeviceCABundle(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			bundleName := CustomAstarteName + "-devices-ca-bundle"
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: bundleName, Namespace: CustomAstarteNamespace}, &v1.ConfigMap{})).To(Succeed())
+			Consistently(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: bundleName, Namespace: MirrorNamespace}, &v1.ConfigMap{})
+			}, "2s", Interval).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("Test GetDeviceCAFingerprintAndExpiry", func() {
+		It("should return the fingerprint and expiry of the devices CA", func() {
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			fingerprint, expiry, err := GetDeviceCAFingerprintAndExpiry(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.Split(fingerprint, ":")).To(HaveLen(32))
+			Expect(expiry.IsZero()).To(BeFalse())
+		})
+	})
+})
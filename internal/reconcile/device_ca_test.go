@@ -0,0 +1,137 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Device CA backend testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "device-ca-test"
+		CASignerURL            = "http://ca-signer.astarte-operator.svc.cluster.local:8090"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		SetOperatorCASignerURL("")
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test getCFSSLURL", func() {
+		It("should point to the CFSSL service by default", func() {
+			Expect(getCFSSLURL(cr)).To(Equal("http://example-astarte-cfssl.device-ca-test.svc.cluster.local"))
+		})
+
+		It("should point to the Operator's signer when using the operator backend", func() {
+			SetOperatorCASignerURL(CASignerURL + "/")
+			cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendOperator
+			Expect(getCFSSLURL(cr)).To(Equal(CASignerURL + "/device-ca-test/example-astarte/$(DEVICE_CA_SIGNER_TOKEN)"))
+
+			envVars := getCFSSLURLEnvVars("PAIRING_CFSSL_URL", cr)
+			Expect(envVars).To(HaveLen(2))
+			Expect(envVars[0].Name).To(Equal("DEVICE_CA_SIGNER_TOKEN"))
+			Expect(envVars[0].ValueFrom.SecretKeyRef.Name).To(Equal(CustomAstarteName + "-device-ca-signer-token"))
+			Expect(envVars[1].Name).To(Equal("PAIRING_CFSSL_URL"))
+		})
+
+		It("should always honor a custom URL", func() {
+			cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendOperator
+			cr.Spec.CFSSL.URL = "http://my-cfssl.com"
+			Expect(getCFSSLURL(cr)).To(Equal("http://my-cfssl.com"))
+			Expect(getCFSSLURLEnvVars("PAIRING_CFSSL_URL", cr)).To(ConsistOf(v1.EnvVar{Name: "PAIRING_CFSSL_URL", Value: "http://my-cfssl.com"}))
+		})
+	})
+
+	Describe("Test EnsureDeviceCA with the operator backend", func() {
+		It("should fail when the Operator's signer is not enabled", func() {
+			SetOperatorCASignerURL("")
+			cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendOperator
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).ToNot(Succeed())
+		})
+
+		It("should create the CA secrets and remove the CFSSL Deployment", func() {
+			SetOperatorCASignerURL(CASignerURL)
+
+			// Start from CFSSL
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: CustomAstarteName + "-cfssl", Namespace: CustomAstarteNamespace}, &appsv1.Deployment{})
+			}, Timeout, Interval).Should(Succeed())
+
+			// Then switch backend
+			cr.Spec.CFSSL.Backend = apiv2alpha1.DeviceCABackendOperator
+			Expect(k8sClient.Update(context.Background(), cr)).To(Succeed())
+			Expect(EnsureDeviceCA(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: CustomAstarteName + "-cfssl", Namespace: CustomAstarteNamespace}, &appsv1.Deployment{})
+			}, Timeout, Interval).ShouldNot(Succeed())
+
+			for _, secretName := range []string{CustomAstarteName + "-devices-ca", CustomAstarteName + "-cfssl-ca"} {
+				Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: secretName, Namespace: CustomAstarteNamespace}, &v1.Secret{})).To(Succeed())
+			}
+			Expect(GetDeviceCABackend(cr).IsHealthy(cr, k8sClient)).To(BeTrue())
+
+			// The token is derived from the CA key
+			caSecret := &v1.Secret{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: CustomAstarteName + "-devices-ca", Namespace: CustomAstarteNamespace}, caSecret)).To(Succeed())
+			tokenSecret := &v1.Secret{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetDeviceCASignerTokenSecretName(cr), Namespace: CustomAstarteNamespace}, tokenSecret)).To(Succeed())
+			Expect(string(tokenSecret.Data[DeviceCASignerTokenKey])).To(Equal(GetDeviceCASignerToken(cr, caSecret.Data[v1.TLSPrivateKeyKey])))
+		})
+	})
+
+	Describe("Test GetDeviceCASigningPolicy", func() {
+		It("should honor the certificate expiry override", func() {
+			cr.Spec.CFSSL.CertificateExpiry = "24h"
+			policy, err := GetDeviceCASigningPolicy(cr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy.Default.Expiry.String()).To(Equal("24h0m0s"))
+		})
+	})
+})
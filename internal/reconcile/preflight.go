@@ -0,0 +1,234 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.openly.dev/pointy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/cql"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/rabbitmq"
+)
+
+// The reasons of the preflight conditions
+const (
+	// PreflightReasonReachable means the service accepted a connection
+	PreflightReasonReachable = "Reachable"
+	// PreflightReasonAuthenticated means the service accepted a connection and the configured credentials
+	PreflightReasonAuthenticated = "Authenticated"
+	// PreflightReasonUnreachable means the service could not be connected to
+	PreflightReasonUnreachable = "Unreachable"
+	// PreflightReasonAuthenticationFailed means the service refused the configured credentials
+	PreflightReasonAuthenticationFailed = "AuthenticationFailed"
+	// PreflightReasonInvalidConfiguration means the check could not even start, e.g. due to a missing Secret
+	PreflightReasonInvalidConfiguration = "InvalidConfiguration"
+)
+
+const defaultCassandraPort int32 = 9042
+
+// preflightError carries the reason a check failed with
+type preflightError struct {
+	reason string
+	err    error
+}
+
+func (e *preflightError) Error() string {
+	return e.err.Error()
+}
+
+// RunBackingServicesPreflight checks whether RabbitMQ and every Cassandra node are reachable, over TLS when
+// Astarte is configured so, and logs in with the configured credentials if requested. It returns the
+// RabbitMQReachable and CassandraReachable conditions, leaving it to the caller to store them.
+func RunBackingServicesPreflight(cr *apiv2alpha1.Astarte, c client.Client) []metav1.Condition {
+	return []metav1.Condition{
+		preflightCondition(apiv2alpha1.AstarteConditionRabbitMQReachable, checkRabbitMQ(cr, c), cr.Spec.Preflight.ShouldAuthenticate()),
+		preflightCondition(apiv2alpha1.AstarteConditionCassandraReachable, checkCassandra(cr, c), cr.Spec.Preflight.ShouldAuthenticate()),
+	}
+}
+
+func preflightCondition(conditionType string, err error, authenticated bool) metav1.Condition {
+	condition := metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: PreflightReasonReachable}
+	switch {
+	case err == nil && authenticated:
+		condition.Reason = PreflightReasonAuthenticated
+		condition.Message = "Connected and logged in"
+	case err == nil:
+		condition.Message = "Connected"
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = PreflightReasonUnreachable
+		var pErr *preflightError
+		if errors.As(err, &pErr) {
+			condition.Reason = pErr.reason
+		}
+		condition.Message = err.Error()
+	}
+	return condition
+}
+
+func checkRabbitMQ(cr *apiv2alpha1.Astarte, c client.Client) error {
+	connection := cr.Spec.RabbitMQ.Connection
+	if connection == nil {
+		return &preflightError{PreflightReasonInvalidConfiguration, errors.New("no RabbitMQ connection is configured")}
+	}
+	host, port := misc.GetRabbitMQHostnameAndPort(cr)
+
+	var username, password string
+	if cr.Spec.Preflight.ShouldAuthenticate() {
+		var err error
+		if _, _, username, password, err = misc.GetRabbitMQCredentialsFor(cr, c); err != nil {
+			return &preflightError{PreflightReasonInvalidConfiguration, fmt.Errorf("could not get the RabbitMQ credentials: %w", err)}
+		}
+	}
+
+	conn, err := dialBackingService(host, port, connection.SSLConfiguration, cr.Spec.Preflight.GetTimeout(), cr, c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if !cr.Spec.Preflight.ShouldAuthenticate() {
+		return nil
+	}
+	if err := rabbitmq.CheckAMQPLogin(conn, misc.GetRabbitMQVirtualHost(cr), username, password); err != nil {
+		if errors.Is(err, rabbitmq.ErrAccessRefused) {
+			return &preflightError{PreflightReasonAuthenticationFailed, fmt.Errorf("could not log in to RabbitMQ at %s: %w", joinHostPort(host, port), err)}
+		}
+		return fmt.Errorf("could not log in to RabbitMQ at %s: %w", joinHostPort(host, port), err)
+	}
+	return nil
+}
+
+// checkCassandra checks all Cassandra nodes concurrently, failing if any of them fails
+func checkCassandra(cr *apiv2alpha1.Astarte, c client.Client) error {
+	connection := cr.Spec.Cassandra.Connection
+	if connection == nil || len(connection.Nodes) == 0 {
+		return &preflightError{PreflightReasonInvalidConfiguration, errors.New("no Cassandra nodes are configured")}
+	}
+
+	var username, password string
+	if cr.Spec.Preflight.ShouldAuthenticate() {
+		var err error
+		if username, password, err = misc.GetCassandraCredentialsFor(cr, c); err != nil {
+			return &preflightError{PreflightReasonInvalidConfiguration, fmt.Errorf("could not get the Cassandra credentials: %w", err)}
+		}
+	}
+
+	errs := make([]error, len(connection.Nodes))
+	var wg sync.WaitGroup
+	for i, node := range connection.Nodes {
+		wg.Add(1)
+		go func(i int, node apiv2alpha1.HostAndPort) {
+			defer wg.Done()
+			errs[i] = checkCassandraNode(node, username, password, cr, c)
+		}(i, node)
+	}
+	wg.Wait()
+
+	// Unreachable nodes are the most pressing matter, report them first
+	reason := ""
+	failures := []string{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		failures = append(failures, err.Error())
+		var pErr *preflightError
+		if !errors.As(err, &pErr) {
+			reason = PreflightReasonUnreachable
+		} else if reason != PreflightReasonUnreachable {
+			reason = pErr.reason
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &preflightError{reason, fmt.Errorf("%d out of %d Cassandra nodes failed: %s",
+		len(failures), len(connection.Nodes), strings.Join(failures, "; "))}
+}
+
+func checkCassandraNode(node apiv2alpha1.HostAndPort, username, password string, cr *apiv2alpha1.Astarte, c client.Client) error {
+	port := pointy.Int32Value(node.Port, defaultCassandraPort)
+	conn, err := dialBackingService(node.Host, port, cr.Spec.Cassandra.Connection.SSLConfiguration, cr.Spec.Preflight.GetTimeout(), cr, c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if !cr.Spec.Preflight.ShouldAuthenticate() {
+		return nil
+	}
+	if err := cql.NewConn(conn).Startup(username, password); err != nil {
+		if errors.Is(err, cql.ErrAuthenticationFailed) {
+			return &preflightError{PreflightReasonAuthenticationFailed, fmt.Errorf("could not log in to %s: %w", joinHostPort(node.Host, port), err)}
+		}
+		return fmt.Errorf("could not log in to %s: %w", joinHostPort(node.Host, port), err)
+	}
+	return nil
+}
+
+// dialBackingService connects to host:port, completing the TLS handshake when SSL is enabled. The returned
+// connection has its deadline set according to timeout.
+func dialBackingService(host string, port int32, ssl apiv2alpha1.GenericSSLConfigurationSpec, timeout time.Duration,
+	cr *apiv2alpha1.Astarte, c client.Client) (net.Conn, error) {
+	address := joinHostPort(host, port)
+
+	var tlsConfig *tls.Config
+	if ssl.Enable {
+		var err error
+		if tlsConfig, err = misc.GetTLSConfigFor(ssl, host, cr.Namespace, c); err != nil {
+			return nil, &preflightError{PreflightReasonInvalidConfiguration, fmt.Errorf("could not set up TLS for %s: %w", address, err)}
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).Dial("tcp", address)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %w", address, err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func joinHostPort(host string, port int32) string {
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
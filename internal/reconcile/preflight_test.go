@@ -0,0 +1,208 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Backing services preflight testing", Ordered, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "preflight-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.Preflight = &apiv2alpha1.AstartePreflightSpec{TimeoutSeconds: pointy.Int32(2)}
+		integrationutils.DeployAstarte(k8sClient, cr)
+
+		Expect(k8sClient.Create(context.Background(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "scylladb-connection-secret", Namespace: CustomAstarteNamespace},
+			Data:       map[string][]byte{"username": []byte("astarte"), "password": []byte("wrong")},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	// listen starts a local listener handing its connections to handle, and returns its address
+	listen := func(handle func(net.Conn)) apiv2alpha1.HostAndPort {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(listener.Close)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					handle(conn)
+				}()
+			}
+		}()
+		return hostAndPortOf(listener.Addr().String())
+	}
+
+	// closedPort returns the address of a port nobody listens on
+	closedPort := func() apiv2alpha1.HostAndPort {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		address := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+		return hostAndPortOf(address)
+	}
+
+	// refuseCassandraLogin answers as a Cassandra node refusing any credentials
+	refuseCassandraLogin := func(conn net.Conn) {
+		const opError, opAuthenticate = 0x00, 0x03
+		for _, opcode := range []byte{opAuthenticate, opError} {
+			header := make([]byte, 9)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			if _, err := io.ReadFull(conn, make([]byte, binary.BigEndian.Uint32(header[5:9]))); err != nil {
+				return
+			}
+			body := []byte{0, 0, 0, 0, 0, 0}
+			if opcode == opError {
+				binary.BigEndian.PutUint32(body, 0x0100)
+			}
+			response := append([]byte{0x84, 0, header[2], header[3], opcode, 0, 0, 0, byte(len(body))}, body...)
+			_, _ = conn.Write(response)
+		}
+	}
+
+	setEndpoints := func(rabbitMQ apiv2alpha1.HostAndPort, cassandra ...apiv2alpha1.HostAndPort) {
+		cr.Spec.RabbitMQ.Connection.Host = rabbitMQ.Host
+		cr.Spec.RabbitMQ.Connection.Port = rabbitMQ.Port
+		cr.Spec.Cassandra.Connection.Nodes = cassandra
+	}
+
+	Describe("Test RunBackingServicesPreflight", func() {
+		It("should report reachable services", func() {
+			setEndpoints(listen(func(net.Conn) {}), listen(func(net.Conn) {}), listen(func(net.Conn) {}))
+
+			conditions := RunBackingServicesPreflight(cr, k8sClient)
+			Expect(conditions).To(HaveLen(2))
+			for _, condition := range conditions {
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal(PreflightReasonReachable))
+			}
+		})
+
+		It("should report the unreachable Cassandra nodes", func() {
+			unreachable := closedPort()
+			setEndpoints(listen(func(net.Conn) {}), listen(func(net.Conn) {}), unreachable)
+
+			conditions := RunBackingServicesPreflight(cr, k8sClient)
+			Expect(meta.IsStatusConditionTrue(conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)).To(BeTrue())
+			condition := meta.FindStatusCondition(conditions, apiv2alpha1.AstarteConditionCassandraReachable)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(PreflightReasonUnreachable))
+			Expect(condition.Message).To(ContainSubstring("1 out of 2"))
+			Expect(condition.Message).To(ContainSubstring(net.JoinHostPort(unreachable.Host, strconv.Itoa(int(*unreachable.Port)))))
+		})
+
+		It("should report refused credentials when authenticating", func() {
+			cr.Spec.Preflight.Authenticate = true
+			setEndpoints(listen(func(net.Conn) {}), listen(refuseCassandraLogin))
+
+			conditions := RunBackingServicesPreflight(cr, k8sClient)
+			condition := meta.FindStatusCondition(conditions, apiv2alpha1.AstarteConditionCassandraReachable)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(PreflightReasonAuthenticationFailed))
+			// The RabbitMQ credentials Secret does not exist
+			condition = meta.FindStatusCondition(conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(PreflightReasonInvalidConfiguration))
+		})
+
+		It("should complete the TLS handshake against the custom CA", func() {
+			server := httptest.NewTLSServer(http.NotFoundHandler())
+			DeferCleanup(server.Close)
+			Expect(k8sClient.Create(context.Background(), &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-ca", Namespace: CustomAstarteNamespace},
+				Data: map[string][]byte{
+					"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+				},
+			})).To(Succeed())
+			setEndpoints(hostAndPortOf(server.Listener.Addr().String()), listen(func(net.Conn) {}))
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.Enable = true
+
+			// The server certificate can't be verified without the CA
+			conditions := RunBackingServicesPreflight(cr, k8sClient)
+			Expect(meta.IsStatusConditionFalse(conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)).To(BeTrue())
+
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name = "rabbitmq-ca"
+			conditions = RunBackingServicesPreflight(cr, k8sClient)
+			Expect(meta.IsStatusConditionTrue(conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)).To(BeTrue())
+		})
+
+		It("should report a missing CA Secret as an invalid configuration", func() {
+			setEndpoints(listen(func(net.Conn) {}), listen(func(net.Conn) {}))
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.Enable = true
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name = "missing"
+
+			conditions := RunBackingServicesPreflight(cr, k8sClient)
+			condition := meta.FindStatusCondition(conditions, apiv2alpha1.AstarteConditionRabbitMQReachable)
+			Expect(condition.Reason).To(Equal(PreflightReasonInvalidConfiguration))
+		})
+	})
+})
+
+func hostAndPortOf(address string) apiv2alpha1.HostAndPort {
+	host, port, err := net.SplitHostPort(address)
+	Expect(err).ToNot(HaveOccurred())
+	p, err := strconv.Atoi(port)
+	Expect(err).ToNot(HaveOccurred())
+	return apiv2alpha1.HostAndPort{Host: host, Port: pointy.Int32(int32(p))}
+}
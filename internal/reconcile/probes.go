@@ -21,7 +21,6 @@ package reconcile
 import (
 	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ----- Astarte Component Probes -----
@@ -41,7 +40,7 @@ func getAstarteComponentReadinessProbe(component apiv2alpha1.AstarteComponent, r
 		return res.ReadinessProbe
 	}
 
-	return getAstarteDefaultProbe(component)
+	return apiv2alpha1.DefaultAstarteComponentProbe(component)
 }
 
 // getAstarteBackendLivenessProbe returns the custom liveness probe if set, the default liveness probe otherwise
@@ -50,56 +49,16 @@ func getAstarteComponentLivenessProbe(component apiv2alpha1.AstarteComponent, re
 		return res.LivenessProbe
 	}
 
-	return getAstarteDefaultProbe(component)
-}
-
-// getDefaultProbeWithThreshold returns a default probe for Astarte components with a custom failure threshold
-func getDefaultProbeWithThreshold(path string, threshold int32) *v1.Probe {
-	return &v1.Probe{
-		ProbeHandler: v1.ProbeHandler{
-			HTTPGet: &v1.HTTPGetAction{
-				Path: path,
-				Port: intstr.FromString("http"),
-			},
-		},
-		InitialDelaySeconds: 10,
-		TimeoutSeconds:      5,
-		PeriodSeconds:       30,
-		FailureThreshold:    threshold,
-	}
-}
-
-// getAstarteDefaultProbe returns the default probe for Astarte components
-func getAstarteDefaultProbe(component apiv2alpha1.AstarteComponent) *v1.Probe {
-	// Custom components
-	if component == apiv2alpha1.Housekeeping {
-		// We need a much longer timeout, as we have an initialization which happens 3 times
-		return getDefaultProbeWithThreshold("/health", 15)
-	}
-
-	// The rest are generic probes on /health
-	return getDefaultProbeWithThreshold("/health", 5)
+	return apiv2alpha1.DefaultAstarteComponentProbe(component)
 }
 
 // ----- CFSSL Probes -----
-// getCFSSLDefaultProbe returns the default probe for CFSSL
-func getCFSSLDefaultProbe() *v1.Probe {
-	// Start checking after 10 seconds, every 20 seconds, fail after the 3rd attempt
-	return &v1.Probe{
-		ProbeHandler:        v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/api/v1/cfssl/health", Port: intstr.FromString("http")}},
-		InitialDelaySeconds: 10,
-		TimeoutSeconds:      5,
-		PeriodSeconds:       20,
-		FailureThreshold:    3,
-	}
-}
-
 // getCFSSLReadinessProbe returns the readiness probe for CFSSL
 func getCFSSLReadinessProbe(cr *apiv2alpha1.Astarte) *v1.Probe {
 	if cr.Spec.CFSSL.ReadinessProbe != nil {
 		return cr.Spec.CFSSL.ReadinessProbe
 	}
-	return getCFSSLDefaultProbe()
+	return apiv2alpha1.DefaultCFSSLProbe()
 }
 
 // getCFSSLLivenessProbe returns the liveness probe for CFSSL
@@ -107,7 +66,7 @@ func getCFSSLLivenessProbe(cr *apiv2alpha1.Astarte) *v1.Probe {
 	if cr.Spec.CFSSL.LivenessProbe != nil {
 		return cr.Spec.CFSSL.LivenessProbe
 	}
-	return getCFSSLDefaultProbe()
+	return apiv2alpha1.DefaultCFSSLProbe()
 }
 
 // getCFSSLStartupProbe returns the startup probe for CFSSL
@@ -120,24 +79,12 @@ func getCFSSLStartupProbe(cr *apiv2alpha1.Astarte) *v1.Probe {
 }
 
 // ----- VerneMQ Probes -----
-// getVerneMQDefaultProbe returns the default probe for VerneMQ
-func getVerneMQDefaultProbe() *v1.Probe {
-	// Start checking after 1 minute, every 20 seconds, fail after the 3rd attempt
-	return &v1.Probe{
-		ProbeHandler:        v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/metrics", Port: intstr.FromInt(8888)}},
-		InitialDelaySeconds: 60,
-		TimeoutSeconds:      10,
-		PeriodSeconds:       20,
-		FailureThreshold:    3,
-	}
-}
-
 // getVerneMQReadinessProbe returns the readiness probe for VerneMQ
 func getVerneMQReadinessProbe(cr *apiv2alpha1.Astarte) *v1.Probe {
 	if cr.Spec.VerneMQ.ReadinessProbe != nil {
 		return cr.Spec.VerneMQ.ReadinessProbe
 	}
-	return getVerneMQDefaultProbe()
+	return apiv2alpha1.DefaultVerneMQProbe()
 }
 
 // getVerneMQLivenessProbe returns the liveness probe for VerneMQ
@@ -145,7 +92,7 @@ func getVerneMQLivenessProbe(cr *apiv2alpha1.Astarte) *v1.Probe {
 	if cr.Spec.VerneMQ.LivenessProbe != nil {
 		return cr.Spec.VerneMQ.LivenessProbe
 	}
-	return getVerneMQDefaultProbe()
+	return apiv2alpha1.DefaultVerneMQProbe()
 }
 
 // getVerneMQStartupProbe returns the startup probe for VerneMQ
@@ -0,0 +1,188 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/rabbitmq"
+)
+
+// The reasons of the RabbitMQProvisioned condition
+const (
+	// RabbitMQProvisioningReasonProvisioned means RabbitMQ is provisioned as Astarte needs
+	RabbitMQProvisioningReasonProvisioned = "Provisioned"
+	// RabbitMQProvisioningReasonDriftCorrected means RabbitMQ was provisioned, correcting some drift
+	RabbitMQProvisioningReasonDriftCorrected = "DriftCorrected"
+	// RabbitMQProvisioningReasonFailed means RabbitMQ could not be provisioned
+	RabbitMQProvisioningReasonFailed = "ProvisioningFailed"
+)
+
+const defaultDataQueuesPolicyName = "astarte-data-queues"
+
+// Astarte declares its own exchanges and queues, hence its user needs full access to the virtual host
+var astarteRabbitMQPermissions = rabbitmq.Permissions{Configure: ".*", Write: ".*", Read: ".*"}
+
+// EnsureRabbitMQProvisioning provisions the virtual host, the Astarte user, its permissions and the data queues
+// policy through the RabbitMQ management API, if requested. Whatever exists already but differs from what
+// Astarte is configured with is corrected, and reported in the returned drifts. Nothing is ever deleted.
+func EnsureRabbitMQProvisioning(cr *apiv2alpha1.Astarte, c client.Client) ([]string, error) {
+	if cr.Spec.RabbitMQ.Provisioning == nil {
+		return nil, nil
+	}
+	reqLogger := log.WithValues("Request.Namespace", cr.Namespace, "Request.Name", cr.Name)
+
+	m, err := rabbitmq.NewProvisioningClientFor(cr, c)
+	if err != nil {
+		return nil, err
+	}
+	_, _, username, password, err := misc.GetRabbitMQCredentialsFor(cr, c)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" {
+		return nil, errors.New("the Astarte RabbitMQ user cannot be provisioned, as it has no credentials")
+	}
+
+	ctx := context.TODO()
+	vhost := misc.GetRabbitMQVirtualHost(cr)
+	drifts := []string{}
+
+	if err := m.GetVirtualHost(ctx, vhost); errors.Is(err, rabbitmq.ErrNotFound) {
+		reqLogger.Info("Creating RabbitMQ virtual host", "VirtualHost", vhost)
+		if err := m.PutVirtualHost(ctx, vhost); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	user, err := m.GetUser(ctx, username)
+	switch {
+	case errors.Is(err, rabbitmq.ErrNotFound):
+		reqLogger.Info("Creating RabbitMQ user", "User", username)
+		if err := m.PutUser(ctx, username, password, nil); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !user.HasPassword(password):
+		drifts = append(drifts, fmt.Sprintf("the password of user %s", username))
+		if err := m.PutUser(ctx, username, password, user.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	permissions, err := m.GetPermissions(ctx, vhost, username)
+	if err != nil && !errors.Is(err, rabbitmq.ErrNotFound) {
+		return nil, err
+	}
+	if permissions == nil || *permissions != astarteRabbitMQPermissions {
+		if permissions != nil {
+			drifts = append(drifts, fmt.Sprintf("the permissions of user %s on virtual host %s (configure %q, write %q, read %q)",
+				username, vhost, permissions.Configure, permissions.Write, permissions.Read))
+		}
+		if err := m.PutPermissions(ctx, vhost, username, astarteRabbitMQPermissions); err != nil {
+			return nil, err
+		}
+	}
+
+	if cr.Spec.RabbitMQ.Provisioning.DataQueuesPolicy != nil {
+		name, desired := getDataQueuesPolicy(cr)
+		policy, err := m.GetPolicy(ctx, vhost, name)
+		if err != nil && !errors.Is(err, rabbitmq.ErrNotFound) {
+			return nil, err
+		}
+		if policy == nil || !policyMatches(policy, desired) {
+			if policy != nil {
+				drifts = append(drifts, fmt.Sprintf("the policy %s on virtual host %s", name, vhost))
+			}
+			if err := m.PutPolicy(ctx, vhost, name, desired); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(drifts) > 0 {
+		reqLogger.Info("Corrected RabbitMQ drift", "Drifts", strings.Join(drifts, "; "))
+	}
+	return drifts, nil
+}
+
+// getDataQueuesPolicy returns the name and the content of the policy applying to the data queues, and to
+// the VerneMQ mirror queue if any
+func getDataQueuesPolicy(cr *apiv2alpha1.Astarte) (string, rabbitmq.Policy) {
+	spec := cr.Spec.RabbitMQ.Provisioning.DataQueuesPolicy
+	name := spec.Name
+	if name == "" {
+		name = defaultDataQueuesPolicyName
+	}
+
+	prefix := cr.Spec.RabbitMQ.DataQueuesPrefix
+	if prefix == "" {
+		prefix = defaultDataQueuesPrefix
+	}
+	queues := []string{regexp.QuoteMeta(prefix) + `\d+`}
+	if mirrorQueue := getMirrorQueue(cr); mirrorQueue != "" {
+		queues = append(queues, regexp.QuoteMeta(mirrorQueue))
+	}
+
+	definition := map[string]interface{}{"ha-mode": spec.HAMode}
+	if spec.HAParams != nil {
+		definition["ha-params"] = *spec.HAParams
+	}
+	if spec.HASyncMode != "" {
+		definition["ha-sync-mode"] = spec.HASyncMode
+	}
+
+	return name, rabbitmq.Policy{
+		Pattern:    "^(" + strings.Join(queues, "|") + ")$",
+		ApplyTo:    "queues",
+		Priority:   spec.Priority,
+		Definition: definition,
+	}
+}
+
+// policyMatches compares the policy RabbitMQ reports with the desired one
+func policyMatches(actual *rabbitmq.Policy, desired rabbitmq.Policy) bool {
+	if actual.Pattern != desired.Pattern || actual.ApplyTo != desired.ApplyTo || actual.Priority != desired.Priority {
+		return false
+	}
+
+	// Compare the definitions as RabbitMQ reports them, i.e. as decoded JSON
+	encoded, err := json.Marshal(desired.Definition)
+	if err != nil {
+		return false
+	}
+	definition := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &definition); err != nil {
+		return false
+	}
+	return equality.Semantic.DeepEqual(actual.Definition, definition)
+}
@@ -0,0 +1,257 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	"github.com/astarte-platform/astarte-kubernetes-operator/internal/rabbitmq"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.openly.dev/pointy"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeManagementAPI is a local stand-in for the bits of the RabbitMQ management API used for provisioning
+type fakeManagementAPI struct {
+	mu          sync.Mutex
+	vhosts      map[string]bool
+	users       map[string]rabbitmq.User
+	permissions map[string]rabbitmq.Permissions
+	policies    map[string]rabbitmq.Policy
+	writes      int
+}
+
+func newFakeManagementAPI() *fakeManagementAPI {
+	return &fakeManagementAPI{
+		vhosts:      map[string]bool{"/": true},
+		users:       map[string]rabbitmq.User{},
+		permissions: map[string]rabbitmq.Permissions{},
+		policies:    map[string]rabbitmq.Policy{},
+	}
+}
+
+func hashRabbitMQPassword(password string) string {
+	salt := []byte{0xCA, 0xFE, 0xBA, 0xBE}
+	sum := sha256.Sum256(append(salt, []byte(password)...))
+	return base64.StdEncoding.EncodeToString(append(salt, sum[:]...))
+}
+
+func (f *fakeManagementAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "admin-pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/"), "/")
+	for i := range segments {
+		segments[i], _ = url.PathUnescape(segments[i])
+	}
+	key := strings.Join(segments[1:], "/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found bool
+	var current interface{}
+	switch segments[0] {
+	case "vhosts":
+		found, current = f.vhosts[key], map[string]string{"name": key}
+	case "users":
+		current, found = f.users[key]
+	case "permissions":
+		current, found = f.permissions[key]
+	case "policies":
+		current, found = f.policies[key]
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(current)
+		return
+	}
+
+	f.writes++
+	switch segments[0] {
+	case "vhosts":
+		f.vhosts[key] = true
+	case "users":
+		body := struct {
+			Password string          `json:"password"`
+			Tags     json.RawMessage `json:"tags"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.users[key] = rabbitmq.User{Name: key, PasswordHash: hashRabbitMQPassword(body.Password),
+			HashingAlgorithm: "rabbit_password_hashing_sha256", Tags: body.Tags}
+	case "permissions":
+		permissions := rabbitmq.Permissions{}
+		_ = json.NewDecoder(r.Body).Decode(&permissions)
+		f.permissions[key] = permissions
+	case "policies":
+		policy := rabbitmq.Policy{}
+		_ = json.NewDecoder(r.Body).Decode(&policy)
+		f.policies[key] = policy
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var _ = Describe("RabbitMQ provisioning testing", Ordered, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "rabbitmq-provisioning-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+	var api *fakeManagementAPI
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		api = newFakeManagementAPI()
+		server := httptest.NewServer(api)
+		DeferCleanup(server.Close)
+
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.RabbitMQ.Connection.VirtualHost = "astarte"
+		cr.Spec.VerneMQ.MirrorQueue = "mirror.queue"
+		cr.Spec.RabbitMQ.Provisioning = &apiv2alpha1.AstarteRabbitMQProvisioningSpec{
+			AdminCredentialsSecret: &apiv2alpha1.LoginCredentialsSecret{Name: "rabbitmq-admin", UsernameKey: "username", PasswordKey: "password"},
+			ManagementURL:          server.URL,
+			DataQueuesPolicy:       &apiv2alpha1.AstarteRabbitMQQueuePolicySpec{HAMode: "exactly", HAParams: pointy.Int32(2)},
+		}
+		integrationutils.DeployAstarte(k8sClient, cr)
+
+		Expect(k8sClient.Create(context.Background(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-connection-secret", Namespace: CustomAstarteNamespace},
+			Data:       map[string][]byte{"username": []byte("astarte"), "password": []byte("s3cr3t")},
+		})).To(Succeed())
+		Expect(k8sClient.Create(context.Background(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-admin", Namespace: CustomAstarteNamespace},
+			Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("admin-pass")},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test EnsureRabbitMQProvisioning", func() {
+		It("should do nothing unless requested", func() {
+			cr.Spec.RabbitMQ.Provisioning = nil
+			drifts, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+			Expect(api.writes).To(BeZero())
+		})
+
+		It("should provision the virtual host, the user, its permissions and the data queues policy", func() {
+			drifts, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+
+			Expect(api.vhosts).To(HaveKey("astarte"))
+			user := api.users["astarte"]
+			Expect(user.HasPassword("s3cr3t")).To(BeTrue())
+			Expect(api.permissions).To(HaveKeyWithValue("astarte/astarte", rabbitmq.Permissions{Configure: ".*", Write: ".*", Read: ".*"}))
+			Expect(api.policies).To(HaveKeyWithValue("astarte/astarte-data-queues", rabbitmq.Policy{
+				Pattern:    `^(astarte_data_\d+|mirror\.queue)$`,
+				ApplyTo:    "queues",
+				Definition: map[string]interface{}{"ha-mode": "exactly", "ha-params": float64(2)},
+			}))
+		})
+
+		It("should be idempotent", func() {
+			_, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			writes := api.writes
+
+			drifts, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+			Expect(api.writes).To(Equal(writes))
+		})
+
+		It("should correct and report drift", func() {
+			_, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			api.users["astarte"] = rabbitmq.User{Name: "astarte", PasswordHash: hashRabbitMQPassword("changed"),
+				HashingAlgorithm: "rabbit_password_hashing_sha256", Tags: json.RawMessage(`["monitoring"]`)}
+			api.permissions["astarte/astarte"] = rabbitmq.Permissions{Configure: "", Write: ".*", Read: ".*"}
+			policy := api.policies["astarte/astarte-data-queues"]
+			policy.Definition = map[string]interface{}{"ha-mode": "all"}
+			api.policies["astarte/astarte-data-queues"] = policy
+
+			drifts, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(HaveLen(3))
+			Expect(drifts[0]).To(ContainSubstring("password"))
+			Expect(drifts[1]).To(ContainSubstring("permissions"))
+			Expect(drifts[2]).To(ContainSubstring("policy"))
+
+			user := api.users["astarte"]
+			Expect(user.HasPassword("s3cr3t")).To(BeTrue())
+			Expect(user.Tags).To(MatchJSON(`["monitoring"]`))
+			Expect(api.permissions["astarte/astarte"].Configure).To(Equal(".*"))
+			Expect(api.policies["astarte/astarte-data-queues"].Definition).To(HaveKeyWithValue("ha-mode", "exactly"))
+
+			drifts, err = EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+		})
+
+		It("should fail when the administrator credentials are refused", func() {
+			Expect(k8sClient.Create(context.Background(), &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rabbitmq-admin-wrong", Namespace: CustomAstarteNamespace},
+				Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("wrong")},
+			})).To(Succeed())
+			cr.Spec.RabbitMQ.Provisioning.AdminCredentialsSecret.Name = "rabbitmq-admin-wrong"
+
+			_, err := EnsureRabbitMQProvisioning(cr, k8sClient)
+			Expect(err).To(MatchError(ContainSubstring("401")))
+			Expect(api.writes).To(BeZero())
+		})
+	})
+})
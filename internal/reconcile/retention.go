@@ -0,0 +1,131 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+)
+
+// RetainedFromLabel marks the resources retained on the deletion of the Astarte instance named in its value
+const RetainedFromLabel = "api.astarte-platform.org/retained-from"
+
+// GetRetainableSecretNames returns the names of the Secrets holding the keys generated for an Astarte instance.
+// Secrets derived from them, e.g. the CFSSL CA proxy, are generated again and need not be retained.
+func GetRetainableSecretNames(cr *apiv2alpha1.Astarte) []string {
+	secretNames := []string{
+		cr.Name + "-housekeeping-private-key",
+		cr.Name + "-housekeeping-public-key",
+		cr.Name + "-secret-key-base",
+	}
+	// A custom CA Secret isn't ours to begin with
+	if cr.Spec.CFSSL.CASecret.Name == "" {
+		secretNames = append(secretNames, GetDeviceCASecretName(cr))
+	}
+	return secretNames
+}
+
+// GetRetainablePersistentVolumeClaimPrefixes returns the name prefixes of the PersistentVolumeClaims of an Astarte instance
+func GetRetainablePersistentVolumeClaimPrefixes(cr *apiv2alpha1.Astarte) []string {
+	return []string{
+		cr.Name + "-vernemq-data",
+	}
+}
+
+// AdoptRetainedResources takes over the Secrets and PersistentVolumeClaims retained when a previous
+// Astarte instance with the same name was deleted. Their ownership is then up to EnsureRetainableSecretsOwnership.
+func AdoptRetainedResources(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	retainedSelector := client.MatchingLabels{RetainedFromLabel: cr.Name}
+
+	secrets := &v1.SecretList{}
+	if err := c.List(context.TODO(), secrets, client.InNamespace(cr.Namespace), retainedSelector); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		log.Info("Adopting retained Secret", "Secret.Name", secret.Name)
+		delete(secret.Labels, RetainedFromLabel)
+		if err := c.Update(context.TODO(), secret); err != nil {
+			return err
+		}
+	}
+
+	// StatefulSets pick their claims up by name, so there's nothing more to do than dropping the label
+	pvcs := &v1.PersistentVolumeClaimList{}
+	if err := c.List(context.TODO(), pvcs, client.InNamespace(cr.Namespace), retainedSelector); err != nil {
+		return err
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		log.Info("Adopting retained PersistentVolumeClaim", "PersistentVolumeClaim.Name", pvc.Name)
+		delete(pvc.Labels, RetainedFromLabel)
+		if err := c.Update(context.TODO(), pvc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureRetainableSecretsOwnership makes the Secrets returned by GetRetainableSecretNames owned by cr, unless they
+// are to be retained on its deletion. Retained Secrets must not be owned by cr at all, as the garbage collector
+// might get to them before the finalizer does, e.g. on foreground deletion.
+func EnsureRetainableSecretsOwnership(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
+	retain := cr.Spec.DeletionPolicy.GetSecretsPolicy() == apiv2alpha1.DeletionPolicyRetain
+
+	for _, secretName := range GetRetainableSecretNames(cr) {
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cr.Namespace}, secret); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		owned := slices.ContainsFunc(secret.GetOwnerReferences(), func(ref metav1.OwnerReference) bool { return ref.UID == cr.UID })
+		switch {
+		case retain && owned:
+			log.Info("Detaching retainable Secret from Astarte", "Secret.Name", secretName)
+			secret.SetOwnerReferences(slices.DeleteFunc(secret.GetOwnerReferences(), func(ref metav1.OwnerReference) bool { return ref.UID == cr.UID }))
+		case !retain && !owned:
+			log.Info("Attaching Secret to Astarte", "Secret.Name", secretName)
+			if err := controllerutil.SetControllerReference(cr, secret, scheme); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+
+		if err := c.Update(context.TODO(), secret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
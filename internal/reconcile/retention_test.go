@@ -0,0 +1,118 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Retained resources testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "retention-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test AdoptRetainedResources", func() {
+		It("should adopt Secrets retained by an Astarte with the same name only", func() {
+			retained := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      GetDeviceCASecretName(cr),
+				Namespace: CustomAstarteNamespace,
+				Labels:    map[string]string{RetainedFromLabel: CustomAstarteName},
+			}}
+			Expect(k8sClient.Create(context.Background(), retained)).To(Succeed())
+			other := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "other-astarte-devices-ca",
+				Namespace: CustomAstarteNamespace,
+				Labels:    map[string]string{RetainedFromLabel: "other-astarte"},
+			}}
+			Expect(k8sClient.Create(context.Background(), other)).To(Succeed())
+
+			Expect(AdoptRetainedResources(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: retained.Name, Namespace: CustomAstarteNamespace}, retained)).To(Succeed())
+			Expect(retained.GetLabels()).ToNot(HaveKey(RetainedFromLabel))
+
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: other.Name, Namespace: CustomAstarteNamespace}, other)).To(Succeed())
+			Expect(other.GetLabels()).To(HaveKeyWithValue(RetainedFromLabel, "other-astarte"))
+			Expect(other.GetOwnerReferences()).To(BeEmpty())
+		})
+
+		It("should be a no-op when nothing was retained", func() {
+			Expect(AdoptRetainedResources(cr, k8sClient, scheme.Scheme)).To(Succeed())
+		})
+	})
+
+	Describe("Test EnsureRetainableSecretsOwnership", func() {
+		It("should detach the Secrets to be retained", func() {
+			cr.Spec.DeletionPolicy = &apiv2alpha1.AstarteDeletionPolicySpec{Secrets: apiv2alpha1.DeletionPolicyRetain}
+			secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: GetDeviceCASecretName(cr), Namespace: CustomAstarteNamespace}}
+			Expect(controllerutil.SetControllerReference(cr, secret, scheme.Scheme)).To(Succeed())
+			Expect(k8sClient.Create(context.Background(), secret)).To(Succeed())
+
+			Expect(EnsureRetainableSecretsOwnership(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: secret.Name, Namespace: CustomAstarteNamespace}, secret)).To(Succeed())
+			Expect(secret.GetOwnerReferences()).To(BeEmpty())
+		})
+
+		It("should attach the Secrets not to be retained", func() {
+			secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: CustomAstarteName + "-secret-key-base", Namespace: CustomAstarteNamespace}}
+			Expect(k8sClient.Create(context.Background(), secret)).To(Succeed())
+
+			Expect(EnsureRetainableSecretsOwnership(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: secret.Name, Namespace: CustomAstarteNamespace}, secret)).To(Succeed())
+			Expect(metav1.IsControlledBy(secret, cr)).To(BeTrue())
+		})
+	})
+})
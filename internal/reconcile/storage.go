@@ -0,0 +1,167 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+)
+
+// ensureStatefulSetStorageExpansion takes care of storage size increases for a StatefulSet. Volume claim templates
+// are immutable, hence when the requested size grows, the existing claims are expanded in place and the StatefulSet
+// is deleted leaving its pods and claims behind, so that it can be recreated with the new template.
+// It returns true when the StatefulSet is being deleted, and should not be reconciled until it is gone.
+func ensureStatefulSetStorageExpansion(statefulSetName string, claimTemplate *v1.PersistentVolumeClaim, cr *apiv2alpha1.Astarte, c client.Client) (bool, error) {
+	theStatefulSet := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: statefulSetName, Namespace: cr.Namespace}, theStatefulSet); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// The StatefulSet is on its way out: wait for it to be gone before creating it again
+	if theStatefulSet.DeletionTimestamp != nil {
+		return true, nil
+	}
+
+	if claimTemplate == nil {
+		return false, nil
+	}
+
+	var currentTemplate *v1.PersistentVolumeClaim
+	for i := range theStatefulSet.Spec.VolumeClaimTemplates {
+		if theStatefulSet.Spec.VolumeClaimTemplates[i].Name == claimTemplate.Name {
+			currentTemplate = &theStatefulSet.Spec.VolumeClaimTemplates[i]
+		}
+	}
+	if currentTemplate == nil {
+		return false, nil
+	}
+
+	requestedSize := claimTemplate.Spec.Resources.Requests[v1.ResourceStorage]
+	currentSize := currentTemplate.Spec.Resources.Requests[v1.ResourceStorage]
+	switch requestedSize.Cmp(currentSize) {
+	case 0:
+		return false, nil
+	case -1:
+		// The webhook prevents this. Volumes can't shrink anyway, so keep what we have.
+		log.Info("Refusing to shrink StatefulSet storage", "StatefulSet", statefulSetName,
+			"Size.Current", currentSize.String(), "Size.Requested", requestedSize.String())
+		claimTemplate.Spec.Resources.Requests[v1.ResourceStorage] = currentSize
+		return false, nil
+	}
+
+	claims, err := getStatefulSetPersistentVolumeClaims(statefulSetName, claimTemplate.Name, cr, c)
+	if err != nil {
+		return false, err
+	}
+
+	// Make sure all claims can be expanded before touching anything. The webhook rejects such resizes, unless
+	// it is disabled: keep the current size then, rather than holding back the rest of the reconciliation.
+	for _, claim := range claims {
+		if err := ensurePersistentVolumeClaimIsExpandable(claim, c); err != nil {
+			log.Error(err, "Cannot expand StatefulSet storage, keeping the current size", "StatefulSet", statefulSetName,
+				"Size.Current", currentSize.String(), "Size.Requested", requestedSize.String())
+			claimTemplate.Spec.Resources.Requests[v1.ResourceStorage] = currentSize
+			return false, nil
+		}
+	}
+
+	for i := range claims {
+		claim := &claims[i]
+		if claim.Status.Phase != v1.ClaimBound {
+			// Only bound claims can be expanded. This one will stay as it is.
+			log.Info("Skipping expansion of unbound PersistentVolumeClaim", "PersistentVolumeClaim.Name", claim.Name)
+			continue
+		}
+		claimSize := claim.Spec.Resources.Requests[v1.ResourceStorage]
+		if claimSize.Cmp(requestedSize) >= 0 {
+			continue
+		}
+
+		log.Info("Expanding PersistentVolumeClaim", "PersistentVolumeClaim.Name", claim.Name, "Size", requestedSize.String())
+		patch := client.MergeFrom(claim.DeepCopy())
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = requestedSize
+		if err := c.Patch(context.TODO(), claim, patch); err != nil {
+			return false, err
+		}
+	}
+
+	// Now get rid of the StatefulSet, leaving its pods and claims alone. It will be recreated with the new template.
+	log.Info("Recreating StatefulSet to update its storage size", "StatefulSet", statefulSetName)
+	if err := c.Delete(context.TODO(), theStatefulSet, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !kerrors.IsNotFound(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// getStatefulSetPersistentVolumeClaims returns the claims created by the StatefulSet out of the given template,
+// named <template>-<statefulset>-<ordinal>
+func getStatefulSetPersistentVolumeClaims(statefulSetName, templateName string, cr *apiv2alpha1.Astarte, c client.Client) ([]v1.PersistentVolumeClaim, error) {
+	claimList := &v1.PersistentVolumeClaimList{}
+	if err := c.List(context.TODO(), claimList, client.InNamespace(cr.Namespace)); err != nil {
+		return nil, err
+	}
+
+	prefix := templateName + "-" + statefulSetName + "-"
+	claims := []v1.PersistentVolumeClaim{}
+	for _, claim := range claimList.Items {
+		ordinal, found := strings.CutPrefix(claim.Name, prefix)
+		if !found {
+			continue
+		}
+		if _, err := strconv.Atoi(ordinal); err != nil {
+			continue
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, nil
+}
+
+func ensurePersistentVolumeClaimIsExpandable(claim v1.PersistentVolumeClaim, c client.Client) error {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return fmt.Errorf("PersistentVolumeClaim %s has no StorageClass, hence it cannot be expanded", claim.Name)
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: *claim.Spec.StorageClassName}, storageClass); err != nil {
+		return err
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("StorageClass %s does not allow volume expansion, PersistentVolumeClaim %s cannot be expanded",
+			storageClass.Name, claim.Name)
+	}
+
+	return nil
+}
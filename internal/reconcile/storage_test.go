@@ -0,0 +1,160 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	"go.openly.dev/pointy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Storage expansion testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "storage-expansion-test"
+		ExpandableClassName    = "expandable-storage"
+		FixedClassName         = "fixed-storage"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		for name, expandable := range map[string]bool{ExpandableClassName: true, FixedClassName: false} {
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: name},
+				Provisioner:          "example.com/storage",
+				AllowVolumeExpansion: pointy.Bool(expandable),
+			}
+			Expect(k8sClient.Create(context.Background(), storageClass)).To(Succeed())
+		}
+	})
+
+	AfterAll(func() {
+		for _, name := range []string{ExpandableClassName, FixedClassName} {
+			Expect(k8sClient.Delete(context.Background(), &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}})).To(Succeed())
+		}
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.VerneMQ.Deploy = pointy.Bool(true)
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	// createBoundClaim creates the claim the StatefulSet controller would have created for the first VerneMQ pod
+	createBoundClaim := func(className string) *v1.PersistentVolumeClaim {
+		claim := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetVerneMQStatefulSetName(cr) + "-data-" + GetVerneMQStatefulSetName(cr) + "-0",
+				Namespace: CustomAstarteNamespace,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				StorageClassName: pointy.String(className),
+				Resources:        v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: *resource.NewScaledQuantity(4, resource.Giga)}},
+			},
+		}
+		Expect(k8sClient.Create(context.Background(), claim)).To(Succeed())
+		claim.Status.Phase = v1.ClaimBound
+		Expect(k8sClient.Status().Update(context.Background(), claim)).To(Succeed())
+		return claim
+	}
+
+	Describe("Test ensureStatefulSetStorageExpansion", func() {
+		It("should expand the claims and recreate the VerneMQ StatefulSet", func() {
+			cr.Spec.VerneMQ.Storage = &apiv2alpha1.AstartePersistentStorageSpec{
+				Size:      resource.NewScaledQuantity(4, resource.Giga),
+				ClassName: ExpandableClassName,
+			}
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			claim := createBoundClaim(ExpandableClassName)
+
+			cr.Spec.VerneMQ.Storage.Size = resource.NewScaledQuantity(8, resource.Giga)
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: claim.Name, Namespace: CustomAstarteNamespace}, claim)).To(Succeed())
+			size := claim.Spec.Resources.Requests[v1.ResourceStorage]
+			Expect(size.Cmp(*resource.NewScaledQuantity(8, resource.Giga))).To(BeZero())
+
+			// The StatefulSet is gone, or on its way out while its pods are orphaned
+			Eventually(func() bool {
+				statefulSet := &appsv1.StatefulSet{}
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: CustomAstarteNamespace}, statefulSet)
+				return apierrors.IsNotFound(err) || statefulSet.DeletionTimestamp != nil
+			}, Timeout, Interval).Should(BeTrue())
+
+			// Until it is gone, there's nothing else to do
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+		})
+
+		It("should keep the current size of claims whose StorageClass does not allow expansion", func() {
+			cr.Spec.VerneMQ.Storage = &apiv2alpha1.AstartePersistentStorageSpec{
+				Size:      resource.NewScaledQuantity(4, resource.Giga),
+				ClassName: FixedClassName,
+			}
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			createBoundClaim(FixedClassName)
+
+			cr.Spec.VerneMQ.Storage.Size = resource.NewScaledQuantity(8, resource.Giga)
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: CustomAstarteNamespace}, statefulSet)).To(Succeed())
+			Expect(statefulSet.DeletionTimestamp).To(BeNil())
+			size := statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage]
+			Expect(size.Cmp(*resource.NewScaledQuantity(4, resource.Giga))).To(BeZero())
+		})
+
+		It("should keep the current size when asked to shrink", func() {
+			cr.Spec.VerneMQ.Storage = &apiv2alpha1.AstartePersistentStorageSpec{Size: resource.NewScaledQuantity(8, resource.Giga)}
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			cr.Spec.VerneMQ.Storage.Size = resource.NewScaledQuantity(4, resource.Giga)
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: CustomAstarteNamespace}, statefulSet)).To(Succeed())
+			size := statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage]
+			Expect(size.Cmp(*resource.NewScaledQuantity(8, resource.Giga))).To(BeZero())
+		})
+	})
+})
@@ -353,7 +353,7 @@ func appendAstarteFDOEnvVars(ret []v1.EnvVar, cr *apiv2alpha1.Astarte) []v1.EnvV
 		port = 80
 	}
 
-	rsPort := pointy.Int32Value(cr.Spec.Features.FDO.RendezvousServer.Port, 8041)
+	rsPort := pointy.Int32Value(cr.Spec.Features.FDO.RendezvousServer.Port, apiv2alpha1.DefaultFDORendezvousServerPort)
 
 	ret = append(ret,
 		v1.EnvVar{
@@ -498,6 +498,20 @@ func appendCassandraConnectionEnvVars(ret []v1.EnvVar, cr *apiv2alpha1.Astarte)
 			})
 		}
 
+		// Client certificate, for mutual TLS. Mounted by getBackingServicesSSLVolumes as well.
+		if spec.SSLConfiguration.ClientCertSecret.Name != "" {
+			ret = append(ret,
+				v1.EnvVar{
+					Name:  "CASSANDRA_SSL_CERT_FILE",
+					Value: "/cassandra-ssl-client/" + v1.TLSCertKey,
+				},
+				v1.EnvVar{
+					Name:  "CASSANDRA_SSL_KEY_FILE",
+					Value: "/cassandra-ssl-client/" + v1.TLSPrivateKeyKey,
+				},
+			)
+		}
+
 		// SNI configuration
 		switch {
 		case spec.SSLConfiguration.CustomSNI != "":
@@ -513,7 +527,7 @@ func appendCassandraConnectionEnvVars(ret []v1.EnvVar, cr *apiv2alpha1.Astarte)
 		}
 	}
 
-	if spec.CredentialsSecret != nil {
+	if spec.CredentialsSecret != nil || spec.ConnectionStringSecret != nil {
 		// Fetch our Credentials for Cassandra
 		userCredentialsSecretName, userCredentialsSecretUsernameKey, userCredentialsSecretPasswordKey := misc.GetCassandraUserCredentialsSecret(cr)
 
@@ -603,6 +617,20 @@ func appendRabbitMQConnectionEnvVars(ret []v1.EnvVar, prefix string, cr *apiv2al
 			})
 		}
 
+		// Client certificate, for mutual TLS. Mounted by getBackingServicesSSLVolumes as well.
+		if spec.SSLConfiguration.ClientCertSecret.Name != "" {
+			ret = append(ret,
+				v1.EnvVar{
+					Name:  prefix + "_SSL_CERT_FILE",
+					Value: "/rabbitmq-ssl-client/" + v1.TLSCertKey,
+				},
+				v1.EnvVar{
+					Name:  prefix + "_SSL_KEY_FILE",
+					Value: "/rabbitmq-ssl-client/" + v1.TLSPrivateKeyKey,
+				},
+			)
+		}
+
 		// SNI configuration
 		switch {
 		case spec.SSLConfiguration.CustomSNI != "":
@@ -620,7 +648,6 @@ func appendRabbitMQConnectionEnvVars(ret []v1.EnvVar, prefix string, cr *apiv2al
 
 	// Fetch our Credentials for RabbitMQ
 	rabbitMQHost, rabbitMQPort := misc.GetRabbitMQHostnameAndPort(cr)
-	userCredentialsSecretName, userCredentialsSecretUsernameKey, userCredentialsSecretPasswordKey := misc.GetRabbitMQUserCredentialsSecret(cr)
 
 	// Standard RMQ env vars that, like it or not, we need to plug in everywhere.
 	ret = append(ret,
@@ -636,6 +663,15 @@ func appendRabbitMQConnectionEnvVars(ret []v1.EnvVar, prefix string, cr *apiv2al
 			Name:  prefix + "_VIRTUAL_HOST",
 			Value: virtualHost,
 		},
+	)
+
+	// Without credentials, the client certificate is all we need to authenticate
+	if spec.UsesClientCertificateOnly() {
+		return ret
+	}
+
+	userCredentialsSecretName, userCredentialsSecretUsernameKey, userCredentialsSecretPasswordKey := misc.GetRabbitMQUserCredentialsSecret(cr)
+	ret = append(ret,
 		v1.EnvVar{
 			Name: prefix + "_USERNAME",
 			ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
@@ -678,24 +714,69 @@ func getAstarteCommonVolumes(cr *apiv2alpha1.Astarte) []v1.Volume {
 		},
 	}
 
-	if cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name != "" {
+	return append(ret, getBackingServicesSSLVolumes(cr)...)
+}
+
+func getAstarteCommonVolumeMounts(cr *apiv2alpha1.Astarte) []v1.VolumeMount {
+	ret := []v1.VolumeMount{
+		{
+			Name:      "beam-config",
+			MountPath: "/beamconfig",
+			ReadOnly:  true,
+		},
+	}
+
+	return append(ret, getBackingServicesSSLVolumeMounts(cr)...)
+}
+
+// getBackingServicesSSLVolumes returns the volumes holding the CAs and client certificates used to connect
+// to RabbitMQ and Cassandra, matching the paths set by appendRabbitMQConnectionEnvVars and appendCassandraConnectionEnvVars
+func getBackingServicesSSLVolumes(cr *apiv2alpha1.Astarte) []v1.Volume {
+	ret := []v1.Volume{}
+
+	if cr.Spec.RabbitMQ.Connection != nil {
+		ret = append(ret, getSSLVolumes("rabbitmq", cr.Spec.RabbitMQ.Connection.SSLConfiguration)...)
+	}
+	if cr.Spec.Cassandra.Connection != nil {
+		ret = append(ret, getSSLVolumes("cassandra", cr.Spec.Cassandra.Connection.SSLConfiguration)...)
+	}
+
+	return ret
+}
+
+func getBackingServicesSSLVolumeMounts(cr *apiv2alpha1.Astarte) []v1.VolumeMount {
+	ret := []v1.VolumeMount{}
+
+	if cr.Spec.RabbitMQ.Connection != nil {
+		ret = append(ret, getSSLVolumeMounts("rabbitmq", cr.Spec.RabbitMQ.Connection.SSLConfiguration)...)
+	}
+	if cr.Spec.Cassandra.Connection != nil {
+		ret = append(ret, getSSLVolumeMounts("cassandra", cr.Spec.Cassandra.Connection.SSLConfiguration)...)
+	}
+
+	return ret
+}
+
+func getSSLVolumes(service string, ssl apiv2alpha1.GenericSSLConfigurationSpec) []v1.Volume {
+	ret := []v1.Volume{}
+
+	if ssl.CustomCASecret.Name != "" {
 		// Mount the secret!
 		ret = append(ret, v1.Volume{
-			Name: "rabbitmq-ssl-ca",
+			Name: service + "-ssl-ca",
 			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
-				SecretName: cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name,
+				SecretName: ssl.CustomCASecret.Name,
 				Items:      []v1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
 			}},
 		})
 	}
 
-	if cr.Spec.Cassandra.Connection.SSLConfiguration.CustomCASecret.Name != "" {
-		// Mount the secret!
+	if ssl.ClientCertSecret.Name != "" {
 		ret = append(ret, v1.Volume{
-			Name: "cassandra-ssl-ca",
+			Name: service + "-ssl-client-cert",
 			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
-				SecretName: cr.Spec.Cassandra.Connection.SSLConfiguration.CustomCASecret.Name,
-				Items:      []v1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				SecretName: ssl.ClientCertSecret.Name,
+				Items:      []v1.KeyToPath{{Key: v1.TLSCertKey, Path: v1.TLSCertKey}, {Key: v1.TLSPrivateKeyKey, Path: v1.TLSPrivateKeyKey}},
 			}},
 		})
 	}
@@ -703,29 +784,21 @@ func getAstarteCommonVolumes(cr *apiv2alpha1.Astarte) []v1.Volume {
 	return ret
 }
 
-func getAstarteCommonVolumeMounts(cr *apiv2alpha1.Astarte) []v1.VolumeMount {
-	ret := []v1.VolumeMount{
-		{
-			Name:      "beam-config",
-			MountPath: "/beamconfig",
-			ReadOnly:  true,
-		},
-	}
+func getSSLVolumeMounts(service string, ssl apiv2alpha1.GenericSSLConfigurationSpec) []v1.VolumeMount {
+	ret := []v1.VolumeMount{}
 
-	if cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name != "" {
-		// Mount the secret!
+	if ssl.CustomCASecret.Name != "" {
 		ret = append(ret, v1.VolumeMount{
-			Name:      "rabbitmq-ssl-ca",
-			MountPath: "/rabbitmq-ssl",
+			Name:      service + "-ssl-ca",
+			MountPath: "/" + service + "-ssl",
 			ReadOnly:  true,
 		})
 	}
 
-	if cr.Spec.Cassandra.Connection.SSLConfiguration.CustomCASecret.Name != "" {
-		// Mount the secret!
+	if ssl.ClientCertSecret.Name != "" {
 		ret = append(ret, v1.VolumeMount{
-			Name:      "cassandra-ssl-ca",
-			MountPath: "/cassandra-ssl",
+			Name:      service + "-ssl-client-cert",
+			MountPath: "/" + service + "-ssl-client",
 			ReadOnly:  true,
 		})
 	}
@@ -768,11 +841,11 @@ func getDeploymentStrategyForClusteredResource(cr *apiv2alpha1.Astarte, resource
 }
 
 func getDataQueueCount(cr *apiv2alpha1.Astarte) int {
-	return pointy.IntValue(cr.Spec.Components.DataUpdaterPlant.DataQueueCount, 128)
+	return pointy.IntValue(cr.Spec.Components.DataUpdaterPlant.DataQueueCount, apiv2alpha1.DefaultDataQueueCount)
 }
 
 func getAppEngineAPIMaxResultslimit(cr *apiv2alpha1.Astarte) int {
-	return pointy.IntValue(cr.Spec.Components.AppengineAPI.MaxResultsLimit, 10000)
+	return pointy.IntValue(cr.Spec.Components.AppengineAPI.MaxResultsLimit, apiv2alpha1.DefaultAppengineAPIMaxResultsLimit)
 }
 
 func getBaseAstarteAPIURL(cr *apiv2alpha1.Astarte) string {
@@ -823,20 +896,26 @@ func computePodLabels(r apiv2alpha1.PodLabelsGetter, labels map[string]string) m
 	return podLabels
 }
 
-func getReplicaCountForResource(resource *apiv2alpha1.AstarteGenericClusteredResource, cr *apiv2alpha1.Astarte, c client.Client, log logr.Logger) *int32 {
-	if cr.Spec.Features.Autoscaling && resource.Autoscale != nil {
-		if hpaStatus, err := getHPAStatusForResource(resource.Autoscale.Horizontal, cr, c, log); err == nil {
-			// This is a special case to avoid a race condition with HPA, which can lead to the Operator
-			// and HPA fighting over replica count, causing service disruption. This can happen when
-			// the HPA isn't able to fetch metrics for the pods, and decides to scale down to 0.
-			// This is a known issue in HPA, and this is a workaround to avoid it.
-			if hpaStatus.DesiredReplicas == 0 {
-				log.Info("HPA is reporting 0 desired replicas. This is likely a transient state. Ignoring HPA and using the spec's replica count", "HPA.Name", resource.Autoscale.Horizontal)
-				return resource.Replicas
-			}
-			log.Info("Getting replica count from HPA", "value", hpaStatus.DesiredReplicas)
-			return &hpaStatus.DesiredReplicas
+// getReplicaCountForResource returns the replica count the Deployment/StatefulSet of the resource should have.
+// While a HorizontalPodAutoscaler is in charge of the resource, the current replica count is left untouched.
+func getReplicaCountForResource(resource *apiv2alpha1.AstarteGenericClusteredResource, currentReplicas *int32, cr *apiv2alpha1.Astarte, c client.Client, log logr.Logger) *int32 {
+	if !cr.Spec.Features.Autoscaling || !resource.Autoscale.IsHorizontal() {
+		return resource.Replicas
+	}
+
+	if resource.Autoscale.IsOperatorManaged() {
+		if currentReplicas != nil {
+			log.V(1).Info("Replica count is managed by the HPA", "value", *currentReplicas)
+			return currentReplicas
 		}
+		// Start from the lower bound, the HPA will take it from there
+		return pointy.Int32(getHPAMinReplicas(*resource))
+	}
+
+	// Externally managed HPA: leave the replica count alone as long as it exists
+	if _, err := getHPAStatusForResource(resource.Autoscale.Horizontal, cr, c, log); err == nil && currentReplicas != nil {
+		log.V(1).Info("Replica count is managed by the HPA", "HPA.Name", resource.Autoscale.Horizontal, "value", *currentReplicas)
+		return currentReplicas
 	}
 	return resource.Replicas
 }
@@ -860,6 +939,23 @@ func getCassandraNodes(cr *apiv2alpha1.Astarte) string {
 	return strings.Join(nodes, ",")
 }
 
+// getDataCenterReplication returns the replication factor of each datacenter, as given in the "dc1:3,dc2:5" form
+func getDataCenterReplication(ask apiv2alpha1.AstarteSystemKeyspaceSpec) map[string]int {
+	theMap := make(map[string]int)
+
+	pairs := strings.Split(ask.DataCenterReplication, ",")
+	for _, pair := range pairs {
+		kv := strings.Split(pair, ":")
+		if len(kv) != 2 {
+			continue
+		}
+		// no need to check the error, this is covered in validation webhooks
+		val, _ := strconv.Atoi(kv[1])
+		theMap[kv[0]] = val
+	}
+	return theMap
+}
+
 func appendAstarteKeyspaceEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
 	ask := cr.Spec.Cassandra.AstarteSystemKeyspace
 
@@ -879,18 +975,7 @@ func appendAstarteKeyspaceEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
 	}
 
 	// if we're here, we must handle NetworkTopologyStrategy
-	theMap := make(map[string]int)
-
-	pairs := strings.Split(ask.DataCenterReplication, ",")
-	for _, pair := range pairs {
-		kv := strings.Split(pair, ":")
-		key := kv[0]
-		// no need to check the error, this is covered in validation webhooks
-		val, _ := strconv.Atoi(kv[1])
-		theMap[key] = val
-	}
-
-	b, _ := json.Marshal(theMap)
+	b, _ := json.Marshal(getDataCenterReplication(ask))
 
 	ret = append(ret, v1.EnvVar{
 		Name:  "HOUSEKEEPING_ASTARTE_KEYSPACE_NETWORK_REPLICATION_MAP",
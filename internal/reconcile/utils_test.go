@@ -793,6 +793,72 @@ var _ = Describe("Utils functions testing", Ordered, Serial, func() {
 		})
 	})
 
+	Describe("Test getBackingServicesSSLVolumes", func() {
+		It("should include the client certificates when configured", func() {
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.ClientCertSecret.Name = "rabbitmq-client"
+			cr.Spec.Cassandra.Connection.SSLConfiguration.ClientCertSecret.Name = "cassandra-client"
+
+			volumes := getBackingServicesSSLVolumes(cr)
+			Expect(volumes).To(HaveLen(2))
+			Expect(volumes[0].Name).To(Equal("rabbitmq-ssl-client-cert"))
+			Expect(volumes[0].VolumeSource.Secret.SecretName).To(Equal("rabbitmq-client"))
+			Expect(volumes[0].VolumeSource.Secret.Items).To(ConsistOf(
+				v1.KeyToPath{Key: "tls.crt", Path: "tls.crt"},
+				v1.KeyToPath{Key: "tls.key", Path: "tls.key"},
+			))
+			Expect(volumes[1].Name).To(Equal("cassandra-ssl-client-cert"))
+			Expect(volumes[1].VolumeSource.Secret.SecretName).To(Equal("cassandra-client"))
+
+			mounts := getBackingServicesSSLVolumeMounts(cr)
+			Expect(mounts).To(ConsistOf(
+				v1.VolumeMount{Name: "rabbitmq-ssl-client-cert", MountPath: "/rabbitmq-ssl-client", ReadOnly: true},
+				v1.VolumeMount{Name: "cassandra-ssl-client-cert", MountPath: "/cassandra-ssl-client", ReadOnly: true},
+			))
+		})
+
+		It("should be mounted in VerneMQ, too", func() {
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.CustomCASecret.Name = RabbitMQCASecret
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.ClientCertSecret.Name = "rabbitmq-client"
+
+			Expect(getVerneMQVolumes(cr)).To(ContainElements(getBackingServicesSSLVolumes(cr)))
+			Expect(getVerneMQVolumeMounts("data", cr)).To(ContainElements(getBackingServicesSSLVolumeMounts(cr)))
+		})
+	})
+
+	Describe("Test client certificate env vars", func() {
+		BeforeEach(func() {
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.Enable = true
+			cr.Spec.RabbitMQ.Connection.SSLConfiguration.ClientCertSecret.Name = "rabbitmq-client"
+			cr.Spec.Cassandra.Connection.SSLConfiguration.Enable = true
+			cr.Spec.Cassandra.Connection.SSLConfiguration.ClientCertSecret.Name = "cassandra-client"
+		})
+
+		It("should point to the mounted client certificates", func() {
+			rabbitMQEnv := appendRabbitMQConnectionEnvVars([]v1.EnvVar{}, "TEST_AMQP", cr)
+			Expect(rabbitMQEnv).To(ContainElements(
+				v1.EnvVar{Name: "TEST_AMQP_SSL_CERT_FILE", Value: "/rabbitmq-ssl-client/tls.crt"},
+				v1.EnvVar{Name: "TEST_AMQP_SSL_KEY_FILE", Value: "/rabbitmq-ssl-client/tls.key"},
+			))
+			cassandraEnv := appendCassandraConnectionEnvVars([]v1.EnvVar{}, cr)
+			Expect(cassandraEnv).To(ContainElements(
+				v1.EnvVar{Name: "CASSANDRA_SSL_CERT_FILE", Value: "/cassandra-ssl-client/tls.crt"},
+				v1.EnvVar{Name: "CASSANDRA_SSL_KEY_FILE", Value: "/cassandra-ssl-client/tls.key"},
+			))
+		})
+
+		It("should omit the credentials when the client certificate is enough", func() {
+			cr.Spec.RabbitMQ.Connection.CredentialsSecret = nil
+			cr.Spec.Cassandra.Connection.CredentialsSecret = nil
+
+			for _, env := range appendRabbitMQConnectionEnvVars([]v1.EnvVar{}, "TEST_AMQP", cr) {
+				Expect(env.Name).ToNot(BeElementOf("TEST_AMQP_USERNAME", "TEST_AMQP_PASSWORD"))
+			}
+			for _, env := range appendCassandraConnectionEnvVars([]v1.EnvVar{}, cr) {
+				Expect(env.Name).ToNot(BeElementOf("CASSANDRA_USERNAME", "CASSANDRA_PASSWORD"))
+			}
+		})
+	})
+
 	Describe("Test getAstarteCommonVolumeMounts", func() {
 		It("should return basic volume mounts without SSL configuration", func() {
 			crWithoutSSL := cr.DeepCopy()
@@ -42,6 +42,8 @@ import (
 	"github.com/astarte-platform/astarte-kubernetes-operator/internal/misc"
 )
 
+const verneMQContainerName = "vernemq"
+
 // EnsureVerneMQ reconciles VerneMQ
 func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Scheme) error {
 	statefulSetName := GetVerneMQStatefulSetName(cr)
@@ -62,8 +64,8 @@ func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Sch
 			}
 		}
 
-		// That would be all for today.
-		return nil
+		// Drop the HPA as well, if we own one. That would be all for today.
+		return deleteHorizontalPodAutoscalerIfExists(statefulSetName, cr, c)
 	}
 
 	// Ensure we reconcile with the RBAC Roles, if needed.
@@ -101,6 +103,7 @@ func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Sch
 				Protocol:   v1.ProtocolTCP,
 			},
 		}
+		service.Spec.Ports = append(service.Spec.Ports, getVerneMQListenersServicePorts(cr)...)
 		service.Spec.Selector = labels
 		return nil
 	}); err == nil {
@@ -113,6 +116,17 @@ func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Sch
 	dataVolumeName, persistentVolumeClaim := computePersistentVolumeClaim(statefulSetName+"-data", resource.NewScaledQuantity(4, resource.Giga),
 		cr.Spec.VerneMQ.Storage, cr)
 
+	// Storage can only grow through the claims themselves, and requires the StatefulSet to be recreated
+	recreating, err := ensureStatefulSetStorageExpansion(statefulSetName, persistentVolumeClaim, cr, c)
+	if err != nil {
+		return err
+	}
+	if recreating {
+		// The deletion will trigger a new reconciliation
+		log.Info("Waiting for the VerneMQ StatefulSet to be deleted before recreating it")
+		return nil
+	}
+
 	// If the SSL certificate changed, we should restart VerneMQ pods
 	// Note that we don't restart pods if the secret is modified, but
 	// just on secret deletion/creation.
@@ -149,8 +163,11 @@ func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Sch
 
 		// Assign the Spec.
 		vmqStatefulSet.ObjectMeta.Labels = map[string]string{"component": "astarte"}
+		currentReplicas := vmqStatefulSet.Spec.Replicas
+		currentUpdateStrategy := vmqStatefulSet.Spec.UpdateStrategy
 		vmqStatefulSet.Spec = statefulSetSpec
-		vmqStatefulSet.Spec.Replicas = getReplicaCountForResource(&cr.Spec.VerneMQ.AstarteGenericClusteredResource, cr, c, log)
+		vmqStatefulSet.Spec.Replicas = getReplicaCountForResource(&cr.Spec.VerneMQ.AstarteGenericClusteredResource, currentReplicas, cr, c, log)
+		vmqStatefulSet.Spec.UpdateStrategy = getVerneMQStatefulSetUpdateStrategy(cr, currentUpdateStrategy, pointy.Int32Value(vmqStatefulSet.Spec.Replicas, 1))
 
 		return nil
 	})
@@ -159,7 +176,14 @@ func EnsureVerneMQ(cr *apiv2alpha1.Astarte, c client.Client, scheme *runtime.Sch
 	}
 
 	misc.LogCreateOrUpdateOperationResult(log, result, cr, service)
-	return nil
+
+	// Replace the next node, if a coordinated update is in progress
+	if err := ensureVerneMQCoordinatedUpdate(cr, vmqStatefulSet, c); err != nil {
+		return err
+	}
+
+	// Last but not least, the HPA, if any
+	return ensureHorizontalPodAutoscaler(statefulSetName, "StatefulSet", cr.Spec.VerneMQ.AstarteGenericClusteredResource, cr, c, scheme)
 }
 
 func GetVerneMQStatefulSetName(cr *apiv2alpha1.Astarte) string {
@@ -252,10 +276,25 @@ func getVerneMQEnvVars(statefulSetName string, cr *apiv2alpha1.Astarte) []v1.Env
 			Value: "/opt/vernemq/etc/privkey.pem",
 		})
 
-		envVars = append(envVars, v1.EnvVar{
-			Name:  "CFSSL_URL",
-			Value: fmt.Sprintf("http://%s-cfssl.%s.svc.cluster.local", cr.Name, cr.Namespace),
-		})
+		envVars = append(envVars, getCFSSLURLEnvVars("CFSSL_URL", cr)...)
+	}
+
+	if pointy.BoolValue(cr.Spec.VerneMQ.ProxyProtocol, false) {
+		// Devices reach VerneMQ through a load balancer sending the PROXY protocol header. VerneMQ reads it on
+		// tcp and ws listeners only, hence the load balancer terminates TLS and forwards the client certificate.
+		envVars = append(envVars,
+			v1.EnvVar{
+				Name:  "DOCKER_VERNEMQ_LISTENER__TCP__PROXY",
+				Value: fmt.Sprintf("0.0.0.0:%d", apiv2alpha1.VerneMQProxyProtocolPort),
+			},
+			v1.EnvVar{
+				Name:  "DOCKER_VERNEMQ_LISTENER__TCP__PROXY__PROXY_PROTOCOL",
+				Value: "on",
+			},
+			v1.EnvVar{
+				Name:  "DOCKER_VERNEMQ_LISTENER__TCP__PROXY__PROXY_PROTOCOL_USE_CN_AS_USERNAME",
+				Value: "on",
+			})
 	}
 
 	persistentClientExpiration := cr.Spec.VerneMQ.PersistentClientExpiration
@@ -271,7 +310,7 @@ func getVerneMQEnvVars(statefulSetName string, cr *apiv2alpha1.Astarte) []v1.Env
 		},
 		v1.EnvVar{
 			Name:  "DOCKER_VERNEMQ_MAX_OFFLINE_MESSAGES",
-			Value: strconv.Itoa(pointy.IntValue(cr.Spec.VerneMQ.MaxOfflineMessages, 1000000)),
+			Value: strconv.Itoa(pointy.IntValue(cr.Spec.VerneMQ.MaxOfflineMessages, apiv2alpha1.DefaultVerneMQMaxOfflineMessages)),
 		})
 
 	// and, starting from Astarte 1.2, add cassandra/scylla env vars
@@ -281,6 +320,9 @@ func getVerneMQEnvVars(statefulSetName string, cr *apiv2alpha1.Astarte) []v1.Env
 		envVars = appendVerneMQCassandraConnectionEnvVars(envVars, cr)
 	}
 
+	// Additional listeners and vernemq.conf overrides
+	envVars = append(envVars, getVerneMQConfigEnvVars(cr)...)
+
 	// Add any explicit additional env
 	// This comes last to allow users to override any env var we set
 	if len(cr.Spec.VerneMQ.AdditionalEnv) > 0 {
@@ -334,7 +376,7 @@ func getVerneMQPodSpec(statefulSetName, dataVolumeName string, cr *apiv2alpha1.A
 		Affinity:                      getAffinityForClusteredResource(statefulSetName, cr.Spec.VerneMQ.AstarteGenericClusteredResource),
 		Containers: []v1.Container{
 			{
-				Name:         "vernemq",
+				Name:         verneMQContainerName,
 				VolumeMounts: getVerneMQVolumeMounts(dataVolumeName, cr),
 				// Defaults to the custom image built in Astarte
 				Image:           getAstarteImageForClusteredResource("vernemq", cr.Spec.VerneMQ.AstarteGenericClusteredResource, cr),
@@ -368,18 +410,32 @@ func getVerneMQPodSpec(statefulSetName, dataVolumeName string, cr *apiv2alpha1.A
 		Volumes: getVerneMQVolumes(cr),
 	}
 
+	container := getVerneMQContainer(&ps)
+	if pointy.BoolValue(cr.Spec.VerneMQ.ProxyProtocol, false) {
+		container.Ports = append(container.Ports, v1.ContainerPort{Name: "mqtt-proxy", ContainerPort: apiv2alpha1.VerneMQProxyProtocolPort})
+	}
+
+	// Expose additional listeners, if any
+	container.Ports = append(container.Ports, getVerneMQListenersContainerPorts(cr)...)
+
+	// When updates are coordinated, nodes removed by a scale down leave the cluster before stopping. Give them the time to do so.
+	if cr.Spec.VerneMQ.UpdateStrategy.IsCoordinated() {
+		ps.TerminationGracePeriodSeconds = pointy.Int64(int64(getVerneMQDrainTimeoutSeconds(cr)) + 30)
+		container.Lifecycle = &v1.Lifecycle{PreStop: getVerneMQPreStopHandler(cr)}
+	}
+
 	// do we want priorities?
 	if cr.Spec.Features.AstartePodPriorities.IsEnabled() {
 		// is a priorityClass specified in the Astarte CR?
 		switch cr.Spec.VerneMQ.AstarteGenericClusteredResource.PriorityClass {
 		case highPriority:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		case midPriority:
-			ps.PriorityClassName = AstarteMidPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteMidPriorityName)
 		case lowPriority:
-			ps.PriorityClassName = AstarteLowPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteLowPriorityName)
 		default:
-			ps.PriorityClassName = AstarteHighPriorityName
+			ps.PriorityClassName = getAstartePriorityClassName(cr, AstarteHighPriorityName)
 		}
 	}
 
@@ -413,7 +469,8 @@ func getVerneMQVolumes(cr *apiv2alpha1.Astarte) []v1.Volume {
 		})
 	}
 
-	return theVolumes
+	// VerneMQ connects to RabbitMQ and Cassandra, too
+	return append(theVolumes, getBackingServicesSSLVolumes(cr)...)
 }
 
 func getVerneMQVolumeMounts(dataVolumeName string, cr *apiv2alpha1.Astarte) []v1.VolumeMount {
@@ -435,7 +492,17 @@ func getVerneMQVolumeMounts(dataVolumeName string, cr *apiv2alpha1.Astarte) []v1
 			ReadOnly:  true,
 		})
 	}
-	return theVolumeMounts
+	return append(theVolumeMounts, getBackingServicesSSLVolumeMounts(cr)...)
+}
+
+// getVerneMQContainer returns the VerneMQ container of the given pod spec
+func getVerneMQContainer(ps *v1.PodSpec) *v1.Container {
+	for i := range ps.Containers {
+		if ps.Containers[i].Name == verneMQContainerName {
+			return &ps.Containers[i]
+		}
+	}
+	return nil
 }
 
 func getVerneMQPolicyRules() []rbacv1.PolicyRule {
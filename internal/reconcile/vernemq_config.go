@@ -0,0 +1,104 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+)
+
+// getVerneMQConfigEnvVars renders the additional listeners and the configuration overrides as environment
+// variables, which the VerneMQ image turns into vernemq.conf settings.
+func getVerneMQConfigEnvVars(cr *apiv2alpha1.Astarte) []v1.EnvVar {
+	config := map[string]string{}
+	for key, value := range cr.Spec.VerneMQ.ConfigOverrides {
+		config[key] = value
+	}
+	// Listeners come last, as they can't be overridden anyway
+	for key, value := range getVerneMQListenersConfig(cr) {
+		config[key] = value
+	}
+
+	// Keep the order stable, or pods would be restarted at each reconciliation
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	envVars := []v1.EnvVar{}
+	for _, key := range keys {
+		envVars = append(envVars, v1.EnvVar{Name: getVerneMQConfigEnvVarName(key), Value: config[key]})
+	}
+	return envVars
+}
+
+// getVerneMQConfigEnvVarName maps a vernemq.conf key to the environment variable setting it,
+// e.g. "listener.tcp.default" to "DOCKER_VERNEMQ_LISTENER__TCP__DEFAULT"
+func getVerneMQConfigEnvVarName(key string) string {
+	return "DOCKER_VERNEMQ_" + strings.ToUpper(strings.ReplaceAll(key, ".", "__"))
+}
+
+func getVerneMQListenersConfig(cr *apiv2alpha1.Astarte) map[string]string {
+	config := map[string]string{}
+	for _, listener := range cr.Spec.VerneMQ.Listeners {
+		// Port names allow dashes, vernemq.conf keys and environment variables are better off without them
+		prefix := "listener." + string(listener.Type) + "." + strings.ReplaceAll(listener.Name, "-", "_")
+		config[prefix] = "0.0.0.0:" + strconv.Itoa(int(listener.Port))
+
+		if listener.ProxyProtocol {
+			config[prefix+".proxy_protocol"] = "on"
+		}
+
+		if listener.Type.IsTLS() {
+			// Same certificates as the default SSL listener. Check getVerneMQEnvVars for where they come from.
+			config[prefix+".cafile"] = "/opt/vernemq/etc/ca.pem"
+			config[prefix+".certfile"] = "/opt/vernemq/etc/cert.pem"
+			config[prefix+".keyfile"] = "/opt/vernemq/etc/privkey.pem"
+		}
+	}
+	return config
+}
+
+func getVerneMQListenersContainerPorts(cr *apiv2alpha1.Astarte) []v1.ContainerPort {
+	ports := []v1.ContainerPort{}
+	for _, listener := range cr.Spec.VerneMQ.Listeners {
+		ports = append(ports, v1.ContainerPort{Name: listener.Name, ContainerPort: listener.Port, Protocol: v1.ProtocolTCP})
+	}
+	return ports
+}
+
+func getVerneMQListenersServicePorts(cr *apiv2alpha1.Astarte) []v1.ServicePort {
+	ports := []v1.ServicePort{}
+	for _, listener := range cr.Spec.VerneMQ.Listeners {
+		ports = append(ports, v1.ServicePort{
+			Name:       listener.Name,
+			Port:       listener.Port,
+			TargetPort: intstr.FromString(listener.Name),
+			Protocol:   v1.ProtocolTCP,
+		})
+	}
+	return ports
+}
@@ -0,0 +1,140 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	"go.openly.dev/pointy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("VerneMQ configuration testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "vernemq-config-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	AfterAll(func() {
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.VerneMQ.Deploy = pointy.Bool(true)
+		cr.Spec.VerneMQ.Listeners = []apiv2alpha1.AstarteVerneMQListenerSpec{
+			{Name: "mqtt-ws", Type: apiv2alpha1.VerneMQListenerWS, Port: 8080, ProxyProtocol: true},
+			{Name: "mqtt-wss", Type: apiv2alpha1.VerneMQListenerWSS, Port: 8443},
+		}
+		cr.Spec.VerneMQ.ConfigOverrides = map[string]string{
+			"max_message_size":      "65536",
+			"max_inflight_messages": "50",
+		}
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test getVerneMQConfigEnvVars", func() {
+		It("should render listeners and overrides in a stable order", func() {
+			envVars := getVerneMQConfigEnvVars(cr)
+			names := []string{}
+			for _, e := range envVars {
+				names = append(names, e.Name)
+			}
+			Expect(names).To(Equal([]string{
+				"DOCKER_VERNEMQ_LISTENER__WS__MQTT_WS",
+				"DOCKER_VERNEMQ_LISTENER__WS__MQTT_WS__PROXY_PROTOCOL",
+				"DOCKER_VERNEMQ_LISTENER__WSS__MQTT_WSS",
+				"DOCKER_VERNEMQ_LISTENER__WSS__MQTT_WSS__CAFILE",
+				"DOCKER_VERNEMQ_LISTENER__WSS__MQTT_WSS__CERTFILE",
+				"DOCKER_VERNEMQ_LISTENER__WSS__MQTT_WSS__KEYFILE",
+				"DOCKER_VERNEMQ_MAX_INFLIGHT_MESSAGES",
+				"DOCKER_VERNEMQ_MAX_MESSAGE_SIZE",
+			}))
+			Expect(envVars).To(ContainElement(v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__WS__MQTT_WS", Value: "0.0.0.0:8080"}))
+			Expect(envVars).To(ContainElement(v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__WS__MQTT_WS__PROXY_PROTOCOL", Value: "on"}))
+			Expect(envVars).To(ContainElement(v1.EnvVar{Name: "DOCKER_VERNEMQ_MAX_MESSAGE_SIZE", Value: "65536"}))
+		})
+
+		It("should serve the PROXY protocol on a tcp listener of its own", func() {
+			cr.Spec.VerneMQ.SSLListener = pointy.Bool(true)
+			cr.Spec.VerneMQ.ProxyProtocol = pointy.Bool(true)
+			envVars := getVerneMQEnvVars(GetVerneMQStatefulSetName(cr), cr)
+			Expect(envVars).To(ContainElements(
+				v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__TCP__PROXY", Value: "0.0.0.0:1886"},
+				v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__TCP__PROXY__PROXY_PROTOCOL", Value: "on"},
+				v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__TCP__PROXY__PROXY_PROTOCOL_USE_CN_AS_USERNAME", Value: "on"},
+			))
+			// VerneMQ reads the header on tcp and ws listeners only
+			for _, e := range envVars {
+				Expect(e.Name).ToNot(HavePrefix("DOCKER_VERNEMQ_LISTENER__SSL__DEFAULT__PROXY_PROTOCOL"))
+			}
+
+			cr.Spec.VerneMQ.ProxyProtocol = nil
+			Expect(getVerneMQEnvVars(GetVerneMQStatefulSetName(cr), cr)).ToNot(ContainElement(
+				v1.EnvVar{Name: "DOCKER_VERNEMQ_LISTENER__TCP__PROXY", Value: "0.0.0.0:1886"}))
+		})
+
+		It("should let additional env vars have the last word", func() {
+			cr.Spec.VerneMQ.AdditionalEnv = []v1.EnvVar{{Name: "DOCKER_VERNEMQ_MAX_MESSAGE_SIZE", Value: "1024"}}
+			envVars := getVerneMQEnvVars(GetVerneMQStatefulSetName(cr), cr)
+			Expect(envVars[len(envVars)-1]).To(Equal(v1.EnvVar{Name: "DOCKER_VERNEMQ_MAX_MESSAGE_SIZE", Value: "1024"}))
+		})
+	})
+
+	Describe("Test EnsureVerneMQ with additional listeners", func() {
+		It("should expose the listeners on the pods and on the Service", func() {
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: CustomAstarteNamespace}, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].Ports).To(ContainElement(
+				v1.ContainerPort{Name: "mqtt-ws", ContainerPort: 8080, Protocol: v1.ProtocolTCP}))
+
+			service := &v1.Service{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: CustomAstarteNamespace}, service)).To(Succeed())
+			portNames := []string{}
+			for _, p := range service.Spec.Ports {
+				portNames = append(portNames, p.Name)
+			}
+			Expect(portNames).To(ContainElements("mqtt", "mqtt-ws", "mqtt-wss"))
+		})
+	})
+})
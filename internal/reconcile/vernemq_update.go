@@ -0,0 +1,295 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.openly.dev/pointy"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+)
+
+const (
+	defaultVerneMQDrainTimeoutSeconds                 int32 = 300
+	defaultVerneMQSessionRecoveryPercentage           int32 = 90
+	defaultVerneMQSessionRedistributionTimeoutSeconds int32 = 300
+	verneMQUpdatePollInterval                               = 10 * time.Second
+	verneMQMetricsTimeout                                   = 5 * time.Second
+	verneMQActiveConnectionsMetric                          = "active_mqtt_connections"
+)
+
+// verneMQSessionCounter returns the number of sessions open on a VerneMQ node. Tests replace it, as envtest runs no pods.
+var verneMQSessionCounter = getVerneMQNodeSessions
+
+// getVerneMQStatefulSetUpdateStrategy returns the update strategy of the VerneMQ StatefulSet. When updates are
+// coordinated, the partition is owned by the Operator: it is kept where it is, or set to hold all nodes back.
+func getVerneMQStatefulSetUpdateStrategy(cr *apiv2alpha1.Astarte, currentStrategy appsv1.StatefulSetUpdateStrategy, replicas int32) appsv1.StatefulSetUpdateStrategy {
+	if !cr.Spec.VerneMQ.UpdateStrategy.IsCoordinated() {
+		// Let the API Server default it to a standard rolling update
+		return appsv1.StatefulSetUpdateStrategy{}
+	}
+
+	partition := replicas
+	// Until coordination starts, the partition is whatever a standard rolling update left behind
+	if cr.Status.VerneMQUpdate != nil && currentStrategy.RollingUpdate != nil && currentStrategy.RollingUpdate.Partition != nil {
+		partition = *currentStrategy.RollingUpdate.Partition
+	}
+
+	return appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointy.Int32(partition)},
+	}
+}
+
+// getVerneMQPreStopHandler makes a VerneMQ node leave the cluster before stopping, when it is being removed by a
+// scale down. Leaving kills the listeners, so that devices reconnect to other nodes, and migrates the queues of the
+// node to the rest of the cluster. Nodes which are just restarted, e.g. while being updated, keep their queues.
+func getVerneMQPreStopHandler(cr *apiv2alpha1.Astarte) *v1.LifecycleHandler {
+	// StatefulSets remove the pods with the highest ordinals first, so the node is going away when its ordinal
+	// is not below the desired replicas anymore. When in doubt, e.g. when the StatefulSet is gone, stay.
+	command := fmt.Sprintf("SA=/var/run/secrets/kubernetes.io/serviceaccount && "+
+		"REPLICAS=$(curl -sSf --cacert $SA/ca.crt -H \"Authorization: Bearer $(cat $SA/token)\" "+
+		"https://kubernetes.default.svc/apis/apps/v1/namespaces/%s/statefulsets/%s | jq -r '.spec.replicas // empty') && "+
+		"POD=$(hostname) && [ -n \"$REPLICAS\" ] && [ \"${POD##*-}\" -ge \"$REPLICAS\" ] || exit 0; "+
+		// The node name depends on how the pod was started, so let's ask the node itself
+		"NODE=$(sed -n 's/^-name[[:space:]]*//p' /opt/vernemq/etc/vm.args) && "+
+		"/opt/vernemq/bin/vmq-admin cluster leave node=\"$NODE\" -k -i 5 -t %d || true",
+		cr.Namespace, GetVerneMQStatefulSetName(cr), getVerneMQDrainTimeoutSeconds(cr))
+	return &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"/bin/sh", "-c", command}}}
+}
+
+func getVerneMQDrainTimeoutSeconds(cr *apiv2alpha1.Astarte) int32 {
+	return pointy.Int32Value(cr.Spec.VerneMQ.UpdateStrategy.DrainTimeoutSeconds, defaultVerneMQDrainTimeoutSeconds)
+}
+
+// ensureVerneMQCoordinatedUpdate moves a coordinated VerneMQ update forward, if any. A single node is replaced at a
+// time, by lowering the StatefulSet partition, and only once the previously replaced node is ready and the sessions
+// it was holding have been redistributed across the cluster. Progress is persisted in the Astarte status.
+func ensureVerneMQCoordinatedUpdate(cr *apiv2alpha1.Astarte, statefulSet *appsv1.StatefulSet, c client.Client) error {
+	if !cr.Spec.VerneMQ.UpdateStrategy.IsCoordinated() {
+		if cr.Status.VerneMQUpdate == nil {
+			return nil
+		}
+		return patchVerneMQUpdateStatus(cr, nil, c)
+	}
+
+	replicas := pointy.Int32Value(statefulSet.Spec.Replicas, 1)
+	partition := replicas
+	if statefulSet.Spec.UpdateStrategy.RollingUpdate != nil {
+		partition = pointy.Int32Value(statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition, replicas)
+	}
+
+	newStatus := apiv2alpha1.AstarteVerneMQUpdateStatus{}
+	if cr.Status.VerneMQUpdate != nil {
+		newStatus = *cr.Status.VerneMQUpdate.DeepCopy()
+	}
+	newStatus.Replicas = replicas
+	newStatus.Partition = partition
+	newStatus.UpdatedReplicas = statefulSet.Status.UpdatedReplicas
+
+	newPartition, err := computeVerneMQUpdateStep(cr, statefulSet, &newStatus, c, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if newPartition != partition {
+		log.Info("Moving VerneMQ update forward", "Partition.Old", partition, "Partition.New", newPartition)
+		patch := client.MergeFrom(statefulSet.DeepCopy())
+		statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointy.Int32(newPartition)}
+		if err := c.Patch(context.TODO(), statefulSet, patch); err != nil {
+			return err
+		}
+		newStatus.Partition = newPartition
+	}
+
+	if equality.Semantic.DeepEqual(cr.Status.VerneMQUpdate, &newStatus) {
+		return nil
+	}
+	return patchVerneMQUpdateStatus(cr, &newStatus, c)
+}
+
+// computeVerneMQUpdateStep updates the given status and returns the partition the StatefulSet should have
+func computeVerneMQUpdateStep(cr *apiv2alpha1.Astarte, statefulSet *appsv1.StatefulSet, status *apiv2alpha1.AstarteVerneMQUpdateStatus,
+	c client.Client, now time.Time) (int32, error) {
+	replicas, partition := status.Replicas, status.Partition
+
+	// Wait for the StatefulSet controller to catch up with our changes
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		status.Phase = apiv2alpha1.VerneMQUpdatePhaseWaitingForNode
+		return partition, nil
+	}
+
+	if statefulSet.Status.UpdatedReplicas >= replicas && statefulSet.Status.ReadyReplicas >= replicas {
+		// All nodes are up to date. Hold them back again, so that the next update waits for us.
+		status.Phase = apiv2alpha1.VerneMQUpdatePhaseIdle
+		status.SessionsBeforeLastStep = nil
+		return replicas, nil
+	}
+
+	// The last replaced node must be up and running before we go on
+	if partition == 0 || statefulSet.Status.ReadyReplicas < replicas || statefulSet.Status.UpdatedReplicas < replicas-partition {
+		status.Phase = apiv2alpha1.VerneMQUpdatePhaseWaitingForNode
+		return partition, nil
+	}
+
+	sessions, err := countVerneMQSessions(cr, c)
+	if err != nil {
+		// Not knowing the sessions is no reason to stop: we will just wait for the whole redistribution timeout
+		log.V(1).Info("Could not count VerneMQ sessions", "error", err.Error())
+		status.Sessions = nil
+	} else {
+		status.Sessions = pointy.Int64(sessions)
+	}
+
+	if !haveVerneMQSessionsRecovered(cr.Spec.VerneMQ.UpdateStrategy, status, now) {
+		status.Phase = apiv2alpha1.VerneMQUpdatePhaseWaitingForSessions
+		return partition, nil
+	}
+
+	// Good to go: replace the next node
+	status.Phase = apiv2alpha1.VerneMQUpdatePhaseWaitingForNode
+	status.SessionsBeforeLastStep = status.Sessions
+	status.LastStepTime = &metav1.Time{Time: now}
+	return partition - 1, nil
+}
+
+// haveVerneMQSessionsRecovered returns whether the sessions held by the last replaced node have been redistributed
+func haveVerneMQSessionsRecovered(strategy *apiv2alpha1.AstarteVerneMQUpdateStrategySpec, status *apiv2alpha1.AstarteVerneMQUpdateStatus, now time.Time) bool {
+	// No node was replaced yet
+	if status.LastStepTime == nil {
+		return true
+	}
+
+	timeout := pointy.Int32Value(strategy.SessionRedistributionTimeoutSeconds, defaultVerneMQSessionRedistributionTimeoutSeconds)
+	if now.Sub(status.LastStepTime.Time) >= time.Duration(timeout)*time.Second {
+		return true
+	}
+
+	if status.Sessions == nil || status.SessionsBeforeLastStep == nil {
+		return false
+	}
+	percentage := pointy.Int32Value(strategy.SessionRecoveryPercentage, defaultVerneMQSessionRecoveryPercentage)
+	return *status.Sessions*100 >= *status.SessionsBeforeLastStep*int64(percentage)
+}
+
+// GetVerneMQUpdatePollInterval returns how often a coordinated VerneMQ update should be checked upon, or 0 if
+// no update is in progress.
+func GetVerneMQUpdatePollInterval(cr *apiv2alpha1.Astarte) time.Duration {
+	if !cr.Spec.VerneMQ.UpdateStrategy.IsCoordinated() || cr.Status.VerneMQUpdate == nil ||
+		cr.Status.VerneMQUpdate.Phase == apiv2alpha1.VerneMQUpdatePhaseIdle {
+		return 0
+	}
+	return verneMQUpdatePollInterval
+}
+
+func countVerneMQSessions(cr *apiv2alpha1.Astarte, c client.Client) (int64, error) {
+	pods := &v1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(cr.Namespace),
+		client.MatchingLabels{"app": GetVerneMQStatefulSetName(cr)}); err != nil {
+		return 0, err
+	}
+
+	var sessions int64
+	for i := range pods.Items {
+		nodeSessions, err := verneMQSessionCounter(&pods.Items[i])
+		if err != nil {
+			return 0, err
+		}
+		sessions += nodeSessions
+	}
+	return sessions, nil
+}
+
+func getVerneMQNodeSessions(pod *v1.Pod) (int64, error) {
+	if pod.Status.PodIP == "" {
+		return 0, fmt.Errorf("VerneMQ pod %s has no IP yet", pod.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), verneMQMetricsTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+pod.Status.PodIP+":8888/metrics", nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("VerneMQ pod %s returned %s while fetching metrics", pod.Name, resp.Status)
+	}
+	return parseVerneMQActiveConnections(resp.Body)
+}
+
+// parseVerneMQActiveConnections sums the open MQTT connections out of VerneMQ Prometheus metrics
+func parseVerneMQActiveConnections(metrics io.Reader) (int64, error) {
+	var connections float64
+	found := false
+
+	scanner := bufio.NewScanner(metrics)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[0], "{")
+		if name != verneMQActiveConnectionsMetric {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return 0, err
+		}
+		connections += value
+		found = true
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	if !found {
+		return 0, fmt.Errorf("metric %s not found", verneMQActiveConnectionsMetric)
+	}
+	return int64(connections), nil
+}
+
+func patchVerneMQUpdateStatus(cr *apiv2alpha1.Astarte, status *apiv2alpha1.AstarteVerneMQUpdateStatus, c client.Client) error {
+	patch := client.MergeFrom(cr.DeepCopy())
+	cr.Status.VerneMQUpdate = status
+	return c.Status().Patch(context.TODO(), cr, patch)
+}
@@ -0,0 +1,188 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	integrationutils "github.com/astarte-platform/astarte-kubernetes-operator/test/integration"
+	"go.openly.dev/pointy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("VerneMQ coordinated update testing", Ordered, Serial, func() {
+	const (
+		CustomAstarteName      = "example-astarte"
+		CustomAstarteNamespace = "vernemq-update-test"
+	)
+
+	var cr *apiv2alpha1.Astarte
+	// The sessions each VerneMQ pod reports, by pod name
+	var sessionsPerPod map[string]int64
+
+	BeforeAll(func() {
+		integrationutils.CreateNamespace(k8sClient, CustomAstarteNamespace)
+		verneMQSessionCounter = func(pod *v1.Pod) (int64, error) {
+			sessions, ok := sessionsPerPod[pod.Name]
+			if !ok {
+				return 0, errors.New("unreachable")
+			}
+			return sessions, nil
+		}
+	})
+
+	AfterAll(func() {
+		verneMQSessionCounter = getVerneMQNodeSessions
+		integrationutils.DeleteNamespace(k8sClient, CustomAstarteNamespace)
+	})
+
+	BeforeEach(func() {
+		cr = baseCr.DeepCopy()
+		cr.SetName(CustomAstarteName)
+		cr.SetNamespace(CustomAstarteNamespace)
+		cr.SetResourceVersion("")
+		cr.Spec.VerneMQ.Deploy = pointy.Bool(true)
+		cr.Spec.VerneMQ.UpdateStrategy = &apiv2alpha1.AstarteVerneMQUpdateStrategySpec{
+			Type:                apiv2alpha1.VerneMQCoordinatedUpdateStrategy,
+			DrainTimeoutSeconds: pointy.Int32(120),
+		}
+		integrationutils.DeployAstarte(k8sClient, cr)
+	})
+
+	AfterEach(func() {
+		integrationutils.TeardownResourcesInNamespace(context.Background(), k8sClient, CustomAstarteNamespace)
+	})
+
+	Describe("Test EnsureVerneMQ with coordinated updates", func() {
+		It("should hold nodes back and make them leave the cluster when scaled down", func() {
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: cr.Namespace}, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.UpdateStrategy.Type).To(Equal(appsv1.RollingUpdateStatefulSetStrategyType))
+			Expect(*statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(*statefulSet.Spec.Replicas))
+
+			podSpec := statefulSet.Spec.Template.Spec
+			Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(150)))
+			preStopCommand := getVerneMQContainer(&podSpec).Lifecycle.PreStop.Exec.Command[2]
+			Expect(preStopCommand).To(ContainSubstring("vmq-admin cluster leave"))
+			Expect(preStopCommand).To(ContainSubstring("-t 120"))
+			// Only nodes removed by a scale down leave the cluster
+			Expect(preStopCommand).To(ContainSubstring("/namespaces/" + cr.Namespace + "/statefulsets/" + GetVerneMQStatefulSetName(cr)))
+			Expect(preStopCommand).To(ContainSubstring(`[ "${POD##*-}" -ge "$REPLICAS" ] || exit 0`))
+
+			Expect(cr.Status.VerneMQUpdate).ToNot(BeNil())
+			Expect(GetVerneMQUpdatePollInterval(cr)).To(Equal(verneMQUpdatePollInterval))
+
+			// Going back to standard rolling updates drops everything
+			cr.Spec.VerneMQ.UpdateStrategy = nil
+			Expect(EnsureVerneMQ(cr, k8sClient, scheme.Scheme)).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: GetVerneMQStatefulSetName(cr), Namespace: cr.Namespace}, statefulSet)).To(Succeed())
+			Expect(getVerneMQContainer(&statefulSet.Spec.Template.Spec).Lifecycle).To(BeNil())
+			Expect(pointy.Int32Value(statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition, 0)).To(BeZero())
+			Expect(cr.Status.VerneMQUpdate).To(BeNil())
+		})
+	})
+
+	Describe("Test computeVerneMQUpdateStep", func() {
+		It("should replace one node at a time, waiting for sessions to be redistributed", func() {
+			for _, name := range []string{"vmq-0", "vmq-1", "vmq-2"} {
+				pod := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: CustomAstarteNamespace, Labels: map[string]string{"app": GetVerneMQStatefulSetName(cr)}},
+					Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "vernemq", Image: "vernemq"}}},
+				}
+				Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+			}
+			sessionsPerPod = map[string]int64{"vmq-0": 100, "vmq-1": 100, "vmq-2": 100}
+
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3},
+			}
+			status := &apiv2alpha1.AstarteVerneMQUpdateStatus{Replicas: 3, Partition: 3}
+			now := time.Now()
+
+			// A new revision is out: the last node goes first
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now)).To(Equal(int32(2)))
+			Expect(*status.SessionsBeforeLastStep).To(Equal(int64(300)))
+			Expect(status.LastStepTime).ToNot(BeNil())
+
+			// The node is back, but its sessions are not
+			status.Partition = 2
+			statefulSet.Status.UpdatedReplicas = 1
+			sessionsPerPod = map[string]int64{"vmq-0": 120, "vmq-1": 120, "vmq-2": 0}
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(time.Minute))).To(Equal(int32(2)))
+			Expect(status.Phase).To(Equal(apiv2alpha1.VerneMQUpdatePhaseWaitingForSessions))
+			Expect(*status.Sessions).To(Equal(int64(240)))
+
+			// Sessions are back: next node
+			sessionsPerPod["vmq-2"] = 60
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(2*time.Minute))).To(Equal(int32(1)))
+
+			// The node is not ready yet
+			status.Partition = 1
+			statefulSet.Status.ReadyReplicas = 2
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(3*time.Minute))).To(Equal(int32(1)))
+			Expect(status.Phase).To(Equal(apiv2alpha1.VerneMQUpdatePhaseWaitingForNode))
+
+			// Sessions can't be counted: we go on once the redistribution timeout expires
+			statefulSet.Status.ReadyReplicas = 3
+			statefulSet.Status.UpdatedReplicas = 2
+			sessionsPerPod = map[string]int64{}
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(3*time.Minute))).To(Equal(int32(1)))
+			Expect(status.Sessions).To(BeNil())
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(8*time.Minute))).To(Equal(int32(0)))
+
+			// All done: hold nodes back for the next update
+			status.Partition = 0
+			statefulSet.Status.UpdatedReplicas = 3
+			Expect(computeVerneMQUpdateStep(cr, statefulSet, status, k8sClient, now.Add(9*time.Minute))).To(Equal(int32(3)))
+			Expect(status.Phase).To(Equal(apiv2alpha1.VerneMQUpdatePhaseIdle))
+		})
+	})
+
+	Describe("Test parseVerneMQActiveConnections", func() {
+		It("should sum the open MQTT connections", func() {
+			metrics := `# HELP active_mqtt_connections The number of open MQTT connections.
+# TYPE active_mqtt_connections gauge
+active_mqtt_connections{node="VerneMQ@vernemq-0",mqtt_version="4"} 1200
+active_mqtt_connections{node="VerneMQ@vernemq-0",mqtt_version="5"} 34
+active_mqttws_connections{node="VerneMQ@vernemq-0"} 7
+`
+			Expect(parseVerneMQActiveConnections(strings.NewReader(metrics))).To(Equal(int64(1234)))
+		})
+
+		It("should fail when the metric is missing", func() {
+			_, err := parseVerneMQActiveConnections(strings.NewReader("socket_open 12\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scope restricts the objects an Operator deployment manages, so that several Operators can share a cluster.
+package scope
+
+import (
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+	ingressv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/ingress/v2alpha1"
+)
+
+// OperatorScope describes the objects managed by the Operator
+type OperatorScope struct {
+	// Namespaces watched by the Operator. When empty, all namespaces are watched.
+	Namespaces []string
+	// Selector the Astarte, Flow and AstarteDefaultIngress objects managed by the Operator must match.
+	Selector labels.Selector
+}
+
+// current is the scope of the running Operator, which manages everything unless told otherwise
+var current = OperatorScope{Selector: labels.Everything()}
+
+// Parse builds an OperatorScope out of a comma-separated list of namespaces and a label selector
+func Parse(namespaces, selector string) (OperatorScope, error) {
+	s := OperatorScope{Selector: labels.Everything()}
+
+	for _, namespace := range strings.Split(namespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" && !slices.Contains(s.Namespaces, namespace) {
+			s.Namespaces = append(s.Namespaces, namespace)
+		}
+	}
+
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return OperatorScope{}, err
+		}
+		s.Selector = parsed
+	}
+
+	return s, nil
+}
+
+// Set sets the scope of the running Operator. It must be called before starting the controllers.
+func Set(s OperatorScope) {
+	if s.Selector == nil {
+		s.Selector = labels.Everything()
+	}
+	current = s
+}
+
+// Get returns the scope of the running Operator
+func Get() OperatorScope {
+	return current
+}
+
+// IsRestricted returns whether other Operators might be managing objects in the same cluster
+func (s OperatorScope) IsRestricted() bool {
+	return len(s.Namespaces) > 0 || (s.Selector != nil && !s.Selector.Empty())
+}
+
+// Contains returns whether obj is managed by the Operator
+func (s OperatorScope) Contains(obj metav1.Object) bool {
+	if !s.ContainsNamespace(obj.GetNamespace()) {
+		return false
+	}
+	return s.Selector == nil || s.Selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// ContainsNamespace returns whether the Operator watches namespace, and can hence manage objects in it
+func (s OperatorScope) ContainsNamespace(namespace string) bool {
+	return len(s.Namespaces) == 0 || slices.Contains(s.Namespaces, namespace)
+}
+
+// CacheOptions returns the cache options making the Operator see only the objects in its scope. Secrets which
+// are never of interest to Astarte, such as Helm releases and ServiceAccount tokens, are left out as well.
+func (s OperatorScope) CacheOptions() cache.Options {
+	opts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&v1.Secret{}: {Field: fields.AndSelectors(
+				fields.OneTermNotEqualSelector("type", "helm.sh/release.v1"),
+				fields.OneTermNotEqualSelector("type", string(v1.SecretTypeServiceAccountToken)),
+			)},
+		},
+	}
+
+	if len(s.Namespaces) > 0 {
+		opts.DefaultNamespaces = map[string]cache.Config{}
+		for _, namespace := range s.Namespaces {
+			opts.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+
+	if s.Selector != nil && !s.Selector.Empty() {
+		opts.ByObject[&apiv2alpha1.Astarte{}] = cache.ByObject{Label: s.Selector}
+		opts.ByObject[&flowv2alpha1.Flow{}] = cache.ByObject{Label: s.Selector}
+		opts.ByObject[&ingressv2alpha1.AstarteDefaultIngress{}] = cache.ByObject{Label: s.Selector}
+	}
+
+	return opts
+}
@@ -0,0 +1,100 @@
+/*
+This file is part of Astarte.
+
+Copyright 2020-25 SECO Mind Srl.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/api/v2alpha1"
+	flowv2alpha1 "github.com/astarte-platform/astarte-kubernetes-operator/api/flow/v2alpha1"
+)
+
+var _ = Describe("OperatorScope", func() {
+	Context("Parse", func() {
+		It("should watch everything by default", func() {
+			s, err := Parse("", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Namespaces).To(BeEmpty())
+			Expect(s.IsRestricted()).To(BeFalse())
+			Expect(s.CacheOptions().DefaultNamespaces).To(BeNil())
+			Expect(s.CacheOptions().ByObject).To(HaveLen(1))
+		})
+
+		It("should trim and deduplicate namespaces", func() {
+			s, err := Parse(" team-a,team-b,,team-a ", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Namespaces).To(Equal([]string{"team-a", "team-b"}))
+			Expect(s.IsRestricted()).To(BeTrue())
+			Expect(s.CacheOptions().DefaultNamespaces).To(HaveLen(2))
+		})
+
+		It("should parse the label selector", func() {
+			s, err := Parse("", "team=iot")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.IsRestricted()).To(BeTrue())
+			Expect(s.CacheOptions().ByObject).To(HaveLen(4))
+		})
+
+		It("should reject invalid label selectors", func() {
+			_, err := Parse("", "team==,")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Contains", func() {
+		It("should match both the namespaces and the label selector", func() {
+			s, err := Parse("team-a", "team=iot")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s.Contains(&apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "team-a", Labels: map[string]string{"team": "iot"}}})).To(BeTrue())
+			Expect(s.Contains(&flowv2alpha1.Flow{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "team-a", Labels: map[string]string{"team": "other"}}})).To(BeFalse())
+			Expect(s.Contains(&apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "team-b", Labels: map[string]string{"team": "iot"}}})).To(BeFalse())
+		})
+	})
+
+	Context("ContainsNamespace", func() {
+		It("should match the watched namespaces only", func() {
+			s, err := Parse("team-a", "team=iot")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.ContainsNamespace("team-a")).To(BeTrue())
+			Expect(s.ContainsNamespace("team-b")).To(BeFalse())
+
+			s, err = Parse("", "team=iot")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.ContainsNamespace("team-b")).To(BeTrue())
+		})
+	})
+
+	Context("Set", func() {
+		AfterEach(func() {
+			Set(OperatorScope{})
+		})
+
+		It("should default to managing everything", func() {
+			Set(OperatorScope{Namespaces: []string{"team-a"}})
+			Expect(Get().Selector).ToNot(BeNil())
+			Expect(Get().Contains(&apiv2alpha1.Astarte{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}})).To(BeTrue())
+		})
+	})
+})